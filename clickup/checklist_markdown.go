@@ -0,0 +1,396 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultChecklistName is the name given to task list items that come before
+// any heading in a Markdown checklist block.
+const DefaultChecklistName = "Checklist"
+
+// MarkdownChecklist is a checklist parsed from Markdown.
+type MarkdownChecklist struct {
+	Name  string
+	Items []MarkdownChecklistItem
+}
+
+// MarkdownChecklistItem is a task list item and the items nested under it.
+type MarkdownChecklistItem struct {
+	Name     string
+	Resolved bool
+	Children []MarkdownChecklistItem
+}
+
+var (
+	checklistHeadingRe = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*\s*$`)
+	checklistItemRe    = regexp.MustCompile(`^([ \t]*)[-*+]\s+\[([ xX])\]\s+(.*?)\s*$`)
+)
+
+// ParseChecklistMarkdown parses Markdown task lists into checklists. Each
+// heading starts a checklist named after it; items before the first heading
+// belong to DefaultChecklistName. An item indented deeper than the one before
+// it is nested under it. Other lines are ignored.
+//
+//	## Acceptance criteria
+//	- [x] Login works
+//	  - [ ] with SSO
+func ParseChecklistMarkdown(md string) []MarkdownChecklist {
+	var checklists []MarkdownChecklist
+
+	type level struct {
+		indent int
+		items  *[]MarkdownChecklistItem
+	}
+	var stack []level
+
+	for _, line := range strings.Split(md, "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		if m := checklistHeadingRe.FindStringSubmatch(line); m != nil {
+			checklists = append(checklists, MarkdownChecklist{Name: m[1]})
+			stack = nil
+			continue
+		}
+
+		m := checklistItemRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if len(checklists) == 0 {
+			checklists = append(checklists, MarkdownChecklist{Name: DefaultChecklistName})
+		}
+		cl := &checklists[len(checklists)-1]
+
+		indent := len(strings.ReplaceAll(m[1], "\t", "    "))
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		item := MarkdownChecklistItem{Name: m[3], Resolved: m[2] != " "}
+		if len(stack) == 0 {
+			cl.Items = append(cl.Items, item)
+			stack = append(stack, level{indent, &cl.Items})
+			continue
+		}
+
+		// Nest under the last item of the enclosing level.
+		siblings := *stack[len(stack)-1].items
+		parent := &siblings[len(siblings)-1]
+		parent.Children = append(parent.Children, item)
+		stack = append(stack, level{indent, &parent.Children})
+	}
+
+	return checklists
+}
+
+// ChecklistsToMarkdown renders checklists as Markdown task lists, in the
+// form read by ParseChecklistMarkdown.
+func ChecklistsToMarkdown(checklists []Checklist) string {
+	var b strings.Builder
+
+	for i, cl := range sortedChecklists(checklists) {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "## %s\n", checklistLine(cl.Name))
+
+		children := checklistItemChildren(cl.AllItems())
+		var write func(parent string, depth int)
+		write = func(parent string, depth int) {
+			for _, it := range children[parent] {
+				mark := " "
+				if it.Resolved {
+					mark = "x"
+				}
+				fmt.Fprintf(&b, "%s- [%s] %s\n", strings.Repeat("  ", depth), mark, checklistLine(it.Name))
+				write(it.ID, depth+1)
+			}
+		}
+		write("", 0)
+	}
+
+	return b.String()
+}
+
+func checklistLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// sortedChecklists returns the checklists ordered by orderindex.
+func sortedChecklists(checklists []Checklist) []Checklist {
+	sorted := append([]Checklist{}, checklists...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return orderindexValue(sorted[i].Orderindex.String()) < orderindexValue(sorted[j].Orderindex.String())
+	})
+
+	return sorted
+}
+
+// checklistItemChildren groups items by parent ID, ordered by orderindex.
+// Top-level items are under "". Items whose parent is missing are treated as
+// top-level.
+func checklistItemChildren(items []Item) map[string][]Item {
+	ids := map[string]bool{}
+	for _, it := range items {
+		ids[it.ID] = true
+	}
+
+	children := map[string][]Item{}
+	for _, it := range items {
+		parent := it.Parent
+		if !ids[parent] {
+			parent = ""
+		}
+		children[parent] = append(children[parent], it)
+	}
+	for _, c := range children {
+		sort.SliceStable(c, func(i, j int) bool {
+			return orderindexValue(c[i].Orderindex.String()) < orderindexValue(c[j].Orderindex.String())
+		})
+	}
+
+	return children
+}
+
+func orderindexValue(s string) float64 {
+	var f float64
+	fmt.Sscan(s, &f)
+	return f
+}
+
+// ChecklistSyncResult counts the changes made by SyncChecklistMarkdown.
+type ChecklistSyncResult struct {
+	ChecklistsCreated int
+	ChecklistsMoved   int
+	ChecklistsDeleted int
+	ItemsCreated      int
+	ItemsUpdated      int
+	ItemsDeleted      int
+}
+
+// SyncChecklistMarkdown makes the checklists of a task match a Markdown
+// block parsed by ParseChecklistMarkdown. Checklists and items are matched
+// by name, in order. Missing ones are created, resolved state, nesting and
+// order are updated, and checklists and items not in the Markdown are
+// deleted.
+func (s *ChecklistsService) SyncChecklistMarkdown(ctx context.Context, taskID string, md string, opts *GetTaskOptions) (*ChecklistSyncResult, error) {
+	task, _, err := s.client.Tasks.GetTask(ctx, taskID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var clOpts *ChecklistOptions
+	if opts != nil {
+		clOpts = &ChecklistOptions{TeamID: opts.TeamID}
+		if opts.CustomTaskIDs {
+			clOpts.CustomTaskIDs = "true"
+		}
+	}
+
+	res := &ChecklistSyncResult{}
+	desired := ParseChecklistMarkdown(md)
+	existing := sortedChecklists(task.Checklists)
+
+	// Match checklists by name, in order.
+	used := make([]bool, len(existing))
+	matched := make([]*Checklist, len(desired))
+	for i, d := range desired {
+		for j := range existing {
+			if !used[j] && existing[j].Name == d.Name {
+				used[j] = true
+				matched[i] = &existing[j]
+				break
+			}
+		}
+	}
+
+	var order []string
+	for j, cl := range existing {
+		if used[j] {
+			order = append(order, cl.ID)
+			continue
+		}
+		if _, err := s.DeleteChecklist(ctx, cl.ID); err != nil {
+			return res, err
+		}
+		res.ChecklistsDeleted++
+	}
+
+	for i, d := range desired {
+		if matched[i] != nil {
+			continue
+		}
+		cl, _, err := s.CreateChecklist(ctx, taskID, clOpts, &ChecklistRequest{Name: d.Name})
+		if err != nil {
+			return res, err
+		}
+		matched[i] = cl
+		order = append(order, cl.ID)
+		res.ChecklistsCreated++
+	}
+
+	for i, d := range desired {
+		cl := matched[i]
+		if order[i] != cl.ID {
+			if _, _, err := s.MoveChecklist(ctx, cl.ID, i); err != nil {
+				return res, err
+			}
+			order = moveString(order, cl.ID, i)
+			res.ChecklistsMoved++
+		}
+
+		if err := s.syncChecklistItems(ctx, cl, d.Items, res); err != nil {
+			return res, err
+		}
+	}
+
+	return res, nil
+}
+
+// checklistNode is a desired item with its position in the tree.
+type checklistNode struct {
+	item   MarkdownChecklistItem
+	parent int // index of the parent node, or -1
+	id     string
+}
+
+// syncChecklistItems makes the items of cl match the desired tree.
+func (s *ChecklistsService) syncChecklistItems(ctx context.Context, cl *Checklist, desired []MarkdownChecklistItem, res *ChecklistSyncResult) error {
+	// Existing items in document order, so duplicates match in order.
+	children := checklistItemChildren(cl.AllItems())
+	var existing []Item
+	var collect func(parent string)
+	collect = func(parent string) {
+		for _, it := range children[parent] {
+			existing = append(existing, it)
+			collect(it.ID)
+		}
+	}
+	collect("")
+
+	var nodes []checklistNode
+	var flatten func(items []MarkdownChecklistItem, parent int)
+	flatten = func(items []MarkdownChecklistItem, parent int) {
+		for _, d := range items {
+			nodes = append(nodes, checklistNode{item: d, parent: parent})
+			flatten(d.Children, len(nodes)-1)
+		}
+	}
+	flatten(desired, -1)
+
+	// Match by name in document order, wherever the items are nested.
+	current := map[string]Item{}
+	for _, it := range existing {
+		current[it.ID] = it
+	}
+	used := map[string]bool{}
+	for i := range nodes {
+		for _, it := range existing {
+			if !used[it.ID] && it.Name == nodes[i].item.Name {
+				used[it.ID] = true
+				nodes[i].id = it.ID
+				break
+			}
+		}
+	}
+
+	for i := range nodes {
+		if nodes[i].id != "" {
+			continue
+		}
+		updated, _, err := s.CreateChecklistItem(ctx, cl.ID, &ChecklistItemRequest{Name: nodes[i].item.Name})
+		if err != nil {
+			return err
+		}
+		for _, it := range updated.AllItems() {
+			if _, ok := current[it.ID]; !ok && it.Name == nodes[i].item.Name {
+				current[it.ID] = it
+				nodes[i].id = it.ID
+				break
+			}
+		}
+		if nodes[i].id == "" {
+			return fmt.Errorf("created checklist item %q not found in checklist %s", nodes[i].item.Name, cl.ID)
+		}
+		res.ItemsCreated++
+	}
+
+	// Group siblings and reorder a group when its order or membership changed.
+	parentID := func(i int) string {
+		if nodes[i].parent < 0 {
+			return ""
+		}
+		return nodes[nodes[i].parent].id
+	}
+	groups := map[string][]int{}
+	var groupOrder []string
+	for i := range nodes {
+		p := parentID(i)
+		if _, ok := groups[p]; !ok {
+			groupOrder = append(groupOrder, p)
+		}
+		groups[p] = append(groups[p], i)
+	}
+
+	for _, p := range groupOrder {
+		siblings := groups[p]
+		reorder := false
+		for k, i := range siblings {
+			it := current[nodes[i].id]
+			if it.Parent != p {
+				reorder = true
+			}
+			if k > 0 && orderindexValue(current[nodes[siblings[k-1]].id].Orderindex.String()) >= orderindexValue(it.Orderindex.String()) {
+				reorder = true
+			}
+		}
+
+		for k, i := range siblings {
+			it := current[nodes[i].id]
+			if !reorder && it.Resolved == nodes[i].item.Resolved {
+				continue
+			}
+
+			parent, resolved := p, nodes[i].item.Resolved
+			update := &ChecklistItemUpdate{Resolved: &resolved, Parent: &parent}
+			if reorder {
+				index := k
+				update.Orderindex = &index
+			}
+			if _, _, err := s.UpdateChecklistItem(ctx, cl.ID, it.ID, update); err != nil {
+				return err
+			}
+			res.ItemsUpdated++
+		}
+	}
+
+	// Delete leftovers children first, after matched items have moved away.
+	for i := len(existing) - 1; i >= 0; i-- {
+		if used[existing[i].ID] {
+			continue
+		}
+		if _, err := s.DeleteChecklistItem(ctx, cl.ID, existing[i].ID); err != nil {
+			return err
+		}
+		res.ItemsDeleted++
+	}
+
+	return nil
+}
+
+// moveString moves s to index i of list.
+func moveString(list []string, s string, i int) []string {
+	out := make([]string, 0, len(list))
+	for _, v := range list {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	out = append(out[:i], append([]string{s}, out[i:]...)...)
+
+	return out
+}
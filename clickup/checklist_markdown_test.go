@@ -0,0 +1,170 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseChecklistMarkdown(t *testing.T) {
+	md := `Intro text is ignored.
+- [ ] Loose item
+
+## Acceptance criteria
+- [x] Login works
+  - [ ] with SSO
+    - [X] Okta
+  - [ ] with password
+* [ ] Logout works
+
+### QA ###
+	+ [ ] Smoke test
+	    + [ ] Mobile
+`
+
+	got := ParseChecklistMarkdown(md)
+	want := []MarkdownChecklist{
+		{Name: DefaultChecklistName, Items: []MarkdownChecklistItem{{Name: "Loose item"}}},
+		{Name: "Acceptance criteria", Items: []MarkdownChecklistItem{
+			{Name: "Login works", Resolved: true, Children: []MarkdownChecklistItem{
+				{Name: "with SSO", Children: []MarkdownChecklistItem{{Name: "Okta", Resolved: true}}},
+				{Name: "with password"},
+			}},
+			{Name: "Logout works"},
+		}},
+		{Name: "QA", Items: []MarkdownChecklistItem{
+			{Name: "Smoke test", Children: []MarkdownChecklistItem{{Name: "Mobile"}}},
+		}},
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("ParseChecklistMarkdown = %+v, want %+v", got, want)
+	}
+}
+
+func TestChecklistsToMarkdown(t *testing.T) {
+	checklists := []Checklist{
+		{ID: "c2", Name: "QA", Orderindex: "1", Items: []Item{{ID: "i9", Name: "Smoke\ntest"}}},
+		{ID: "c1", Name: "Acceptance", Orderindex: "0", Items: []Item{
+			{ID: "i3", Name: "with SSO", Orderindex: "0", Parent: "i1"},
+			{ID: "i2", Name: "Logout", Orderindex: "1"},
+			{ID: "i1", Name: "Login", Orderindex: "0", Resolved: true},
+		}},
+	}
+
+	got := ChecklistsToMarkdown(checklists)
+	want := "## Acceptance\n- [x] Login\n  - [ ] with SSO\n- [ ] Logout\n\n## QA\n- [ ] Smoke test\n"
+	if got != want {
+		t.Errorf("ChecklistsToMarkdown = %q, want %q", got, want)
+	}
+
+	parsed := ParseChecklistMarkdown(got)
+	if len(parsed) != 2 || parsed[0].Items[0].Children[0].Name != "with SSO" {
+		t.Errorf("ParseChecklistMarkdown(ChecklistsToMarkdown) = %+v", parsed)
+	}
+}
+
+func TestChecklistsService_SyncChecklistMarkdown(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	var calls []string
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		call := strings.TrimSpace(r.Method + " " + r.URL.Path + " " + strings.TrimSpace(string(body)))
+
+		switch call {
+		case "GET /task/9hz/":
+			fmt.Fprint(w, `{"id": "9hz", "checklists": [
+				{"id": "c2", "name": "Old", "orderindex": 0, "items": []},
+				{"id": "c1", "name": "Acceptance", "orderindex": 1, "items": [
+					{"id": "i1", "name": "Login", "orderindex": 0, "resolved": false, "parent": null},
+					{"id": "i2", "name": "SSO", "orderindex": 1, "resolved": false, "parent": null},
+					{"id": "i3", "name": "Stale", "orderindex": 2, "resolved": false, "parent": null}
+				]}
+			]}`)
+			return
+		case `POST /task/9hz/checklist/ {"name":"QA"}`:
+			fmt.Fprint(w, `{"checklist": {"id": "c3", "name": "QA", "orderindex": 2, "items": []}}`)
+		case `POST /checklist/c1/checklist_item {"name":"Password reset"}`:
+			fmt.Fprint(w, `{"checklist": {"id": "c1", "items": [
+				{"id": "i1", "name": "Login", "orderindex": 0},
+				{"id": "i2", "name": "SSO", "orderindex": 1},
+				{"id": "i3", "name": "Stale", "orderindex": 2},
+				{"id": "i4", "name": "Password reset", "orderindex": 3}
+			]}}`)
+		case `POST /checklist/c3/checklist_item {"name":"Smoke test"}`:
+			fmt.Fprint(w, `{"checklist": {"id": "c3", "items": [{"id": "i5", "name": "Smoke test", "orderindex": 0}]}}`)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+		calls = append(calls, call)
+	})
+
+	md := `## Acceptance
+- [x] Login
+  - [ ] SSO
+  - [ ] Password reset
+
+## QA
+- [ ] Smoke test
+`
+
+	res, err := client.Checklists.SyncChecklistMarkdown(context.Background(), "9hz", md, nil)
+	if err != nil {
+		t.Fatalf("Checklists.SyncChecklistMarkdown returned error: %v", err)
+	}
+
+	wantCalls := []string{
+		`DELETE /checklist/c2`,
+		`POST /task/9hz/checklist/ {"name":"QA"}`,
+		`POST /checklist/c1/checklist_item {"name":"Password reset"}`,
+		`PUT /checklist/c1/checklist_item/i1 {"parent":null,"resolved":true}`,
+		`PUT /checklist/c1/checklist_item/i2 {"orderindex":0,"parent":"i1","resolved":false}`,
+		`PUT /checklist/c1/checklist_item/i4 {"orderindex":1,"parent":"i1","resolved":false}`,
+		`DELETE /checklist/c1/checklist_item/i3`,
+		`POST /checklist/c3/checklist_item {"name":"Smoke test"}`,
+	}
+	if !cmp.Equal(calls, wantCalls) {
+		t.Errorf("requests = %q, want %q", calls, wantCalls)
+	}
+
+	want := &ChecklistSyncResult{ChecklistsCreated: 1, ChecklistsDeleted: 1, ItemsCreated: 2, ItemsUpdated: 3, ItemsDeleted: 1}
+	if !cmp.Equal(res, want) {
+		t.Errorf("Checklists.SyncChecklistMarkdown returned %+v, want %+v", res, want)
+	}
+}
+
+func TestChecklistsService_SyncChecklistMarkdown_moves(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/task/9hz/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": "9hz", "checklists": [
+			{"id": "c1", "name": "A", "orderindex": 0, "items": []},
+			{"id": "c2", "name": "B", "orderindex": 1, "items": []}
+		]}`)
+	})
+	var moved string
+	mux.HandleFunc("/checklist/c2", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		body, _ := io.ReadAll(r.Body)
+		moved = strings.TrimSpace(string(body))
+		fmt.Fprint(w, `{}`)
+	})
+
+	res, err := client.Checklists.SyncChecklistMarkdown(context.Background(), "9hz", "# B\n# A\n", nil)
+	if err != nil {
+		t.Fatalf("Checklists.SyncChecklistMarkdown returned error: %v", err)
+	}
+	if want := `{"position":0}`; moved != want {
+		t.Errorf("move request = %s, want %s", moved, want)
+	}
+	if res.ChecklistsMoved != 1 {
+		t.Errorf("ChecklistsMoved = %d, want 1", res.ChecklistsMoved)
+	}
+}
@@ -17,11 +17,53 @@ type ChecklistResponse struct {
 	Checklist Checklist `json:"checklist"`
 }
 
-// TODO: Add parent.
 type ChecklistItemRequest struct {
 	Name     string `json:"name"`
 	Assignee int    `json:"assignee,omitempty"`
 	Resolved bool   `json:"resolved,omitempty"`
+	// Parent is the ID of the checklist item to nest this item under.
+	Parent string `json:"parent,omitempty"`
+}
+
+// ChecklistItemUpdate changes some fields of a checklist item and, unlike
+// ChecklistItemRequest, can unresolve, unassign and unnest it. Nil fields are
+// left unchanged.
+type ChecklistItemUpdate struct {
+	Name     *string
+	Resolved *bool
+	// Assignee is the user to assign; 0 unassigns the item.
+	Assignee *int
+	// Parent is the item to nest under; "" moves the item to the top level.
+	Parent *string
+	// Orderindex is the position of the item among its siblings.
+	Orderindex *int
+}
+
+func (u ChecklistItemUpdate) MarshalJSON() ([]byte, error) {
+	body := map[string]interface{}{}
+	if u.Name != nil {
+		body["name"] = *u.Name
+	}
+	if u.Resolved != nil {
+		body["resolved"] = *u.Resolved
+	}
+	if u.Assignee != nil {
+		body["assignee"] = nil
+		if *u.Assignee != 0 {
+			body["assignee"] = *u.Assignee
+		}
+	}
+	if u.Parent != nil {
+		body["parent"] = nil
+		if *u.Parent != "" {
+			body["parent"] = *u.Parent
+		}
+	}
+	if u.Orderindex != nil {
+		body["orderindex"] = *u.Orderindex
+	}
+
+	return json.Marshal(body)
 }
 
 type Checklist struct {
@@ -35,14 +77,71 @@ type Checklist struct {
 }
 
 type Item struct {
-	ID          string        `json:"id"`
-	Name        string        `json:"name"`
-	Orderindex  json.Number   `json:"orderindex"`
-	Assignee    User          `json:"assignee"`
-	Resolved    bool          `json:"resolved"`
-	Parent      interface{}   `json:"parent"`
-	DateCreated string        `json:"date_created"`
-	Children    []interface{} `json:"children"`
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	Orderindex json.Number `json:"orderindex"`
+	// Assignee has a zero ID when the item is unassigned.
+	Assignee User `json:"assignee"`
+	Resolved bool `json:"resolved"`
+	// Parent is the ID of the item this one is nested under, or "".
+	Parent      string         `json:"parent"`
+	DateCreated string         `json:"date_created"`
+	Children    ChecklistItems `json:"children"`
+}
+
+// ChecklistItems are the items nested under a checklist item. ClickUp sends
+// them either as items or as item IDs; IDs decode to items with only the ID
+// set.
+type ChecklistItems []Item
+
+func (items *ChecklistItems) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*items = make(ChecklistItems, 0, len(raw))
+	for _, r := range raw {
+		var it Item
+		if err := json.Unmarshal(r, &it.ID); err != nil {
+			if err := json.Unmarshal(r, &it); err != nil {
+				return err
+			}
+		}
+		*items = append(*items, it)
+	}
+
+	return nil
+}
+
+// AllItems returns every item of the checklist, including nested items sent
+// as children, each once. Nested items get Parent set when it is missing.
+func (c *Checklist) AllItems() []Item {
+	var all []Item
+	index := map[string]int{}
+
+	var add func(items []Item, parent string)
+	add = func(items []Item, parent string) {
+		for _, it := range items {
+			if it.Parent == "" {
+				it.Parent = parent
+			}
+			if i, ok := index[it.ID]; ok {
+				// An ID-only child was listed before the full item, or the other way round.
+				if all[i].Name == "" {
+					it.Parent = all[i].Parent
+					all[i] = it
+				}
+			} else {
+				index[it.ID] = len(all)
+				all = append(all, it)
+			}
+			add(it.Children, it.ID)
+		}
+	}
+	add(c.Items, "")
+
+	return all
 }
 
 type ChecklistOptions struct {
@@ -88,6 +187,24 @@ func (s *ChecklistsService) EditChecklist(ctx context.Context, checklistID strin
 	return &cr.Checklist, resp, nil
 }
 
+// MoveChecklist moves a checklist to the zero-based position among the
+// checklists of its task.
+func (s *ChecklistsService) MoveChecklist(ctx context.Context, checklistID string, position int) (*Checklist, *Response, error) {
+	u := fmt.Sprintf("checklist/%v", checklistID)
+	req, err := s.client.NewRequest("PUT", u, map[string]int{"position": position})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cr := new(ChecklistResponse)
+	resp, err := s.client.Do(ctx, req, cr)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &cr.Checklist, resp, nil
+}
+
 func (s *ChecklistsService) DeleteChecklist(ctx context.Context, checklistID string) (*Response, error) {
 	u := fmt.Sprintf("checklist/%v", checklistID)
 	req, err := s.client.NewRequest("DELETE", u, nil)
@@ -136,6 +253,37 @@ func (s *ChecklistsService) EditChecklistItem(ctx context.Context, checklistID s
 	return &cr.Checklist, resp, nil
 }
 
+func (s *ChecklistsService) UpdateChecklistItem(ctx context.Context, checklistID string, checklistItemID string, update *ChecklistItemUpdate) (*Checklist, *Response, error) {
+	u := fmt.Sprintf("checklist/%v/checklist_item/%v", checklistID, checklistItemID)
+	req, err := s.client.NewRequest("PUT", u, update)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cr := new(ChecklistResponse)
+	resp, err := s.client.Do(ctx, req, cr)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &cr.Checklist, resp, nil
+}
+
+func (s *ChecklistsService) AssignChecklistItem(ctx context.Context, checklistID string, checklistItemID string, userID int) (*Checklist, *Response, error) {
+	return s.UpdateChecklistItem(ctx, checklistID, checklistItemID, &ChecklistItemUpdate{Assignee: &userID})
+}
+
+func (s *ChecklistsService) UnassignChecklistItem(ctx context.Context, checklistID string, checklistItemID string) (*Checklist, *Response, error) {
+	none := 0
+	return s.UpdateChecklistItem(ctx, checklistID, checklistItemID, &ChecklistItemUpdate{Assignee: &none})
+}
+
+// MoveChecklistItem nests an item under parentID, or moves it to the top
+// level when parentID is "", at the zero-based position among its siblings.
+func (s *ChecklistsService) MoveChecklistItem(ctx context.Context, checklistID string, checklistItemID string, parentID string, position int) (*Checklist, *Response, error) {
+	return s.UpdateChecklistItem(ctx, checklistID, checklistItemID, &ChecklistItemUpdate{Parent: &parentID, Orderindex: &position})
+}
+
 func (s *ChecklistsService) DeleteChecklistItem(ctx context.Context, checklistID string, checklistItemID string) (*Response, error) {
 	u := fmt.Sprintf("checklist/%v/checklist_item/%v", checklistID, checklistItemID)
 
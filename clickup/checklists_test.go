@@ -115,3 +115,109 @@ func TestChecklistsService_DeleteChecklist(t *testing.T) {
 		t.Errorf("Actions.ListArtifacts returned error: %v", err)
 	}
 }
+
+func TestChecklist_AllItems(t *testing.T) {
+	var c Checklist
+	err := json.Unmarshal([]byte(`{"id": "c1", "items": [
+		{"id": "i1", "name": "Login", "orderindex": 0, "assignee": null, "parent": null, "children": [
+			{"id": "i2", "name": "SSO", "orderindex": 0, "parent": "i1", "children": ["i3"]}
+		]},
+		{"id": "i3", "name": "Okta", "orderindex": 0, "assignee": {"id": 183}, "parent": "i2", "children": []}
+	]}`), &c)
+	if err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+
+	if want := (ChecklistItems{{ID: "i3"}}); !cmp.Equal(c.Items[0].Children[0].Children, want) {
+		t.Errorf("Children = %+v, want %+v", c.Items[0].Children[0].Children, want)
+	}
+
+	var got []string
+	for _, it := range c.AllItems() {
+		got = append(got, it.ID+"<"+it.Parent+":"+it.Name)
+	}
+	want := []string{"i1<:Login", "i2<i1:SSO", "i3<i2:Okta"}
+	if !cmp.Equal(got, want) {
+		t.Errorf("AllItems = %v, want %v", got, want)
+	}
+}
+
+func TestChecklistsService_MoveChecklist(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/checklist/c1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		testBody(t, r, `{"position":0}`+"\n")
+		fmt.Fprint(w, `{"checklist": {"id": "c1", "orderindex": 0}}`)
+	})
+
+	checklist, _, err := client.Checklists.MoveChecklist(context.Background(), "c1", 0)
+	if err != nil {
+		t.Errorf("Checklists.MoveChecklist returned error: %v", err)
+	}
+	if want := (&Checklist{ID: "c1", Orderindex: "0"}); !cmp.Equal(checklist, want) {
+		t.Errorf("Checklists.MoveChecklist returned %+v, want %+v", checklist, want)
+	}
+}
+
+func TestChecklistsService_UpdateChecklistItem(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name string
+		call func(client *Client) (*Checklist, *Response, error)
+		body string
+	}{
+		{"assign", func(client *Client) (*Checklist, *Response, error) {
+			return client.Checklists.AssignChecklistItem(ctx, "c1", "i1", 183)
+		}, `{"assignee":183}`},
+		{"unassign", func(client *Client) (*Checklist, *Response, error) {
+			return client.Checklists.UnassignChecklistItem(ctx, "c1", "i1")
+		}, `{"assignee":null}`},
+		{"nest", func(client *Client) (*Checklist, *Response, error) {
+			return client.Checklists.MoveChecklistItem(ctx, "c1", "i1", "i2", 1)
+		}, `{"orderindex":1,"parent":"i2"}`},
+		{"unnest", func(client *Client) (*Checklist, *Response, error) {
+			return client.Checklists.MoveChecklistItem(ctx, "c1", "i1", "", 0)
+		}, `{"orderindex":0,"parent":null}`},
+		{"unresolve", func(client *Client) (*Checklist, *Response, error) {
+			resolved := false
+			return client.Checklists.UpdateChecklistItem(ctx, "c1", "i1", &ChecklistItemUpdate{Resolved: &resolved})
+		}, `{"resolved":false}`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mux, _, teardown := setup()
+			defer teardown()
+
+			mux.HandleFunc("/checklist/c1/checklist_item/i1", func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "PUT")
+				testBody(t, r, tt.body+"\n")
+				fmt.Fprint(w, `{"checklist": {"id": "c1"}}`)
+			})
+
+			checklist, _, err := tt.call(client)
+			if err != nil {
+				t.Fatalf("returned error: %v", err)
+			}
+			if checklist.ID != "c1" {
+				t.Errorf("returned %+v, want checklist c1", checklist)
+			}
+		})
+	}
+}
+
+func TestChecklistsService_CreateChecklistItem_parent(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/checklist/c1/checklist_item", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		testBody(t, r, `{"name":"SSO","parent":"i1"}`+"\n")
+		fmt.Fprint(w, `{"checklist": {"id": "c1"}}`)
+	})
+
+	_, _, err := client.Checklists.CreateChecklistItem(context.Background(), "c1", &ChecklistItemRequest{Name: "SSO", Parent: "i1"})
+	if err != nil {
+		t.Errorf("Checklists.CreateChecklistItem returned error: %v", err)
+	}
+}
@@ -376,6 +376,36 @@ func (c *Client) checkRateLimitBeforeDo(req *http.Request) *RateLimitError {
 	return nil
 }
 
+// RateLimit returns the rate limit reported by the most recent API call.
+func (c *Client) RateLimit() Rate {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+
+	return c.rateLimits
+}
+
+// waitRateLimit blocks until the rate limit resets when no more than reserve
+// requests remain, so background work leaves requests for everything else.
+func (c *Client) waitRateLimit(ctx context.Context, reserve int) error {
+	rate := c.RateLimit()
+	if rate.Limit == 0 || rate.Remaining > reserve || rate.Reset.Time.IsZero() {
+		return nil
+	}
+	wait := time.Until(rate.Reset.Time)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 // compareHttpResponse returns whether two http.Response objects are equal or not.
 // Currently, only StatusCode is checked. This function is used when implementing the
 // Is(error) bool interface for the custom error types in this package.
@@ -0,0 +1,136 @@
+package clickup
+
+import (
+	"context"
+	"strconv"
+)
+
+// commentsPageSize is the number of comments ClickUp returns per page.
+const commentsPageSize = 25
+
+// CommentIterator pages backwards through the comments of a task, list or
+// chat view, newest first.
+//
+//	it := client.Comments.TaskCommentsIterator("9hz", nil)
+//	for it.Next(ctx) {
+//		c := it.Comment()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type CommentIterator struct {
+	fetch func(ctx context.Context, start int64, startID string) ([]Comment, *Response, error)
+
+	start   int64
+	startID string
+	seen    map[int]bool
+	page    []Comment
+	current Comment
+	done    bool
+	resp    *Response
+	err     error
+}
+
+func newCommentIterator(fetch func(ctx context.Context, start int64, startID string) ([]Comment, *Response, error)) *CommentIterator {
+	return &CommentIterator{fetch: fetch, seen: map[int]bool{}}
+}
+
+// TaskCommentsIterator iterates over every comment of a task. The Start and
+// StartID of opts, if any, select where to begin.
+func (s *CommentsService) TaskCommentsIterator(taskID string, opts *TaskCommentOptions) *CommentIterator {
+	o := TaskCommentOptions{}
+	if opts != nil {
+		o = *opts
+	}
+
+	it := newCommentIterator(func(ctx context.Context, start int64, startID string) ([]Comment, *Response, error) {
+		o.Start, o.StartID = start, startID
+		return s.GetTaskComments(ctx, taskID, &o)
+	})
+	it.start, it.startID = o.Start, o.StartID
+
+	return it
+}
+
+// ListCommentsIterator iterates over every comment of a list.
+func (s *CommentsService) ListCommentsIterator(listID int) *CommentIterator {
+	return newCommentIterator(func(ctx context.Context, start int64, startID string) ([]Comment, *Response, error) {
+		return s.GetListCommentsPage(ctx, listID, &CommentPageOptions{Start: start, StartID: startID})
+	})
+}
+
+// ChatViewCommentsIterator iterates over every comment of a chat view.
+func (s *CommentsService) ChatViewCommentsIterator(viewID string) *CommentIterator {
+	return newCommentIterator(func(ctx context.Context, start int64, startID string) ([]Comment, *Response, error) {
+		return s.GetChatViewCommentsPage(ctx, viewID, &CommentPageOptions{Start: start, StartID: startID})
+	})
+}
+
+// Next advances to the next comment, fetching the next page when needed. It
+// returns false when there are no more comments or an error occurred.
+func (it *CommentIterator) Next(ctx context.Context) bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.fetchPage(ctx)
+	}
+
+	it.current, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+func (it *CommentIterator) fetchPage(ctx context.Context) {
+	comments, resp, err := it.fetch(ctx, it.start, it.startID)
+	it.resp = resp
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	if len(comments) < commentsPageSize {
+		it.done = true
+	}
+
+	// The comment a page starts from may be returned again; skip it.
+	for _, c := range comments {
+		if !it.seen[c.ID] {
+			it.seen[c.ID] = true
+			it.page = append(it.page, c)
+		}
+	}
+	if len(it.page) == 0 {
+		it.done = true
+		return
+	}
+
+	oldest := comments[len(comments)-1]
+	it.start, _ = strconv.ParseInt(oldest.Date, 10, 64)
+	it.startID = strconv.Itoa(oldest.ID)
+}
+
+// Comment returns the current comment.
+func (it *CommentIterator) Comment() Comment {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *CommentIterator) Err() error {
+	return it.err
+}
+
+// Response returns the response of the last page fetched.
+func (it *CommentIterator) Response() *Response {
+	return it.resp
+}
+
+// All returns the remaining comments.
+func (it *CommentIterator) All(ctx context.Context) ([]Comment, error) {
+	var comments []Comment
+	for it.Next(ctx) {
+		comments = append(comments, it.Comment())
+	}
+
+	return comments, it.Err()
+}
@@ -0,0 +1,84 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// commentPages serves comments numbered from total down to 1, commentsPageSize
+// at a time, paging with start_id like ClickUp and repeating the start comment.
+func commentPages(t *testing.T, total int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+
+		from := total
+		if id := r.URL.Query().Get("start_id"); id != "" {
+			from, _ = strconv.Atoi(id)
+			if got, want := r.URL.Query().Get("start"), strconv.Itoa(1000+from); got != want {
+				t.Errorf("start = %q, want %q", got, want)
+			}
+		}
+
+		var comments []string
+		for id := from; id > 0 && len(comments) < commentsPageSize; id-- {
+			comments = append(comments, fmt.Sprintf(`{"id": %d, "date": "%d"}`, id, 1000+id))
+		}
+		fmt.Fprintf(w, `{"comments": [%s]}`, strings.Join(comments, ","))
+	}
+}
+
+func TestCommentIterator(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/task/9hz/comment", commentPages(t, 60))
+	mux.HandleFunc("/list/123/comment", commentPages(t, 3))
+	mux.HandleFunc("/view/v1/comment", commentPages(t, 25))
+
+	ctx := context.Background()
+	for name, tt := range map[string]struct {
+		it   *CommentIterator
+		want int
+	}{
+		"task": {client.Comments.TaskCommentsIterator("9hz", nil), 60},
+		"list": {client.Comments.ListCommentsIterator(123), 3},
+		"view": {client.Comments.ChatViewCommentsIterator("v1"), 25},
+	} {
+		comments, err := tt.it.All(ctx)
+		if err != nil {
+			t.Errorf("%s: All returned error: %v", name, err)
+			continue
+		}
+		if len(comments) != tt.want {
+			t.Errorf("%s: All returned %d comments, want %d", name, len(comments), tt.want)
+			continue
+		}
+		for i, c := range comments {
+			if c.ID != tt.want-i {
+				t.Errorf("%s: comment %d has ID %d, want %d", name, i, c.ID, tt.want-i)
+				break
+			}
+		}
+	}
+}
+
+func TestCommentIterator_error(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/task/9hz/comment", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	it := client.Comments.TaskCommentsIterator("9hz", nil)
+	if it.Next(context.Background()) {
+		t.Error("Next returned true on an error")
+	}
+	if it.Err() == nil {
+		t.Error("Err returned nil")
+	}
+}
@@ -0,0 +1,372 @@
+package clickup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ExternalComment is a comment in an external conversation store. Text is
+// Markdown in the dialect of CommentToMarkdown.
+type ExternalComment struct {
+	ID       string
+	Author   string
+	Text     string
+	Resolved bool
+	Date     time.Time
+	// Mirrored is set on comments the mirror created. The store must keep it
+	// so mirrored comments are not copied back to ClickUp.
+	Mirrored bool
+}
+
+// CommentStore is an external conversation store holding threads of comments.
+type CommentStore interface {
+	ListComments(ctx context.Context, threadID string) ([]ExternalComment, error)
+	// CreateComment returns the ID of the new comment.
+	CreateComment(ctx context.Context, threadID string, c ExternalComment) (string, error)
+	UpdateComment(ctx context.Context, threadID string, c ExternalComment) error
+	DeleteComment(ctx context.Context, threadID string, commentID string) error
+}
+
+// CommentLink maps a ClickUp comment to its external copy, with the state
+// both had when they were last synced.
+type CommentLink struct {
+	ClickUpID  int    `json:"clickup_id"`
+	ExternalID string `json:"external_id"`
+	// ClickUpHash and ExternalHash are hashes of the synced texts. They are
+	// empty until the first sync after the comment was created.
+	ClickUpHash  string `json:"clickup_hash,omitempty"`
+	ExternalHash string `json:"external_hash,omitempty"`
+	Resolved     bool   `json:"resolved"`
+}
+
+// CommentLinkStore persists the CommentLinks of each task.
+type CommentLinkStore interface {
+	Links(ctx context.Context, taskID string) ([]CommentLink, error)
+	SaveLinks(ctx context.Context, taskID string, links []CommentLink) error
+}
+
+// FileCommentLinkStore is a CommentLinkStore kept in a JSON file.
+type FileCommentLinkStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileCommentLinkStore(path string) *FileCommentLinkStore {
+	return &FileCommentLinkStore{path: path}
+}
+
+func (f *FileCommentLinkStore) Links(ctx context.Context, taskID string) ([]CommentLink, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	all, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return all[taskID], nil
+}
+
+func (f *FileCommentLinkStore) SaveLinks(ctx context.Context, taskID string, links []CommentLink) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	all, err := f.load()
+	if err != nil {
+		return err
+	}
+	if len(links) == 0 {
+		delete(all, taskID)
+	} else {
+		all[taskID] = links
+	}
+
+	b, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(f.path, b)
+}
+
+// writeFileAtomic writes a temporary file and renames it over path, so a
+// crash never leaves a truncated file.
+func writeFileAtomic(path string, b []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (f *FileCommentLinkStore) load() (map[string][]CommentLink, error) {
+	all := map[string][]CommentLink{}
+
+	b, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, fmt.Errorf("reading comment links from %s: %w", f.path, err)
+	}
+
+	return all, nil
+}
+
+type CommentMirrorOptions struct {
+	// BotUserID is the ClickUp user the mirror posts as. Its comments are
+	// never copied to the external store unless they are already linked.
+	// When it is 0, the comments the mirror posts are only recognized by
+	// their links: if saving the links fails after a comment was posted,
+	// the next Sync copies that comment back to the external thread.
+	BotUserID int
+	// TaskCommentOptions are used to read and create task comments.
+	TaskCommentOptions *TaskCommentOptions
+}
+
+// CommentMirror keeps the comments of a ClickUp task and an external thread
+// in sync in both directions.
+type CommentMirror struct {
+	client *Client
+	store  CommentStore
+	links  CommentLinkStore
+	opts   CommentMirrorOptions
+}
+
+func NewCommentMirror(client *Client, store CommentStore, links CommentLinkStore, opts *CommentMirrorOptions) *CommentMirror {
+	m := &CommentMirror{client: client, store: store, links: links}
+	if opts != nil {
+		m.opts = *opts
+	}
+
+	return m
+}
+
+// CommentSyncResult counts the changes made by a sync.
+type CommentSyncResult struct {
+	CreatedInClickUp  int
+	CreatedExternally int
+	UpdatedInClickUp  int
+	UpdatedExternally int
+	DeletedInClickUp  int
+	DeletedExternally int
+	// Conflicts counts comments edited on both sides; ClickUp wins.
+	Conflicts int
+}
+
+// Sync mirrors the comments of a task and an external thread. New comments
+// are copied to the other side, edits and resolved state follow the side that
+// changed, and a comment deleted on one side is deleted on the other.
+//
+// Comments the mirror created are recognized by their link, by the bot user
+// on ClickUp and by ExternalComment.Mirrored, so they are never echoed back.
+func (m *CommentMirror) Sync(ctx context.Context, taskID, threadID string) (*CommentSyncResult, error) {
+	links, err := m.links.Links(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	clickup, err := m.client.Comments.TaskCommentsIterator(taskID, m.opts.TaskCommentOptions).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	external, err := m.store.ListComments(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+
+	cuByID := map[int]*Comment{}
+	for i := range clickup {
+		cuByID[clickup[i].ID] = &clickup[i]
+	}
+	extByID := map[string]*ExternalComment{}
+	for i := range external {
+		extByID[external[i].ID] = &external[i]
+	}
+
+	res := &CommentSyncResult{}
+	linkedCU := map[int]bool{}
+	linkedExt := map[string]bool{}
+
+	// Save whatever was done, even when a later step fails, so nothing is mirrored twice.
+	var kept []CommentLink
+	save := func(err error) (*CommentSyncResult, error) {
+		if saveErr := m.links.SaveLinks(ctx, taskID, kept); err == nil {
+			err = saveErr
+		}
+		return res, err
+	}
+
+	for i, l := range links {
+		linkedCU[l.ClickUpID] = true
+		linkedExt[l.ExternalID] = true
+
+		cu, ext := cuByID[l.ClickUpID], extByID[l.ExternalID]
+		switch {
+		case cu == nil && ext == nil:
+			continue
+		case cu == nil:
+			if err := m.store.DeleteComment(ctx, threadID, l.ExternalID); err != nil {
+				kept = append(kept, links[i:]...)
+				return save(err)
+			}
+			res.DeletedExternally++
+			continue
+		case ext == nil:
+			if _, err := m.client.Comments.DeleteComment(ctx, l.ClickUpID); err != nil {
+				kept = append(kept, links[i:]...)
+				return save(err)
+			}
+			res.DeletedInClickUp++
+			continue
+		}
+
+		if err := m.syncLinked(ctx, threadID, &l, cu, ext, res); err != nil {
+			kept = append(kept, links[i:]...)
+			return save(err)
+		}
+		kept = append(kept, l)
+	}
+
+	for _, c := range clickup {
+		if linkedCU[c.ID] || (m.opts.BotUserID != 0 && c.User.ID == m.opts.BotUserID) {
+			continue
+		}
+
+		ext := ExternalComment{
+			Author:   c.User.Username,
+			Text:     clickUpCommentText(&c),
+			Resolved: c.Resolved,
+			Mirrored: true,
+		}
+		if date := unixMilliString(c.Date); date != nil {
+			ext.Date = *date
+		}
+
+		id, err := m.store.CreateComment(ctx, threadID, ext)
+		if err != nil {
+			return save(err)
+		}
+		kept = append(kept, CommentLink{ClickUpID: c.ID, ExternalID: id, Resolved: c.Resolved})
+		res.CreatedExternally++
+	}
+
+	for _, e := range external {
+		if linkedExt[e.ID] || e.Mirrored {
+			continue
+		}
+
+		req := NewCommentBuilder().Markdown(e.Text).Request()
+		ccr, _, err := m.client.Comments.CreateTaskComment(ctx, taskID, m.opts.TaskCommentOptions, req)
+		if err != nil {
+			return save(err)
+		}
+		if e.Resolved {
+			update := &UpdateCommentRequest{CommentText: req.CommentText, Comment: req.Comment, Resolved: true}
+			if _, err := m.client.Comments.UpdateComment(ctx, ccr.ID, update); err != nil {
+				return save(err)
+			}
+		}
+		kept = append(kept, CommentLink{ClickUpID: ccr.ID, ExternalID: e.ID, Resolved: e.Resolved})
+		res.CreatedInClickUp++
+	}
+
+	return save(nil)
+}
+
+// syncLinked propagates edits and resolved state between linked comments and
+// records the synced state in l.
+func (m *CommentMirror) syncLinked(ctx context.Context, threadID string, l *CommentLink, cu *Comment, ext *ExternalComment, res *CommentSyncResult) error {
+	cuText := clickUpCommentText(cu)
+	cuHash, extHash := commentHash(cuText), commentHash(ext.Text)
+
+	// A fresh link takes the current texts as the baseline, since ClickUp and
+	// the store may normalize what was sent.
+	if l.ClickUpHash == "" {
+		l.ClickUpHash = cuHash
+	}
+	if l.ExternalHash == "" {
+		l.ExternalHash = extHash
+	}
+
+	cuEdited, extEdited := cuHash != l.ClickUpHash, extHash != l.ExternalHash
+	if cuEdited && extEdited {
+		res.Conflicts++
+	}
+
+	resolved := l.Resolved
+	if ext.Resolved != l.Resolved {
+		resolved = ext.Resolved
+	}
+	if cu.Resolved != l.Resolved {
+		resolved = cu.Resolved
+	}
+
+	if cuEdited || ext.Resolved != resolved {
+		updated := *ext
+		updated.Resolved = resolved
+		if cuEdited {
+			updated.Text = cuText
+		}
+		if err := m.store.UpdateComment(ctx, threadID, updated); err != nil {
+			return err
+		}
+		extHash = commentHash(updated.Text)
+		res.UpdatedExternally++
+	}
+
+	if pushText := extEdited && !cuEdited; pushText || cu.Resolved != resolved {
+		text, blocks := cu.CommentText, cu.Comment
+		if pushText {
+			blocks = CommentFromMarkdown(ext.Text)
+			text = CommentPlainText(blocks)
+			cuHash = commentHash(CommentToMarkdown(blocks))
+		}
+		update := &UpdateCommentRequest{CommentText: text, Comment: blocks, Resolved: resolved, Unresolve: !resolved}
+		if _, err := m.client.Comments.UpdateComment(ctx, cu.ID, update); err != nil {
+			return err
+		}
+		res.UpdatedInClickUp++
+	}
+
+	l.ClickUpHash, l.ExternalHash, l.Resolved = cuHash, extHash, resolved
+	return nil
+}
+
+func clickUpCommentText(c *Comment) string {
+	if len(c.Comment) == 0 {
+		return CommentToMarkdown([]CommentInComment{{Text: c.CommentText}})
+	}
+
+	return CommentToMarkdown(c.Comment)
+}
+
+func commentHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,273 @@
+package clickup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type memCommentStore struct {
+	comments []ExternalComment
+	next     int
+}
+
+func (s *memCommentStore) ListComments(ctx context.Context, threadID string) ([]ExternalComment, error) {
+	return append([]ExternalComment{}, s.comments...), nil
+}
+
+func (s *memCommentStore) CreateComment(ctx context.Context, threadID string, c ExternalComment) (string, error) {
+	s.next++
+	c.ID = fmt.Sprintf("x%d", s.next)
+	s.comments = append(s.comments, c)
+	return c.ID, nil
+}
+
+func (s *memCommentStore) UpdateComment(ctx context.Context, threadID string, c ExternalComment) error {
+	for i := range s.comments {
+		if s.comments[i].ID == c.ID {
+			s.comments[i] = c
+			return nil
+		}
+	}
+	return fmt.Errorf("no comment %s", c.ID)
+}
+
+func (s *memCommentStore) DeleteComment(ctx context.Context, threadID string, commentID string) error {
+	for i := range s.comments {
+		if s.comments[i].ID == commentID {
+			s.comments = append(s.comments[:i], s.comments[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("no comment %s", commentID)
+}
+
+func (s *memCommentStore) get(id string) *ExternalComment {
+	for i := range s.comments {
+		if s.comments[i].ID == id {
+			return &s.comments[i]
+		}
+	}
+	return nil
+}
+
+// fakeTaskComments serves the comments of task 9hz, posting new ones as user 99.
+type fakeTaskComments struct {
+	t        *testing.T
+	comments []Comment
+	next     int
+}
+
+func (f *fakeTaskComments) register(mux *http.ServeMux) {
+	mux.HandleFunc("/task/9hz/comment", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			json.NewEncoder(w).Encode(GetCommentsResponse{Comments: f.comments})
+		case "POST":
+			var req CommentRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			f.next++
+			f.comments = append([]Comment{{ID: f.next, Comment: req.Comment, CommentText: req.CommentText, User: User{ID: 99}}}, f.comments...)
+			fmt.Fprintf(w, `{"id": %d}`, f.next)
+		}
+	})
+	mux.HandleFunc("/comment/", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/comment/"))
+		c := f.get(id)
+		if c == nil {
+			f.t.Errorf("%s of unknown comment %d", r.Method, id)
+			return
+		}
+
+		switch r.Method {
+		case "PUT":
+			var body struct {
+				CommentText string             `json:"comment_text"`
+				Comment     []CommentInComment `json:"comment"`
+				Resolved    *bool              `json:"resolved"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.Resolved == nil {
+				f.t.Errorf("updating comment %d without resolved", id)
+			} else {
+				c.Resolved = *body.Resolved
+			}
+			c.CommentText, c.Comment = body.CommentText, body.Comment
+		case "DELETE":
+			for i := range f.comments {
+				if f.comments[i].ID == id {
+					f.comments = append(f.comments[:i], f.comments[i+1:]...)
+					break
+				}
+			}
+		}
+	})
+}
+
+func (f *fakeTaskComments) get(id int) *Comment {
+	for i := range f.comments {
+		if f.comments[i].ID == id {
+			return &f.comments[i]
+		}
+	}
+	return nil
+}
+
+func TestCommentMirror_Sync(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	cu := &fakeTaskComments{t: t, next: 500, comments: []Comment{
+		{ID: 2, CommentText: "bot says hi", User: User{ID: 99}},
+		{ID: 1, Comment: []CommentInComment{{Text: "from "}, {Text: "ClickUp", Attributes: &CommentAttributes{Bold: true}}}, User: User{ID: 7, Username: "ann"}, Date: "1700000000000"},
+	}}
+	cu.register(mux)
+
+	store := &memCommentStore{comments: []ExternalComment{
+		{ID: "e1", Author: "bob", Text: "from _tickets_"},
+		{ID: "e2", Text: "echo", Mirrored: true},
+	}}
+	links := NewFileCommentLinkStore(filepath.Join(t.TempDir(), "links.json"))
+	mirror := NewCommentMirror(client, store, links, &CommentMirrorOptions{BotUserID: 99})
+	ctx := context.Background()
+
+	sync := func(want CommentSyncResult) {
+		t.Helper()
+		res, err := mirror.Sync(ctx, "9hz", "thread")
+		if err != nil {
+			t.Fatalf("Sync returned error: %v", err)
+		}
+		if !cmp.Equal(*res, want) {
+			t.Errorf("Sync = %+v, want %+v", *res, want)
+		}
+	}
+
+	// New comments are copied across; the bot's comment and mirrored ones are not.
+	sync(CommentSyncResult{CreatedExternally: 1, CreatedInClickUp: 1})
+	if x := store.get("x1"); x == nil || x.Text != "from **ClickUp**" || !x.Mirrored || x.Author != "ann" {
+		t.Errorf("mirrored external comment = %+v", x)
+	}
+	if c := cu.get(501); c == nil || CommentToMarkdown(c.Comment) != "from _tickets_" {
+		t.Errorf("mirrored ClickUp comment = %+v", c)
+	}
+
+	// Nothing changed: a second sync is a no-op, so mirrored comments do not echo.
+	sync(CommentSyncResult{})
+
+	// Edits and resolved state follow the side that changed.
+	cu.get(1).Comment = []CommentInComment{{Text: "edited in ClickUp"}}
+	store.get("e1").Resolved = true
+	sync(CommentSyncResult{UpdatedExternally: 1, UpdatedInClickUp: 1})
+	if x := store.get("x1"); x.Text != "edited in ClickUp" {
+		t.Errorf("external copy not edited: %+v", x)
+	}
+	if c := cu.get(501); !c.Resolved || CommentToMarkdown(c.Comment) != "from _tickets_" {
+		t.Errorf("ClickUp copy not resolved: %+v", c)
+	}
+	store.get("e1").Resolved = false
+	sync(CommentSyncResult{UpdatedInClickUp: 1})
+	if c := cu.get(501); c.Resolved {
+		t.Errorf("ClickUp copy still resolved: %+v", c)
+	}
+
+	store.get("x1").Text = "edited in tickets"
+	sync(CommentSyncResult{UpdatedInClickUp: 1})
+	if got := CommentToMarkdown(cu.get(1).Comment); got != "edited in tickets" {
+		t.Errorf("ClickUp comment = %q, want the external edit", got)
+	}
+	sync(CommentSyncResult{})
+
+	// Deletes propagate both ways and drop the links.
+	store.DeleteComment(ctx, "thread", "e1")
+	cu.comments = []Comment{*cu.get(2), *cu.get(501)}
+	sync(CommentSyncResult{DeletedInClickUp: 1, DeletedExternally: 1})
+	if cu.get(501) != nil || store.get("x1") != nil {
+		t.Errorf("copies not deleted: ClickUp %+v, external %+v", cu.comments, store.comments)
+	}
+	if got, _ := links.Links(ctx, "9hz"); len(got) != 0 {
+		t.Errorf("links after deletes = %+v, want none", got)
+	}
+}
+
+func TestCommentMirror_SyncWithoutBotUser(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	cu := &fakeTaskComments{t: t, next: 500}
+	cu.register(mux)
+	store := &memCommentStore{comments: []ExternalComment{{ID: "e1", Author: "bob", Text: "from tickets"}}}
+	links := NewFileCommentLinkStore(filepath.Join(t.TempDir(), "links.json"))
+	mirror := NewCommentMirror(client, store, links, nil)
+	ctx := context.Background()
+
+	res, err := mirror.Sync(ctx, "9hz", "thread")
+	if err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	if want := (CommentSyncResult{CreatedInClickUp: 1}); !cmp.Equal(*res, want) {
+		t.Errorf("Sync = %+v, want %+v", *res, want)
+	}
+
+	// The posted comment is recognized by its link alone.
+	res, err = mirror.Sync(ctx, "9hz", "thread")
+	if err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	if want := (CommentSyncResult{}); !cmp.Equal(*res, want) {
+		t.Errorf("second Sync = %+v, want %+v", *res, want)
+	}
+	if len(store.comments) != 1 {
+		t.Errorf("external comments = %+v, want only e1", store.comments)
+	}
+}
+
+func TestCommentMirror_SyncDeletesInClickUp(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	cu := &fakeTaskComments{t: t, comments: []Comment{{ID: 1, CommentText: "hi", User: User{ID: 7}}}}
+	cu.register(mux)
+
+	store := &memCommentStore{}
+	links := NewFileCommentLinkStore(filepath.Join(t.TempDir(), "links.json"))
+	mirror := NewCommentMirror(client, store, links, nil)
+	ctx := context.Background()
+
+	if _, err := mirror.Sync(ctx, "9hz", "thread"); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	store.comments = nil
+
+	res, err := mirror.Sync(ctx, "9hz", "thread")
+	if err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	if res.DeletedInClickUp != 1 || len(cu.comments) != 0 {
+		t.Errorf("Sync = %+v, ClickUp comments = %+v, want comment 1 deleted", res, cu.comments)
+	}
+}
+
+func TestFileCommentLinkStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.json")
+	ctx := context.Background()
+
+	want := []CommentLink{{ClickUpID: 1, ExternalID: "x1", ClickUpHash: "a", ExternalHash: "b", Resolved: true}}
+	if err := NewFileCommentLinkStore(path).SaveLinks(ctx, "9hz", want); err != nil {
+		t.Fatalf("SaveLinks returned error: %v", err)
+	}
+
+	got, err := NewFileCommentLinkStore(path).Links(ctx, "9hz")
+	if err != nil {
+		t.Fatalf("Links returned error: %v", err)
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("Links = %+v, want %+v", got, want)
+	}
+}
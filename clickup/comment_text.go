@@ -0,0 +1,615 @@
+package clickup
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CommentBuilder builds the rich text blocks of a comment.
+//
+//	comment := NewCommentBuilder().
+//		Text("Deploy is ").Bold("blocked").Text(" by ").MentionTask("9hz").
+//		Text(", ").MentionUser(183).Text(" can you look?").
+//		Request()
+type CommentBuilder struct {
+	blocks []CommentInComment
+}
+
+func NewCommentBuilder() *CommentBuilder {
+	return &CommentBuilder{}
+}
+
+// Text appends plain text. Newlines start a new line.
+func (b *CommentBuilder) Text(text string) *CommentBuilder {
+	return b.Styled(text, CommentAttributes{})
+}
+
+func (b *CommentBuilder) Bold(text string) *CommentBuilder {
+	return b.Styled(text, CommentAttributes{Bold: true})
+}
+
+func (b *CommentBuilder) Italic(text string) *CommentBuilder {
+	return b.Styled(text, CommentAttributes{Italic: true})
+}
+
+func (b *CommentBuilder) Strike(text string) *CommentBuilder {
+	return b.Styled(text, CommentAttributes{Strike: true})
+}
+
+// Code appends inline code.
+func (b *CommentBuilder) Code(text string) *CommentBuilder {
+	return b.Styled(text, CommentAttributes{Code: true})
+}
+
+func (b *CommentBuilder) Link(text, url string) *CommentBuilder {
+	return b.Styled(text, CommentAttributes{Link: url})
+}
+
+// Styled appends text with any combination of attributes.
+func (b *CommentBuilder) Styled(text string, attrs CommentAttributes) *CommentBuilder {
+	b.blocks = appendCommentBlock(b.blocks, CommentInComment{Text: text, Attributes: &attrs})
+	return b
+}
+
+// Newline ends the current line.
+func (b *CommentBuilder) Newline() *CommentBuilder {
+	return b.Text("\n")
+}
+
+func (b *CommentBuilder) MentionUser(userID int) *CommentBuilder {
+	b.blocks = append(b.blocks, CommentInComment{Type: CommentBlockUserMention, User: &CommentUser{ID: userID}})
+	return b
+}
+
+func (b *CommentBuilder) MentionTask(taskID string) *CommentBuilder {
+	b.blocks = append(b.blocks, CommentInComment{Type: CommentBlockTaskMention, TaskMention: &CommentTaskMention{TaskID: taskID}})
+	return b
+}
+
+// Emoji appends an emoji by its hexadecimal code point, such as "1f44d".
+func (b *CommentBuilder) Emoji(code string) *CommentBuilder {
+	b.blocks = append(b.blocks, CommentInComment{Type: CommentBlockEmoticon, Emoticon: &CommentEmoticon{Code: code}})
+	return b
+}
+
+// CodeBlock appends a code block on its own lines.
+func (b *CommentBuilder) CodeBlock(code, language string) *CommentBuilder {
+	if n := len(b.blocks); n > 0 && !strings.HasSuffix(b.blocks[n-1].Text, "\n") {
+		b.Newline()
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(code, "\n"), "\n") {
+		b.blocks = appendCommentCodeLine(b.blocks, line, language)
+	}
+
+	return b
+}
+
+// Markdown appends text written in the Markdown dialect of CommentToMarkdown.
+func (b *CommentBuilder) Markdown(md string) *CommentBuilder {
+	for _, block := range CommentFromMarkdown(md) {
+		b.blocks = appendCommentBlock(b.blocks, block)
+	}
+
+	return b
+}
+
+// Blocks returns the blocks built so far.
+func (b *CommentBuilder) Blocks() []CommentInComment {
+	return append([]CommentInComment{}, b.blocks...)
+}
+
+// Request returns a CommentRequest with the rich text and its plain text.
+func (b *CommentBuilder) Request() *CommentRequest {
+	return &CommentRequest{
+		CommentText: CommentPlainText(b.blocks),
+		Comment:     b.Blocks(),
+	}
+}
+
+// Markdown returns the comment in the Markdown dialect of CommentToMarkdown.
+func (c *Comment) Markdown() string {
+	return CommentToMarkdown(c.Comment)
+}
+
+// CommentPlainText returns the text of blocks without formatting. Mentions
+// are written as @username, or @ID if the username is unknown, and #taskID.
+func CommentPlainText(blocks []CommentInComment) string {
+	sb := strings.Builder{}
+	for _, b := range blocks {
+		switch {
+		case b.Type == CommentBlockUserMention && b.User != nil:
+			if b.User.Username != "" {
+				sb.WriteString("@" + b.User.Username)
+			} else {
+				sb.WriteString("@" + strconv.Itoa(b.User.ID))
+			}
+		case b.Type == CommentBlockTaskMention && b.TaskMention != nil:
+			sb.WriteString("#" + b.TaskMention.TaskID)
+		case b.Type == CommentBlockEmoticon && b.Emoticon != nil:
+			sb.WriteString(emoticonString(b.Emoticon.Code))
+		default:
+			sb.WriteString(b.Text)
+		}
+	}
+
+	return sb.String()
+}
+
+func emoticonString(code string) string {
+	sb := strings.Builder{}
+	for _, part := range strings.Split(code, "-") {
+		r, err := strconv.ParseUint(part, 16, 32)
+		if err != nil || !utf8.ValidRune(rune(r)) {
+			return ":" + code + ":"
+		}
+		sb.WriteRune(rune(r))
+	}
+
+	return sb.String()
+}
+
+// CommentToMarkdown converts rich text blocks to Markdown. Bold, italic,
+// strikethrough, inline code, links and fenced code blocks use the usual
+// syntax. The ClickUp specific blocks are written as:
+//
+//	<@183>    a mention of the user with ID 183
+//	<#9hz>    a mention of the task with ID 9hz
+//	:1f44d:   the emoji with code point 1f44d
+func CommentToMarkdown(blocks []CommentInComment) string {
+	var (
+		sb     strings.Builder
+		line   []CommentInComment
+		inCode bool
+		lang   string
+	)
+
+	closeCode := func() {
+		if inCode {
+			sb.WriteString("```\n")
+			inCode = false
+		}
+	}
+
+	for _, b := range blocks {
+		if b.Type != "" {
+			line = append(line, b)
+			continue
+		}
+
+		if b.Attributes != nil && b.Attributes.CodeBlock != nil {
+			for i := strings.Count(b.Text, "\n"); i > 0; i-- {
+				if !inCode || lang != b.Attributes.CodeBlock.Language {
+					closeCode()
+					lang = b.Attributes.CodeBlock.Language
+					sb.WriteString("```" + lang + "\n")
+					inCode = true
+				}
+				sb.WriteString(CommentPlainText(line) + "\n")
+				line = nil
+			}
+			continue
+		}
+
+		parts := strings.Split(b.Text, "\n")
+		for i, part := range parts {
+			if part != "" {
+				line = append(line, CommentInComment{Text: part, Attributes: b.Attributes})
+			}
+			if i < len(parts)-1 {
+				closeCode()
+				sb.WriteString(markdownInline(line) + "\n")
+				line = nil
+			}
+		}
+	}
+
+	if inCode && len(line) == 0 {
+		sb.WriteString("```")
+	} else {
+		closeCode()
+		sb.WriteString(markdownInline(line))
+	}
+
+	return sb.String()
+}
+
+func markdownInline(blocks []CommentInComment) string {
+	parts := make([]string, len(blocks))
+	// italic marks the parts wrapped in _ on the outside.
+	italic := make([]bool, len(blocks))
+	for i, b := range blocks {
+		switch {
+		case b.Type == CommentBlockUserMention && b.User != nil:
+			parts[i] = fmt.Sprintf("<@%d>", b.User.ID)
+			continue
+		case b.Type == CommentBlockTaskMention && b.TaskMention != nil:
+			parts[i] = fmt.Sprintf("<#%s>", b.TaskMention.TaskID)
+			continue
+		case b.Type == CommentBlockEmoticon && b.Emoticon != nil:
+			parts[i] = fmt.Sprintf(":%s:", b.Emoticon.Code)
+			continue
+		case b.Type != "":
+			parts[i] = escapeMarkdown(b.Text)
+			continue
+		}
+
+		a := CommentAttributes{}
+		if b.Attributes != nil {
+			a = *b.Attributes
+		}
+
+		s := escapeMarkdown(b.Text)
+		if a.Code {
+			s = markdownCodeSpan(b.Text)
+		}
+		if a.Strike {
+			s = "~~" + s + "~~"
+		}
+		if a.Italic {
+			s = "_" + s + "_"
+		}
+		if a.Bold {
+			s = "**" + s + "**"
+		}
+		if a.Link != "" {
+			s = "[" + s + "](" + strings.NewReplacer(" ", "%20", ")", "%29").Replace(a.Link) + ")"
+		}
+		parts[i] = s
+		italic[i] = a.Italic && !a.Bold && a.Link == ""
+	}
+
+	// _ does not emphasize within a word, so italics touching a letter or
+	// digit are written with * instead.
+	for i, p := range parts {
+		if italic[i] && (i > 0 && isWordRuneBefore(parts[i-1], len(parts[i-1])) || i < len(parts)-1 && isWordRuneAt(parts[i+1], 0)) {
+			parts[i] = "*" + p[1:len(p)-1] + "*"
+		}
+	}
+
+	return strings.Join(parts, "")
+}
+
+var (
+	markdownEscaper   = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `_`, `\_`, `~`, `\~`, "`", "\\`", `[`, `\[`, `]`, `\]`, `<`, `\<`)
+	emoticonPattern   = regexp.MustCompile(`^:([0-9a-f]+(?:-[0-9a-f]+)*):`)
+	userMentionSyntax = regexp.MustCompile(`^<@(\d+)>`)
+	taskMentionSyntax = regexp.MustCompile(`^<#([^\s>]+)>`)
+)
+
+func escapeMarkdown(s string) string {
+	s = markdownEscaper.Replace(s)
+
+	// A colon only needs escaping where it would start an emoji.
+	sb := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		if s[i] == ':' && emoticonPattern.MatchString(s[i:]) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(s[i])
+	}
+
+	return sb.String()
+}
+
+func markdownCodeSpan(code string) string {
+	longest, run := 0, 0
+	for _, r := range code {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+
+	fence := strings.Repeat("`", longest+1)
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") || strings.HasPrefix(code, " ") && strings.HasSuffix(code, " ") {
+		code = " " + code + " "
+	}
+
+	return fence + code + fence
+}
+
+// CommentFromMarkdown parses Markdown written in the dialect of
+// CommentToMarkdown into rich text blocks. Other Markdown syntax is kept as
+// plain text.
+func CommentFromMarkdown(md string) []CommentInComment {
+	var (
+		blocks []CommentInComment
+		inCode bool
+		lang   string
+	)
+
+	lines := strings.Split(md, "\n")
+	for i, line := range lines {
+		last := i == len(lines)-1
+
+		switch {
+		case !inCode && strings.HasPrefix(line, "```"):
+			inCode, lang = true, strings.TrimSpace(line[3:])
+			continue
+		case inCode && line == "```":
+			inCode = false
+			continue
+		case inCode:
+			if last && line == "" {
+				continue
+			}
+			blocks = appendCommentCodeLine(blocks, line, lang)
+			continue
+		}
+
+		blocks = parseMarkdownInline(blocks, line, CommentAttributes{})
+		if !last {
+			blocks = appendCommentBlock(blocks, CommentInComment{Text: "\n"})
+		}
+	}
+
+	return blocks
+}
+
+func parseMarkdownInline(blocks []CommentInComment, s string, attrs CommentAttributes) []CommentInComment {
+	text := strings.Builder{}
+	flush := func() {
+		if text.Len() > 0 {
+			a := attrs
+			blocks = appendCommentBlock(blocks, CommentInComment{Text: text.String(), Attributes: &a})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		rest := s[i:]
+
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && strings.IndexByte("\\*_~`[]<:", rest[1]) >= 0:
+			text.WriteByte(rest[1])
+			i += 2
+		case rest[0] == '*' || rest[0] == '_' || strings.HasPrefix(rest, "~~"):
+			if strings.HasPrefix(rest, "__") {
+				// Runs of underscores, as in __init__, are not emphasis.
+				n := len(rest) - len(strings.TrimLeft(rest, "_"))
+				text.WriteString(rest[:n])
+				i += n
+				continue
+			}
+			delim := rest[:1]
+			if strings.HasPrefix(rest, "**") || strings.HasPrefix(rest, "~~") {
+				delim = rest[:2]
+			}
+			end, ok := findMarkdownEmphasis(s, i, delim)
+			if !ok {
+				text.WriteString(delim)
+				i += len(delim)
+				continue
+			}
+			flush()
+			a := attrs
+			switch delim {
+			case "**":
+				a.Bold = true
+			case "~~":
+				a.Strike = true
+			default:
+				a.Italic = true
+			}
+			blocks = parseMarkdownInline(blocks, s[i+len(delim):end], a)
+			i = end + len(delim)
+		case rest[0] == '`':
+			code, n, ok := parseMarkdownCodeSpan(rest)
+			if !ok {
+				text.WriteString(rest[:n])
+				i += n
+				continue
+			}
+			flush()
+			a := attrs
+			a.Code = true
+			blocks = appendCommentBlock(blocks, CommentInComment{Text: code, Attributes: &a})
+			i += n
+		case rest[0] == '[':
+			label, url, n, ok := parseMarkdownLink(rest)
+			if !ok {
+				text.WriteByte('[')
+				i++
+				continue
+			}
+			flush()
+			a := attrs
+			a.Link = strings.NewReplacer("%20", " ", "%29", ")").Replace(url)
+			blocks = parseMarkdownInline(blocks, label, a)
+			i += n
+		case userMentionSyntax.MatchString(rest):
+			m := userMentionSyntax.FindStringSubmatch(rest)
+			id, _ := strconv.Atoi(m[1])
+			flush()
+			blocks = append(blocks, CommentInComment{Type: CommentBlockUserMention, User: &CommentUser{ID: id}})
+			i += len(m[0])
+		case taskMentionSyntax.MatchString(rest):
+			m := taskMentionSyntax.FindStringSubmatch(rest)
+			flush()
+			blocks = append(blocks, CommentInComment{Type: CommentBlockTaskMention, TaskMention: &CommentTaskMention{TaskID: m[1]}})
+			i += len(m[0])
+		case emoticonPattern.MatchString(rest):
+			m := emoticonPattern.FindStringSubmatch(rest)
+			flush()
+			blocks = append(blocks, CommentInComment{Type: CommentBlockEmoticon, Emoticon: &CommentEmoticon{Code: m[1]}})
+			i += len(m[0])
+		default:
+			_, size := utf8.DecodeRuneInString(rest)
+			text.WriteString(rest[:size])
+			i += size
+		}
+	}
+	flush()
+
+	return blocks
+}
+
+// findMarkdownEmphasis returns the position of the delimiter closing the
+// emphasis delim opened at s[i]. Only closed, non-empty emphasis counts.
+// Like in CommonMark, * and _ must not be followed by whitespace when
+// opening nor preceded by it when closing, and _ must not open or close
+// within a word, so snake_case stays plain text.
+func findMarkdownEmphasis(s string, i int, delim string) (int, bool) {
+	single := delim == "*" || delim == "_"
+	start := i + len(delim)
+	if start >= len(s) {
+		return 0, false
+	}
+	if single && isSpaceAt(s, start) || delim == "_" && isWordRuneBefore(s, i) {
+		return 0, false
+	}
+
+	for j := start; j < len(s); {
+		switch {
+		case s[j] == '\\':
+			j += 2
+			continue
+		case s[j] == '`':
+			_, n, _ := parseMarkdownCodeSpan(s[j:])
+			j += n
+			continue
+		case delim == "*" && strings.HasPrefix(s[j:], "**"):
+			// Bold nested in italics.
+			j += 2
+			continue
+		case j > start && strings.HasPrefix(s[j:], delim):
+			closes := !single || !isSpaceBefore(s, j)
+			if delim == "_" && isWordRuneAt(s, j+1) {
+				closes = false
+			}
+			if closes {
+				return j, true
+			}
+		}
+		j++
+	}
+
+	return 0, false
+}
+
+func isSpaceAt(s string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	return unicode.IsSpace(r)
+}
+
+func isSpaceBefore(s string, i int) bool {
+	r, _ := utf8.DecodeLastRuneInString(s[:i])
+	return unicode.IsSpace(r)
+}
+
+func isWordRuneAt(s string, i int) bool {
+	if i >= len(s) {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isWordRuneBefore(s string, i int) bool {
+	if i == 0 {
+		return false
+	}
+	r, _ := utf8.DecodeLastRuneInString(s[:i])
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// parseMarkdownCodeSpan parses a code span at the start of s and returns its
+// code and length. If there is none, n is the length of the opening backticks.
+func parseMarkdownCodeSpan(s string) (code string, n int, ok bool) {
+	for n < len(s) && s[n] == '`' {
+		n++
+	}
+	fence := s[:n]
+
+	for i := n; i < len(s); {
+		j := strings.Index(s[i:], fence)
+		if j < 0 {
+			break
+		}
+		end := i + j
+		if end+n < len(s) && s[end+n] == '`' {
+			// A longer run of backticks belongs to the code.
+			for end < len(s) && s[end] == '`' {
+				end++
+			}
+			i = end
+			continue
+		}
+
+		code = s[n:end]
+		if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+			code = code[1 : len(code)-1]
+		}
+		return code, end + n, true
+	}
+
+	return "", n, false
+}
+
+// parseMarkdownLink parses a [label](url) link at the start of s.
+func parseMarkdownLink(s string) (label, url string, n int, ok bool) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if i+1 >= len(s) || s[i+1] != '(' {
+				return "", "", 0, false
+			}
+			end := strings.IndexByte(s[i+2:], ')')
+			if end < 0 {
+				return "", "", 0, false
+			}
+			return s[1:i], s[i+2 : i+2+end], i + 3 + end, true
+		}
+	}
+
+	return "", "", 0, false
+}
+
+func appendCommentCodeLine(blocks []CommentInComment, line, language string) []CommentInComment {
+	if line != "" {
+		blocks = append(blocks, CommentInComment{Text: line})
+	}
+
+	return append(blocks, CommentInComment{Text: "\n", Attributes: &CommentAttributes{CodeBlock: &CommentCodeBlock{Language: language}}})
+}
+
+// appendCommentBlock appends b, merging it into the last block if both are
+// text with the same formatting. Empty attributes are dropped.
+func appendCommentBlock(blocks []CommentInComment, b CommentInComment) []CommentInComment {
+	if b.Attributes != nil && *b.Attributes == (CommentAttributes{}) {
+		b.Attributes = nil
+	}
+	if b.Type == "" && b.Text == "" {
+		return blocks
+	}
+
+	if n := len(blocks); n > 0 && b.Type == "" && blocks[n-1].Type == "" && mergeableAttributes(blocks[n-1].Attributes, b.Attributes) {
+		blocks[n-1].Text += b.Text
+		return blocks
+	}
+
+	return append(blocks, b)
+}
+
+func mergeableAttributes(a, b *CommentAttributes) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.CodeBlock == nil && b.CodeBlock == nil && *a == *b
+}
@@ -0,0 +1,168 @@
+package clickup
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCommentBuilder(t *testing.T) {
+	blocks := NewCommentBuilder().
+		Text("Deploy is ").Bold("blocked").Text(" by ").MentionTask("9hz").
+		Text(", ").MentionUser(183).Text(" see ").Link("logs", "https://example.com/logs").
+		Text(" ").Emoji("1f440").
+		CodeBlock("make deploy\nmake verify", "sh").
+		Text("Run ").Code("make").Text(" again.").
+		Blocks()
+
+	got, err := json.Marshal(blocks)
+	if err != nil {
+		t.Fatalf("json.Marshal returned error: %v", err)
+	}
+
+	want := `[{"text":"Deploy is "},{"text":"blocked","attributes":{"bold":true}},{"text":" by "},` +
+		`{"type":"task_mention","task_mention":{"task_id":"9hz"}},{"text":", "},{"type":"tag","user":{"id":183}},` +
+		`{"text":" see "},{"text":"logs","attributes":{"link":"https://example.com/logs"}},{"text":" "},` +
+		`{"type":"emoticon","emoticon":{"code":"1f440"}},{"text":"\n"},` +
+		`{"text":"make deploy"},{"text":"\n","attributes":{"code-block":{"code-block":"sh"}}},` +
+		`{"text":"make verify"},{"text":"\n","attributes":{"code-block":{"code-block":"sh"}}},` +
+		`{"text":"Run "},{"text":"make","attributes":{"code":true}},{"text":" again."}]`
+	if string(got) != want {
+		t.Errorf("CommentBuilder blocks = %s, want %s", got, want)
+	}
+}
+
+func TestCommentBuilder_Request(t *testing.T) {
+	req := NewCommentBuilder().Text("Thanks ").MentionUser(183).Text(" ").Emoji("1f44d").Request()
+
+	if want := "Thanks @183 \U0001f44d"; req.CommentText != want {
+		t.Errorf("CommentText = %q, want %q", req.CommentText, want)
+	}
+	if len(req.Comment) != 4 {
+		t.Errorf("Comment has %d blocks, want 4", len(req.Comment))
+	}
+}
+
+func TestCommentToMarkdown(t *testing.T) {
+	blocks := NewCommentBuilder().
+		Text("Deploy is ").Bold("blocked").Text(" by ").MentionTask("9hz").Text(", ").MentionUser(183).Newline().
+		Styled("very", CommentAttributes{Bold: true, Italic: true}).Text(" ").Strike("old").Text(" ").
+		Styled("docs", CommentAttributes{Code: true, Link: "https://example.com/a b"}).Newline().
+		Text("literal *stars*, snake_case, [brackets], <@1> and :1f44d: ").Emoji("1f44d").
+		CodeBlock("if a < b {\n\treturn\n}", "go").
+		Text("done").
+		Blocks()
+
+	want := "Deploy is **blocked** by <#9hz>, <@183>\n" +
+		"**_very_** ~~old~~ [`docs`](https://example.com/a%20b)\n" +
+		`literal \*stars\*, snake\_case, \[brackets\], \<@1> and \:1f44d: :1f44d:` + "\n" +
+		"```go\nif a < b {\n\treturn\n}\n```\n" +
+		"done"
+
+	got := CommentToMarkdown(blocks)
+	if got != want {
+		t.Errorf("CommentToMarkdown = %q, want %q", got, want)
+	}
+
+	if back := CommentFromMarkdown(got); !cmp.Equal(back, blocks) {
+		t.Errorf("CommentFromMarkdown(CommentToMarkdown(b)) = %+v, want %+v", back, blocks)
+	}
+}
+
+func TestCommentFromMarkdown_roundTrip(t *testing.T) {
+	for _, md := range []string{
+		"",
+		"plain text",
+		"two\nlines\n",
+		"**bold** _italic_ ~~strike~~ `code` [link](https://example.com)",
+		"**bold** **_both_**_italic_",
+		"[**bold link**](https://example.com/x%29y)",
+		"``a`b``",
+		"`` `tick` ``",
+		"ping <@42> about <#abc_1> :1f3f3-fe0f:",
+		"```\nno language\n```",
+		"before\n```go\nfmt.Println()\n```\nafter",
+		"```sh\nmake\n```\n```go\ngo test\n```",
+	} {
+		blocks := CommentFromMarkdown(md)
+		if got := CommentToMarkdown(blocks); got != md {
+			t.Errorf("CommentToMarkdown(CommentFromMarkdown(%q)) = %q", md, got)
+		}
+	}
+
+	// Nesting is normalized, keeping the formatting.
+	md := "**bold _both_**_italic_"
+	blocks := CommentFromMarkdown(md)
+	if got, want := CommentToMarkdown(blocks), "**bold ****_both_**_italic_"; got != want {
+		t.Errorf("CommentToMarkdown(CommentFromMarkdown(%q)) = %q, want %q", md, got, want)
+	}
+	if back := CommentFromMarkdown(CommentToMarkdown(blocks)); !cmp.Equal(back, blocks) {
+		t.Errorf("CommentFromMarkdown(%q) round trip = %+v, want %+v", md, back, blocks)
+	}
+}
+
+func TestCommentFromMarkdown_unmatched(t *testing.T) {
+	got := CommentFromMarkdown("a [b] `c")
+	want := []CommentInComment{{Text: "a [b] `c"}}
+	if !cmp.Equal(got, want) {
+		t.Errorf("CommentFromMarkdown = %+v, want %+v", got, want)
+	}
+}
+
+func TestCommentFromMarkdown_emphasis(t *testing.T) {
+	bold := &CommentAttributes{Bold: true}
+	italic := &CommentAttributes{Italic: true}
+	for _, tt := range []struct {
+		md   string
+		want []CommentInComment
+	}{
+		{"use snake_case in file_name.go", []CommentInComment{{Text: "use snake_case in file_name.go"}}},
+		{"__init__ and a_b_c", []CommentInComment{{Text: "__init__ and a_b_c"}}},
+		{"an **unclosed bold", []CommentInComment{{Text: "an **unclosed bold"}}},
+		{"2 * 3 * 4", []CommentInComment{{Text: "2 * 3 * 4"}}},
+		{"_ not italic _", []CommentInComment{{Text: "_ not italic _"}}},
+		{"*x* and _y_", []CommentInComment{{Text: "x", Attributes: italic}, {Text: " and "}, {Text: "y", Attributes: italic}}},
+		{"un*frigging*believable", []CommentInComment{{Text: "un"}, {Text: "frigging", Attributes: italic}, {Text: "believable"}}},
+		{"**a_b** c", []CommentInComment{{Text: "a_b", Attributes: bold}, {Text: " c"}}},
+		{"*a **b** c*", []CommentInComment{{Text: "a ", Attributes: italic}, {Text: "b", Attributes: &CommentAttributes{Bold: true, Italic: true}}, {Text: " c", Attributes: italic}}},
+	} {
+		got := CommentFromMarkdown(tt.md)
+		for i := range got {
+			if got[i].Attributes != nil && *got[i].Attributes == (CommentAttributes{}) {
+				got[i].Attributes = nil
+			}
+		}
+		if !cmp.Equal(got, tt.want) {
+			t.Errorf("CommentFromMarkdown(%q) = %+v, want %+v", tt.md, got, tt.want)
+		}
+	}
+
+	// Italics within a word are written with *.
+	blocks := NewCommentBuilder().Text("un").Italic("frigging").Text("believable ").Italic("ok").Blocks()
+	md := CommentToMarkdown(blocks)
+	if want := "un*frigging*believable _ok_"; md != want {
+		t.Errorf("CommentToMarkdown = %q, want %q", md, want)
+	}
+	if back := CommentFromMarkdown(md); !cmp.Equal(back, blocks) {
+		t.Errorf("CommentFromMarkdown(%q) = %+v, want %+v", md, back, blocks)
+	}
+}
+
+func TestComment_Markdown(t *testing.T) {
+	var c Comment
+	err := json.Unmarshal([]byte(`{"comment": [
+		{"text": "Hi ", "attributes": {}},
+		{"type": "tag", "text": "@Ann", "user": {"id": 7, "username": "Ann"}},
+		{"text": " see ", "attributes": {}},
+		{"text": "this", "attributes": {"bold": true, "link": "https://example.com"}},
+		{"text": "\n", "attributes": {}}
+	]}`), &c)
+	if err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+
+	if got, want := c.Markdown(), "Hi <@7> see [**this**](https://example.com)\n"; got != want {
+		t.Errorf("Comment.Markdown = %q, want %q", got, want)
+	}
+}
@@ -2,21 +2,46 @@ package clickup
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 )
 
 type CommentsService service
 
+// Comment is rich text and takes precedence over CommentText. See CommentBuilder.
 type CommentRequest struct {
-	CommentText string `json:"comment_text,omitempty"`
-	Assignee    int    `json:"assignee,omitempty"`
-	NotifyAll   bool   `json:"notify_all,omitempty"`
+	CommentText string             `json:"comment_text,omitempty"`
+	Comment     []CommentInComment `json:"comment,omitempty"`
+	Assignee    int                `json:"assignee,omitempty"`
+	NotifyAll   bool               `json:"notify_all,omitempty"`
 }
 
 type UpdateCommentRequest struct {
-	CommentText string `json:"comment_text,omitempty"`
-	Assignee    int    `json:"assignee,omitempty"`
-	Resolved    bool   `json:"resolved,omitempty"`
+	CommentText string             `json:"comment_text,omitempty"`
+	Comment     []CommentInComment `json:"comment,omitempty"`
+	Assignee    int                `json:"assignee,omitempty"`
+	Resolved    bool               `json:"resolved,omitempty"`
+	// Unresolve reopens a resolved comment, as a false Resolved is not sent.
+	Unresolve bool `json:"-"`
+}
+
+func (r UpdateCommentRequest) MarshalJSON() ([]byte, error) {
+	type request UpdateCommentRequest
+	b, err := json.Marshal(request(r))
+	if err != nil || !r.Unresolve {
+		return b, err
+	}
+	if r.Resolved {
+		return nil, fmt.Errorf("clickup: comment update both resolves and unresolves")
+	}
+
+	body := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &body); err != nil {
+		return nil, err
+	}
+	body["resolved"] = json.RawMessage("false")
+
+	return json.Marshal(body)
 }
 
 type CreateCommentResponse struct {
@@ -32,6 +57,15 @@ type GetCommentsResponse struct {
 type TaskCommentOptions struct {
 	CustomTaskIDs string `url:"custom_task_ids,omitempty"`
 	TeamID        int    `url:"team_id,omitempty"`
+	// Start and StartID page back from the comment with that date and ID.
+	Start   int64  `url:"start,omitempty"`
+	StartID string `url:"start_id,omitempty"`
+}
+
+// CommentPageOptions page list and chat view comments, see TaskCommentOptions.
+type CommentPageOptions struct {
+	Start   int64  `url:"start,omitempty"`
+	StartID string `url:"start_id,omitempty"`
 }
 
 type Comment struct {
@@ -44,10 +78,53 @@ type Comment struct {
 	AssignedBy  User               `json:"assigned_by,omitempty"`
 	Reactions   []Reaction         `json:"reactions,omitempty"`
 	Date        string             `json:"date"`
+	ReplyCount  int                `json:"reply_count,omitempty"`
 }
 
+// CommentInComment is a block of rich text. Text blocks carry Text and
+// Attributes; mention and emoticon blocks are identified by Type.
 type CommentInComment struct {
-	Text string `json:"text"`
+	Text        string              `json:"text,omitempty"`
+	Type        string              `json:"type,omitempty"`
+	Attributes  *CommentAttributes  `json:"attributes,omitempty"`
+	User        *CommentUser        `json:"user,omitempty"`
+	TaskMention *CommentTaskMention `json:"task_mention,omitempty"`
+	Emoticon    *CommentEmoticon    `json:"emoticon,omitempty"`
+}
+
+const (
+	CommentBlockUserMention = "tag"
+	CommentBlockTaskMention = "task_mention"
+	CommentBlockEmoticon    = "emoticon"
+)
+
+// CommentAttributes format a text block. CodeBlock is set on the newline
+// ending each line of a code block.
+type CommentAttributes struct {
+	Bold      bool              `json:"bold,omitempty"`
+	Italic    bool              `json:"italic,omitempty"`
+	Strike    bool              `json:"strike,omitempty"`
+	Code      bool              `json:"code,omitempty"`
+	Link      string            `json:"link,omitempty"`
+	CodeBlock *CommentCodeBlock `json:"code-block,omitempty"`
+}
+
+type CommentCodeBlock struct {
+	Language string `json:"code-block"`
+}
+
+type CommentUser struct {
+	ID       int    `json:"id"`
+	Username string `json:"username,omitempty"`
+}
+
+type CommentTaskMention struct {
+	TaskID string `json:"task_id"`
+}
+
+// CommentEmoticon is an emoji by its hexadecimal code point, such as "1f44d".
+type CommentEmoticon struct {
+	Code string `json:"code"`
 }
 
 type Reaction struct {
@@ -113,46 +190,62 @@ func (s *CommentsService) CreateListComment(ctx context.Context, listID int, com
 }
 
 func (s *CommentsService) GetTaskComments(ctx context.Context, taskID string, opts *TaskCommentOptions) ([]Comment, *Response, error) {
-	u := fmt.Sprintf("task/%v/comment", taskID)
-	req, err := s.client.NewRequest("GET", u, nil)
-	if err != nil {
-		return nil, nil, err
-	}
+	return s.getComments(ctx, fmt.Sprintf("task/%v/comment", taskID), opts)
+}
 
-	gcr := new(GetCommentsResponse)
-	resp, err := s.client.Do(ctx, req, gcr)
-	if err != nil {
-		return nil, resp, err
-	}
+func (s *CommentsService) GetChatViewComments(ctx context.Context, viewID string) ([]Comment, *Response, error) {
+	return s.GetChatViewCommentsPage(ctx, viewID, nil)
+}
 
-	return gcr.Comments, resp, nil
+// GetChatViewCommentsPage returns the page of chat view comments selected by opts.
+func (s *CommentsService) GetChatViewCommentsPage(ctx context.Context, viewID string, opts *CommentPageOptions) ([]Comment, *Response, error) {
+	return s.getComments(ctx, fmt.Sprintf("view/%v/comment", viewID), opts)
 }
 
-func (s *CommentsService) GetChatViewComments(ctx context.Context, viewID string) ([]Comment, *Response, error) {
-	u := fmt.Sprintf("view/%v/comment", viewID)
-	req, err := s.client.NewRequest("GET", u, nil)
+func (s *CommentsService) GetListComments(ctx context.Context, listID int) ([]Comment, *Response, error) {
+	return s.GetListCommentsPage(ctx, listID, nil)
+}
+
+// GetListCommentsPage returns the page of list comments selected by opts.
+func (s *CommentsService) GetListCommentsPage(ctx context.Context, listID int, opts *CommentPageOptions) ([]Comment, *Response, error) {
+	return s.getComments(ctx, fmt.Sprintf("list/%v/comment", listID), opts)
+}
+
+// GetThreadedComments returns the replies to a comment.
+func (s *CommentsService) GetThreadedComments(ctx context.Context, commentID int) ([]Comment, *Response, error) {
+	return s.getComments(ctx, fmt.Sprintf("comment/%v/reply", commentID), nil)
+}
+
+// CreateThreadedComment replies to a comment.
+func (s *CommentsService) CreateThreadedComment(ctx context.Context, commentID int, comment *CommentRequest) (*CreateCommentResponse, *Response, error) {
+	u := fmt.Sprintf("comment/%v/reply", commentID)
+	req, err := s.client.NewRequest("POST", u, comment)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	gcr := new(GetCommentsResponse)
-	resp, err := s.client.Do(ctx, req, gcr)
+	ccr := new(CreateCommentResponse)
+	resp, err := s.client.Do(ctx, req, ccr)
 	if err != nil {
 		return nil, resp, err
 	}
 
-	return gcr.Comments, resp, nil
+	return ccr, resp, nil
 }
 
-func (s *CommentsService) GetListComments(ctx context.Context, listID int) ([]Comment, *Response, error) {
-	u := fmt.Sprintf("list/%v/comment", listID)
+func (s *CommentsService) getComments(ctx context.Context, u string, opts interface{}) ([]Comment, *Response, error) {
+	u, err := addOptions(u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	req, err := s.client.NewRequest("GET", u, nil)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	gcr := new(GetCommentsResponse)
-	resp, err := s.client.Do(ctx, req, &gcr)
+	resp, err := s.client.Do(ctx, req, gcr)
 	if err != nil {
 		return nil, resp, err
 	}
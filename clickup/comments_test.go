@@ -159,6 +159,28 @@ func TestCommentsService_UpdateComment(t *testing.T) {
 	}
 }
 
+func TestCommentsService_UpdateComment_unresolve(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/comment/456", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		testBody(t, r, `{"comment_text":"Reopened","resolved":false}`+"\n")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ctx := context.Background()
+	_, err := client.Comments.UpdateComment(ctx, 456, &UpdateCommentRequest{CommentText: "Reopened", Unresolve: true})
+	if err != nil {
+		t.Errorf("Comments.UpdateComment returned error: %v", err)
+	}
+
+	_, err = client.Comments.UpdateComment(ctx, 456, &UpdateCommentRequest{Resolved: true, Unresolve: true})
+	if err == nil {
+		t.Error("Comments.UpdateComment returned no error for a request resolving and unresolving")
+	}
+}
+
 func TestCommentsService_DeleteComment(t *testing.T) {
 	client, mux, _, teardown := setup()
 	defer teardown()
@@ -174,3 +196,53 @@ func TestCommentsService_DeleteComment(t *testing.T) {
 		t.Errorf("Actions.ListArtifacts returned error: %v", err)
 	}
 }
+
+func TestCommentsService_GetThreadedComments(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/comment/458/reply", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"comments": [{"id": 459, "comment_text": "reply", "date": "1568036964080"}]}`)
+	})
+
+	ctx := context.Background()
+	replies, _, err := client.Comments.GetThreadedComments(ctx, 458)
+	if err != nil {
+		t.Errorf("Comments.GetThreadedComments returned error: %v", err)
+	}
+
+	want := []Comment{{ID: 459, CommentText: "reply", Date: "1568036964080"}}
+	if !cmp.Equal(replies, want) {
+		t.Errorf("Comments.GetThreadedComments returned %+v, want %+v", replies, want)
+	}
+}
+
+func TestCommentsService_CreateThreadedComment(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	input := &CommentRequest{CommentText: "reply"}
+
+	mux.HandleFunc("/comment/458/reply", func(w http.ResponseWriter, r *http.Request) {
+		v := new(CommentRequest)
+		json.NewDecoder(r.Body).Decode(v)
+
+		testMethod(t, r, "POST")
+		if !cmp.Equal(v, input) {
+			t.Errorf("Request body = %+v, want %+v", v, input)
+		}
+		fmt.Fprint(w, `{"id": 459, "hist_id": "26509", "date": 1568036964080}`)
+	})
+
+	ctx := context.Background()
+	got, _, err := client.Comments.CreateThreadedComment(ctx, 458, input)
+	if err != nil {
+		t.Errorf("Comments.CreateThreadedComment returned error: %v", err)
+	}
+
+	want := &CreateCommentResponse{ID: 459, HistId: "26509", Date: NewDateWithUnixTime(1568036964080)}
+	if !cmp.Equal(got, want) {
+		t.Errorf("Comments.CreateThreadedComment returned %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,384 @@
+package clickup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CustomFieldNameCollisionError is returned when a name matches more than one
+// custom field accessible from a list.
+type CustomFieldNameCollisionError struct {
+	ListID string
+	Name   string
+	IDs    []string
+}
+
+func (e *CustomFieldNameCollisionError) Error() string {
+	return fmt.Sprintf("custom field name %q is ambiguous in list %s: matches fields %s",
+		e.Name, e.ListID, strings.Join(e.IDs, ", "))
+}
+
+// CustomFieldRegistry resolves custom fields by name. Field definitions are
+// fetched with GetAccessibleCustomFields and cached per list.
+type CustomFieldRegistry struct {
+	client *Client
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	lists map[string]customFieldSchema
+}
+
+type customFieldSchema struct {
+	fields    []CustomField
+	fetchedAt time.Time
+}
+
+// NewCustomFieldRegistry returns a registry caching the fields of each list
+// for ttl. A ttl of zero or less caches them until Invalidate is called.
+func NewCustomFieldRegistry(client *Client, ttl time.Duration) *CustomFieldRegistry {
+	return &CustomFieldRegistry{
+		client: client,
+		ttl:    ttl,
+		now:    time.Now,
+		lists:  map[string]customFieldSchema{},
+	}
+}
+
+// Fields returns the custom fields accessible from a list. The slice is a
+// copy, so changing it does not change the cache.
+func (r *CustomFieldRegistry) Fields(ctx context.Context, listID string) ([]CustomField, error) {
+	r.mu.Lock()
+	schema, ok := r.lists[listID]
+	r.mu.Unlock()
+	if ok && (r.ttl <= 0 || r.now().Sub(schema.fetchedAt) < r.ttl) {
+		return append([]CustomField(nil), schema.fields...), nil
+	}
+
+	fields, _, err := r.client.CustomFields.GetAccessibleCustomFields(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.lists[listID] = customFieldSchema{fields: fields, fetchedAt: r.now()}
+	r.mu.Unlock()
+
+	return append([]CustomField(nil), fields...), nil
+}
+
+// Invalidate drops the cached fields of a list, or of every list if listID is empty.
+func (r *CustomFieldRegistry) Invalidate(listID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if listID == "" {
+		r.lists = map[string]customFieldSchema{}
+		return
+	}
+	delete(r.lists, listID)
+}
+
+// Field returns the custom field of a list with the given name. An exact match
+// is preferred over a case-insensitive one. If several fields match equally,
+// a *CustomFieldNameCollisionError is returned.
+func (r *CustomFieldRegistry) Field(ctx context.Context, listID, name string) (*CustomField, error) {
+	fields, err := r.Fields(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, equal := range []func(a, b string) bool{
+		func(a, b string) bool { return a == b },
+		strings.EqualFold,
+	} {
+		var found []*CustomField
+		for i := range fields {
+			if equal(fields[i].Name, name) {
+				found = append(found, &fields[i])
+			}
+		}
+
+		switch len(found) {
+		case 0:
+			continue
+		case 1:
+			return found[0], nil
+		}
+
+		e := &CustomFieldNameCollisionError{ListID: listID, Name: name}
+		for _, f := range found {
+			e.IDs = append(e.IDs, f.ID)
+		}
+		return nil, e
+	}
+
+	return nil, fmt.Errorf("list %s has no custom field named %q", listID, name)
+}
+
+// FieldID returns the ID of the custom field of a list with the given name.
+func (r *CustomFieldRegistry) FieldID(ctx context.Context, listID, name string) (string, error) {
+	field, err := r.Field(ctx, listID, name)
+	if err != nil {
+		return "", err
+	}
+
+	return field.ID, nil
+}
+
+// OptionID returns the ID of the option named optionName of a drop_down or
+// labels field of a list.
+func (r *CustomFieldRegistry) OptionID(ctx context.Context, listID, fieldName, optionName string) (string, error) {
+	field, err := r.Field(ctx, listID, fieldName)
+	if err != nil {
+		return "", err
+	}
+
+	var value CustomFieldValue
+	switch field.Type {
+	case "drop_down":
+		value = DropDownFieldValue{OptionName: optionName}
+	case "labels":
+		value = LabelsFieldValue{OptionNames: []string{optionName}}
+	default:
+		return "", fmt.Errorf("custom field %q of type %q has no options", field.Name, field.Type)
+	}
+
+	body, err := CustomFieldValueBody(field, value)
+	if err != nil {
+		return "", err
+	}
+
+	switch v := body["value"].(type) {
+	case string:
+		return v, nil
+	case []string:
+		return v[0], nil
+	}
+
+	return "", fmt.Errorf("custom field %q returned no option for %q", field.Name, optionName)
+}
+
+// Validate checks that value can be set on the named field of a list and
+// returns the field.
+func (r *CustomFieldRegistry) Validate(ctx context.Context, listID, fieldName string, value CustomFieldValue) (*CustomField, error) {
+	field, err := r.Field(ctx, listID, fieldName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateCustomFieldValue(field, value); err != nil {
+		return nil, err
+	}
+
+	return field, nil
+}
+
+func validateCustomFieldValue(field *CustomField, value CustomFieldValue) error {
+	if _, err := CustomFieldValueBody(field, value); err != nil {
+		return err
+	}
+
+	return validateOptionIDs(field, value)
+}
+
+func validateOptionIDs(field *CustomField, value CustomFieldValue) error {
+	var ids []string
+	known := map[string]bool{}
+
+	switch v := value.(type) {
+	case DropDownFieldValue:
+		if v.OptionID == "" {
+			return nil
+		}
+		ids = []string{v.OptionID}
+		tc := DropDownTypeConfig{}
+		getStructValue(field.TypeConfig, &tc)
+		for _, o := range tc.Options {
+			known[o.ID] = true
+		}
+	case LabelsFieldValue:
+		ids = v.OptionIDs
+		tc := LabelsTypeConfig{}
+		getStructValue(field.TypeConfig, &tc)
+		for _, o := range tc.Options {
+			known[o.ID] = true
+		}
+	}
+
+	for _, id := range ids {
+		if !known[id] {
+			return fmt.Errorf("custom field %q has no option with ID %q", field.Name, id)
+		}
+	}
+
+	return nil
+}
+
+// SetValue validates value and sets it on the named field of a task in a list.
+func (r *CustomFieldRegistry) SetValue(ctx context.Context, listID, taskID, fieldName string, value CustomFieldValue, opts *CustomFieldOptions) (*Response, error) {
+	field, err := r.Validate(ctx, listID, fieldName, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.client.CustomFields.SetCustomFieldTypedValue(ctx, taskID, field, value, opts)
+}
+
+// TaskRequestFields validates values keyed by field name and returns them as
+// the custom fields of a TaskRequest or TaskUpdateRequest for a list, in
+// sorted name order.
+func (r *CustomFieldRegistry) TaskRequestFields(ctx context.Context, listID string, values map[string]CustomFieldValue) ([]CustomFieldInTaskRequest, error) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var fields []CustomFieldInTaskRequest
+	for _, name := range names {
+		field, err := r.Validate(ctx, listID, name, values[name])
+		if err != nil {
+			return nil, err
+		}
+
+		f, err := NewCustomFieldInTaskRequest(field, values[name])
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+// CreateTask creates a task in a list after checking that every custom field
+// of tr is accessible from the list and that its value fits the field's type
+// and type config. A nil value is not checked.
+func (r *CustomFieldRegistry) CreateTask(ctx context.Context, listID string, tr *TaskRequest) (*Task, *Response, error) {
+	fields, err := r.Fields(ctx, listID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byID := map[string]*CustomField{}
+	for i := range fields {
+		byID[fields[i].ID] = &fields[i]
+	}
+	for _, f := range tr.CustomFields {
+		field := byID[f.ID]
+		if field == nil {
+			return nil, nil, fmt.Errorf("custom field %q is not accessible from list %s", f.ID, listID)
+		}
+		if f.Value == nil {
+			continue
+		}
+
+		value, err := requestCustomFieldValue(field, f.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := validateCustomFieldValue(field, value); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return r.client.Tasks.CreateTask(ctx, listID, tr)
+}
+
+// requestCustomFieldValue decodes the value of a custom field in a task
+// request, as NewCustomFieldInTaskRequest builds it or as decoded from JSON,
+// into the typed value for the field's type.
+func requestCustomFieldValue(field *CustomField, v interface{}) (CustomFieldValue, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decode := func(dst interface{}) error { return json.Unmarshal(b, dst) }
+
+	var value CustomFieldValue
+	switch field.Type {
+	case "url", "email", "phone", "text", "short_text":
+		var s string
+		err = decode(&s)
+		value = TextFieldValue(s)
+	case "number":
+		var n float64
+		err = decode(&n)
+		value = NumberFieldValue(n)
+	case "currency":
+		var n float64
+		err = decode(&n)
+		value = CurrencyFieldValue(n)
+	case "emoji":
+		var n int
+		err = decode(&n)
+		value = EmojiFieldValue(n)
+	case "rating":
+		var n int
+		err = decode(&n)
+		value = RatingFieldValue(n)
+	case "date":
+		var ms int64
+		err = decode(&ms)
+		value = DateFieldValue{Time: time.UnixMilli(ms)}
+	case "checkbox":
+		var c bool
+		err = decode(&c)
+		value = CheckboxFieldValue(c)
+	case "location":
+		var l struct {
+			Location struct {
+				Lat float64 `json:"lat"`
+				Lng float64 `json:"lng"`
+			} `json:"location"`
+			FormattedAddress string `json:"formatted_address"`
+		}
+		err = decode(&l)
+		value = LocationFieldValue{Latitude: l.Location.Lat, Longitude: l.Location.Lng, FormattedAddress: l.FormattedAddress}
+	case "manual_progress":
+		var p ManualProgressFieldValue
+		err = decode(&p)
+		value = p
+	case "tasks", "list_relationship":
+		var ids struct{ Add, Rem []string }
+		err = decode(&ids)
+		if field.Type == "tasks" {
+			value = TasksFieldValue(ids)
+		} else {
+			value = ListRelationshipFieldValue(ids)
+		}
+	case "users", "votes":
+		var ids struct{ Add, Rem []int }
+		err = decode(&ids)
+		if field.Type == "users" {
+			value = UsersFieldValue(ids)
+		} else {
+			value = VotesFieldValue(ids)
+		}
+	case "attachment":
+		var ids []string
+		err = decode(&ids)
+		value = AttachmentsFieldValue(ids)
+	case "drop_down":
+		var id string
+		err = decode(&id)
+		value = DropDownFieldValue{OptionID: id}
+	case "labels":
+		var ids []string
+		err = decode(&ids)
+		value = LabelsFieldValue{OptionIDs: ids}
+	default:
+		return nil, fmt.Errorf("custom field %q of type %q cannot be set", field.Name, field.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("custom field %q of type %q cannot be set to %s", field.Name, field.Type, b)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,229 @@
+package clickup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const registryFieldsJSON = `{"fields": [
+	{"id": "cf-sev", "name": "Severity", "type": "number"},
+	{"id": "cf-env", "name": "Environment", "type": "drop_down", "type_config": {"options": [
+		{"id": "opt-prod", "name": "production", "orderindex": 0},
+		{"id": "opt-stg", "name": "staging", "orderindex": 1}
+	]}},
+	{"id": "cf-owner-1", "name": "Owner", "type": "users"},
+	{"id": "cf-owner-2", "name": "Owner", "type": "text"},
+	{"id": "cf-case", "name": "customer", "type": "text"},
+	{"id": "cf-case-2", "name": "Customer", "type": "short_text"},
+	{"id": "cf-stars", "name": "Stars", "type": "rating", "type_config": {"count": 5}},
+	{"id": "cf-tags", "name": "Areas", "type": "labels", "type_config": {"options": [{"id": "lbl-ui", "label": "UI"}]}},
+	{"id": "cf-total", "name": "Total", "type": "formula"}
+]}`
+
+func TestCustomFieldRegistry_Field(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	fetches := 0
+	mux.HandleFunc("/list/123/field", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fetches++
+		fmt.Fprint(w, registryFieldsJSON)
+	})
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	reg := NewCustomFieldRegistry(client, time.Minute)
+	reg.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if id, err := reg.FieldID(ctx, "123", "severity"); err != nil || id != "cf-sev" {
+		t.Errorf("FieldID(severity) = %q, %v, want cf-sev", id, err)
+	}
+	if id, err := reg.FieldID(ctx, "123", "Customer"); err != nil || id != "cf-case-2" {
+		t.Errorf("FieldID(Customer) = %q, %v, want exact match cf-case-2", id, err)
+	}
+	if id, err := reg.OptionID(ctx, "123", "Environment", "Staging"); err != nil || id != "opt-stg" {
+		t.Errorf("OptionID(Environment, Staging) = %q, %v, want opt-stg", id, err)
+	}
+
+	var collision *CustomFieldNameCollisionError
+	if _, err := reg.Field(ctx, "123", "owner"); !errors.As(err, &collision) {
+		t.Errorf("Field(owner) returned %v, want a CustomFieldNameCollisionError", err)
+	} else if want := []string{"cf-owner-1", "cf-owner-2"}; !cmp.Equal(collision.IDs, want) {
+		t.Errorf("collision IDs = %v, want %v", collision.IDs, want)
+	}
+	if _, err := reg.Field(ctx, "123", "Missing"); err == nil {
+		t.Error("Field(Missing) returned no error")
+	}
+	if _, err := reg.OptionID(ctx, "123", "Severity", "high"); err == nil {
+		t.Error("OptionID on a number field returned no error")
+	}
+
+	field, _ := reg.Field(ctx, "123", "Severity")
+	field.Name = "changed"
+	fields, _ := reg.Fields(ctx, "123")
+	fields[0].Type = "changed"
+	if field, err := reg.Field(ctx, "123", "Severity"); err != nil || field.Type != "number" {
+		t.Errorf("Field(Severity) after changing returned fields = %+v, %v; the cache was changed", field, err)
+	}
+
+	if fetches != 1 {
+		t.Errorf("fields fetched %d times within the TTL, want 1", fetches)
+	}
+
+	now = now.Add(time.Minute)
+	reg.Fields(ctx, "123")
+	reg.Invalidate("123")
+	reg.Fields(ctx, "123")
+	if fetches != 3 {
+		t.Errorf("fields fetched %d times after expiry and invalidation, want 3", fetches)
+	}
+}
+
+func TestCustomFieldRegistry_Validate(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/list/123/field", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, registryFieldsJSON)
+	})
+
+	reg := NewCustomFieldRegistry(client, 0)
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		field string
+		value CustomFieldValue
+		ok    bool
+	}{
+		{"Severity", NumberFieldValue(3), true},
+		{"Severity", TextFieldValue("3"), false},
+		{"Environment", DropDownFieldValue{OptionID: "opt-prod"}, true},
+		{"Environment", DropDownFieldValue{OptionID: "opt-dev"}, false},
+		{"Environment", DropDownFieldValue{OptionName: "dev"}, false},
+	} {
+		_, err := reg.Validate(ctx, "123", tt.field, tt.value)
+		if got := err == nil; got != tt.ok {
+			t.Errorf("Validate(%s, %#v) returned %v, want ok = %v", tt.field, tt.value, err, tt.ok)
+		}
+	}
+}
+
+func TestCustomFieldRegistry_SetValue(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/list/123/field", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, registryFieldsJSON)
+	})
+	mux.HandleFunc("/task/9hz/field/cf-env", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if want := map[string]interface{}{"value": "opt-prod"}; !cmp.Equal(body, want) {
+			t.Errorf("Request body = %+v, want %+v", body, want)
+		}
+	})
+
+	reg := NewCustomFieldRegistry(client, time.Minute)
+	_, err := reg.SetValue(context.Background(), "123", "9hz", "Environment", DropDownFieldValue{OptionName: "production"}, nil)
+	if err != nil {
+		t.Errorf("SetValue returned error: %v", err)
+	}
+}
+
+func TestCustomFieldRegistry_CreateTask(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/list/123/field", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, registryFieldsJSON)
+	})
+	mux.HandleFunc("/list/123/task", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		var tr TaskRequest
+		json.NewDecoder(r.Body).Decode(&tr)
+		want := []CustomFieldInTaskRequest{
+			{ID: "cf-env", Value: "opt-stg"},
+			{ID: "cf-sev", Value: float64(2)},
+		}
+		if !cmp.Equal(tr.CustomFields, want) {
+			t.Errorf("CustomFields = %+v, want %+v", tr.CustomFields, want)
+		}
+		fmt.Fprint(w, `{"id": "new"}`)
+	})
+
+	reg := NewCustomFieldRegistry(client, time.Minute)
+	ctx := context.Background()
+
+	fields, err := reg.TaskRequestFields(ctx, "123", map[string]CustomFieldValue{
+		"Severity":    NumberFieldValue(2),
+		"Environment": DropDownFieldValue{OptionName: "staging"},
+	})
+	if err != nil {
+		t.Fatalf("TaskRequestFields returned error: %v", err)
+	}
+
+	task, _, err := reg.CreateTask(ctx, "123", &TaskRequest{Name: "new", CustomFields: fields})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	if task.ID != "new" {
+		t.Errorf("CreateTask returned task %q, want new", task.ID)
+	}
+
+	_, _, err = reg.CreateTask(ctx, "123", &TaskRequest{CustomFields: []CustomFieldInTaskRequest{{ID: "cf-other"}}})
+	if err == nil {
+		t.Error("CreateTask with a foreign custom field returned no error")
+	}
+
+	for _, f := range []CustomFieldInTaskRequest{
+		{ID: "cf-sev", Value: "two"},
+		{ID: "cf-env", Value: "opt-dev"},
+		{ID: "cf-stars", Value: 9},
+		{ID: "cf-stars", Value: 2.5},
+		{ID: "cf-tags", Value: []string{"lbl-ui", "lbl-api"}},
+		{ID: "cf-owner-1", Value: []int{1}},
+		{ID: "cf-total", Value: 3},
+	} {
+		_, _, err := reg.CreateTask(ctx, "123", &TaskRequest{CustomFields: []CustomFieldInTaskRequest{f}})
+		if err == nil {
+			t.Errorf("CreateTask with %s = %v returned no error", f.ID, f.Value)
+		}
+	}
+}
+
+func TestCustomFieldRegistry_CreateTaskValues(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/list/123/field", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, registryFieldsJSON)
+	})
+	created := 0
+	mux.HandleFunc("/list/123/task", func(w http.ResponseWriter, r *http.Request) {
+		created++
+		fmt.Fprint(w, `{"id": "new"}`)
+	})
+
+	reg := NewCustomFieldRegistry(client, 0)
+	fields := []CustomFieldInTaskRequest{
+		{ID: "cf-stars", Value: float64(3)},
+		{ID: "cf-tags", Value: []interface{}{"lbl-ui"}},
+		{ID: "cf-owner-1", Value: map[string]interface{}{"add": []int{183}, "rem": []int{}}},
+		{ID: "cf-case", Value: nil},
+	}
+	if _, _, err := reg.CreateTask(context.Background(), "123", &TaskRequest{CustomFields: fields}); err != nil {
+		t.Errorf("CreateTask returned error: %v", err)
+	}
+	if created != 1 {
+		t.Errorf("created %d tasks, want 1", created)
+	}
+}
@@ -0,0 +1,324 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CustomFieldValue is a value that can be set on a custom field. It is
+// implemented by the *FieldValue types in this package, one per field type
+// decoded by CustomField.GetValue. Values of automatic_progress, formula,
+// button, signature and time_tracked fields are computed or captured by
+// ClickUp and cannot be set.
+type CustomFieldValue interface {
+	// fieldTypes are the CustomField types the value can be set on.
+	fieldTypes() []string
+	// body is the request body setting the value on field.
+	body(field *CustomField) (map[string]interface{}, error)
+}
+
+// TextFieldValue sets url, email, phone, text and short_text fields.
+type TextFieldValue string
+
+func (TextFieldValue) fieldTypes() []string {
+	return []string{"url", "email", "phone", "text", "short_text"}
+}
+
+func (v TextFieldValue) body(*CustomField) (map[string]interface{}, error) {
+	return map[string]interface{}{"value": string(v)}, nil
+}
+
+// NumberFieldValue sets number fields.
+type NumberFieldValue float64
+
+func (NumberFieldValue) fieldTypes() []string { return []string{"number"} }
+
+func (v NumberFieldValue) body(*CustomField) (map[string]interface{}, error) {
+	return map[string]interface{}{"value": float64(v)}, nil
+}
+
+// CurrencyFieldValue sets currency fields, in the currency of the field.
+type CurrencyFieldValue float64
+
+func (CurrencyFieldValue) fieldTypes() []string { return []string{"currency"} }
+
+func (v CurrencyFieldValue) body(*CustomField) (map[string]interface{}, error) {
+	return map[string]interface{}{"value": float64(v)}, nil
+}
+
+// EmojiFieldValue sets emoji (rating) fields to a number of emojis.
+type EmojiFieldValue int
+
+func (EmojiFieldValue) fieldTypes() []string { return []string{"emoji"} }
+
+func (v EmojiFieldValue) body(field *CustomField) (map[string]interface{}, error) {
+	if v < 0 {
+		return nil, fmt.Errorf("emoji value %d is negative", v)
+	}
+
+	tc := EmojiTypeConfig{}
+	if getStructValue(field.TypeConfig, &tc) && tc.Count > 0 && int(v) > tc.Count {
+		return nil, fmt.Errorf("emoji value %d exceeds the field's count of %d", v, tc.Count)
+	}
+
+	return map[string]interface{}{"value": int(v)}, nil
+}
+
+// RatingFieldValue sets rating fields to a number of stars or emojis.
+type RatingFieldValue int
+
+func (RatingFieldValue) fieldTypes() []string { return []string{"rating"} }
+
+func (v RatingFieldValue) body(field *CustomField) (map[string]interface{}, error) {
+	if v < 0 {
+		return nil, fmt.Errorf("rating %d is negative", v)
+	}
+
+	tc := RatingTypeConfig{}
+	if getStructValue(field.TypeConfig, &tc) && tc.Count > 0 && int(v) > tc.Count {
+		return nil, fmt.Errorf("rating %d exceeds the field's count of %d", v, tc.Count)
+	}
+
+	return map[string]interface{}{"value": int(v)}, nil
+}
+
+// DateFieldValue sets date fields. IncludeTime shows the time of day in ClickUp.
+type DateFieldValue struct {
+	Time        time.Time
+	IncludeTime bool
+}
+
+func (DateFieldValue) fieldTypes() []string { return []string{"date"} }
+
+func (v DateFieldValue) body(*CustomField) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"value":         v.Time.UnixMilli(),
+		"value_options": map[string]interface{}{"time": v.IncludeTime},
+	}, nil
+}
+
+// CheckboxFieldValue sets checkbox fields.
+type CheckboxFieldValue bool
+
+func (CheckboxFieldValue) fieldTypes() []string { return []string{"checkbox"} }
+
+func (v CheckboxFieldValue) body(*CustomField) (map[string]interface{}, error) {
+	return map[string]interface{}{"value": bool(v)}, nil
+}
+
+// LocationFieldValue sets location fields.
+type LocationFieldValue struct {
+	Latitude         float64
+	Longitude        float64
+	FormattedAddress string
+}
+
+func (LocationFieldValue) fieldTypes() []string { return []string{"location"} }
+
+func (v LocationFieldValue) body(*CustomField) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"value": map[string]interface{}{
+			"location": map[string]interface{}{
+				"lat": v.Latitude,
+				"lng": v.Longitude,
+			},
+			"formatted_address": v.FormattedAddress,
+		},
+	}, nil
+}
+
+// ManualProgressFieldValue sets the current value of manual_progress fields.
+// Automatic progress is computed by ClickUp and cannot be set.
+type ManualProgressFieldValue struct {
+	Current int64
+}
+
+func (ManualProgressFieldValue) fieldTypes() []string { return []string{"manual_progress"} }
+
+func (v ManualProgressFieldValue) body(field *CustomField) (map[string]interface{}, error) {
+	tc := ManualProgressTypeConfig{}
+	if getStructValue(field.TypeConfig, &tc) && tc.End > tc.Start &&
+		(v.Current < tc.Start || v.Current > tc.End) {
+		return nil, fmt.Errorf("progress %d is outside the field's range %d-%d", v.Current, tc.Start, tc.End)
+	}
+
+	return map[string]interface{}{
+		"value": map[string]interface{}{"current": v.Current},
+	}, nil
+}
+
+// TasksFieldValue adds and removes task IDs of tasks (relationship) fields.
+type TasksFieldValue struct {
+	Add []string
+	Rem []string
+}
+
+func (TasksFieldValue) fieldTypes() []string { return []string{"tasks"} }
+
+func (v TasksFieldValue) body(*CustomField) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"value": map[string]interface{}{"add": nonNilStrings(v.Add), "rem": nonNilStrings(v.Rem)},
+	}, nil
+}
+
+// UsersFieldValue adds and removes user IDs of users (people) fields.
+type UsersFieldValue struct {
+	Add []int
+	Rem []int
+}
+
+func (UsersFieldValue) fieldTypes() []string { return []string{"users"} }
+
+func (v UsersFieldValue) body(*CustomField) (map[string]interface{}, error) {
+	add, rem := v.Add, v.Rem
+	if add == nil {
+		add = []int{}
+	}
+	if rem == nil {
+		rem = []int{}
+	}
+
+	return map[string]interface{}{
+		"value": map[string]interface{}{"add": add, "rem": rem},
+	}, nil
+}
+
+// ListRelationshipFieldValue adds and removes list IDs of list_relationship fields.
+type ListRelationshipFieldValue struct {
+	Add []string
+	Rem []string
+}
+
+func (ListRelationshipFieldValue) fieldTypes() []string { return []string{"list_relationship"} }
+
+func (v ListRelationshipFieldValue) body(*CustomField) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"value": map[string]interface{}{"add": nonNilStrings(v.Add), "rem": nonNilStrings(v.Rem)},
+	}, nil
+}
+
+// VotesFieldValue adds and removes the votes of users on votes fields.
+type VotesFieldValue struct {
+	Add []int
+	Rem []int
+}
+
+func (VotesFieldValue) fieldTypes() []string { return []string{"votes"} }
+
+func (v VotesFieldValue) body(f *CustomField) (map[string]interface{}, error) {
+	return UsersFieldValue(v).body(f)
+}
+
+// AttachmentsFieldValue sets attachment fields to already uploaded attachment IDs.
+type AttachmentsFieldValue []string
+
+func (AttachmentsFieldValue) fieldTypes() []string { return []string{"attachment"} }
+
+func (v AttachmentsFieldValue) body(*CustomField) (map[string]interface{}, error) {
+	return map[string]interface{}{"value": nonNilStrings(v)}, nil
+}
+
+// DropDownFieldValue selects a drop_down option by its ID or, if OptionID is
+// empty, by its name, case-insensitively.
+type DropDownFieldValue struct {
+	OptionID   string
+	OptionName string
+}
+
+func (DropDownFieldValue) fieldTypes() []string { return []string{"drop_down"} }
+
+func (v DropDownFieldValue) body(field *CustomField) (map[string]interface{}, error) {
+	if v.OptionID != "" {
+		return map[string]interface{}{"value": v.OptionID}, nil
+	}
+
+	tc := DropDownTypeConfig{}
+	if !getStructValue(field.TypeConfig, &tc) {
+		return nil, fmt.Errorf("custom field %q has an invalid drop-down type config", field.Name)
+	}
+
+	for _, o := range tc.Options {
+		if strings.EqualFold(o.Name, v.OptionName) {
+			return map[string]interface{}{"value": o.ID}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("custom field %q has no drop-down option %q", field.Name, v.OptionName)
+}
+
+// LabelsFieldValue sets labels fields to the given option IDs followed by the
+// options with the given names, compared case-insensitively.
+type LabelsFieldValue struct {
+	OptionIDs   []string
+	OptionNames []string
+}
+
+func (LabelsFieldValue) fieldTypes() []string { return []string{"labels"} }
+
+func (v LabelsFieldValue) body(field *CustomField) (map[string]interface{}, error) {
+	ids := append([]string{}, v.OptionIDs...)
+	if len(v.OptionNames) == 0 {
+		return map[string]interface{}{"value": ids}, nil
+	}
+
+	tc := LabelsTypeConfig{}
+	if !getStructValue(field.TypeConfig, &tc) {
+		return nil, fmt.Errorf("custom field %q has an invalid labels type config", field.Name)
+	}
+
+next:
+	for _, name := range v.OptionNames {
+		for _, o := range tc.Options {
+			if strings.EqualFold(o.Label, name) {
+				ids = append(ids, o.ID)
+				continue next
+			}
+		}
+		return nil, fmt.Errorf("custom field %q has no label option %q", field.Name, name)
+	}
+
+	return map[string]interface{}{"value": ids}, nil
+}
+
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+
+	return s
+}
+
+// CustomFieldValueBody returns the SetCustomFieldValue request body setting
+// value on field. It fails if value does not fit the type of field.
+func CustomFieldValueBody(field *CustomField, value CustomFieldValue) (map[string]interface{}, error) {
+	if !containsString(value.fieldTypes(), field.Type) {
+		return nil, fmt.Errorf("custom field %q of type %q cannot be set to a %T", field.Name, field.Type, value)
+	}
+
+	return value.body(field)
+}
+
+// NewCustomFieldInTaskRequest returns the custom field entry of a
+// TaskRequest or TaskUpdateRequest setting value on field.
+func NewCustomFieldInTaskRequest(field *CustomField, value CustomFieldValue) (CustomFieldInTaskRequest, error) {
+	b, err := CustomFieldValueBody(field, value)
+	if err != nil {
+		return CustomFieldInTaskRequest{}, err
+	}
+
+	return CustomFieldInTaskRequest{ID: field.ID, Value: b["value"], ValueOptions: b["value_options"]}, nil
+}
+
+// SetCustomFieldTypedValue sets value on field of a task. The field definition,
+// as returned by GetAccessibleCustomFields or on a task, is used to check the
+// value type and to resolve drop-down and label option names.
+func (s *CustomFieldsService) SetCustomFieldTypedValue(ctx context.Context, taskID string, field *CustomField, value CustomFieldValue, opts *CustomFieldOptions) (*Response, error) {
+	b, err := CustomFieldValueBody(field, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.SetCustomFieldValue(ctx, taskID, field.ID, b, opts)
+}
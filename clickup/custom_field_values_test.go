@@ -0,0 +1,153 @@
+package clickup
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var (
+	dropDownField = CustomField{
+		ID:   "cf-dd",
+		Name: "Environment",
+		Type: "drop_down",
+		TypeConfig: map[string]interface{}{
+			"options": []interface{}{
+				map[string]interface{}{"id": "opt-prod", "name": "Production", "orderindex": float64(0)},
+				map[string]interface{}{"id": "opt-stg", "name": "Staging", "orderindex": float64(1)},
+			},
+		},
+	}
+	labelsField = CustomField{
+		ID:   "cf-labels",
+		Name: "Platforms",
+		Type: "labels",
+		TypeConfig: map[string]interface{}{
+			"options": []interface{}{
+				map[string]interface{}{"id": "lbl-ios", "label": "iOS"},
+				map[string]interface{}{"id": "lbl-web", "label": "Web"},
+			},
+		},
+	}
+)
+
+func TestCustomFieldValueBody(t *testing.T) {
+	when := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		field CustomField
+		value CustomFieldValue
+		want  string
+	}{
+		{CustomField{Type: "url"}, TextFieldValue("https://example.com"), `{"value":"https://example.com"}`},
+		{CustomField{Type: "number"}, NumberFieldValue(2.5), `{"value":2.5}`},
+		{CustomField{Type: "currency"}, CurrencyFieldValue(10), `{"value":10}`},
+		{CustomField{Type: "emoji", TypeConfig: map[string]interface{}{"count": 5}}, EmojiFieldValue(4), `{"value":4}`},
+		{CustomField{Type: "date"}, DateFieldValue{Time: when, IncludeTime: true}, `{"value":1714564800000,"value_options":{"time":true}}`},
+		{CustomField{Type: "checkbox"}, CheckboxFieldValue(true), `{"value":true}`},
+		{
+			CustomField{Type: "location"},
+			LocationFieldValue{Latitude: 35.6, Longitude: 139.7, FormattedAddress: "Tokyo"},
+			`{"value":{"formatted_address":"Tokyo","location":{"lat":35.6,"lng":139.7}}}`,
+		},
+		{CustomField{Type: "manual_progress"}, ManualProgressFieldValue{Current: 20}, `{"value":{"current":20}}`},
+		{CustomField{Type: "tasks"}, TasksFieldValue{Add: []string{"a1"}}, `{"value":{"add":["a1"],"rem":[]}}`},
+		{CustomField{Type: "users"}, UsersFieldValue{Add: []int{1}, Rem: []int{2}}, `{"value":{"add":[1],"rem":[2]}}`},
+		{CustomField{Type: "rating", TypeConfig: map[string]interface{}{"count": 5}}, RatingFieldValue(5), `{"value":5}`},
+		{CustomField{Type: "list_relationship"}, ListRelationshipFieldValue{Rem: []string{"901"}}, `{"value":{"add":[],"rem":["901"]}}`},
+		{CustomField{Type: "votes"}, VotesFieldValue{Add: []int{7}}, `{"value":{"add":[7],"rem":[]}}`},
+		{CustomField{Type: "attachment"}, AttachmentsFieldValue{"att-1"}, `{"value":["att-1"]}`},
+		{dropDownField, DropDownFieldValue{OptionID: "opt-x"}, `{"value":"opt-x"}`},
+		{dropDownField, DropDownFieldValue{OptionName: "staging"}, `{"value":"opt-stg"}`},
+		{labelsField, LabelsFieldValue{OptionIDs: []string{"lbl-x"}, OptionNames: []string{"web", "iOS"}}, `{"value":["lbl-x","lbl-web","lbl-ios"]}`},
+	}
+
+	for _, tt := range tests {
+		body, err := CustomFieldValueBody(&tt.field, tt.value)
+		if err != nil {
+			t.Errorf("CustomFieldValueBody(%T) returned error: %v", tt.value, err)
+			continue
+		}
+		b, _ := json.Marshal(body)
+		if got := string(b); got != tt.want {
+			t.Errorf("CustomFieldValueBody(%T) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestCustomFieldValueBody_errors(t *testing.T) {
+	tests := []struct {
+		field CustomField
+		value CustomFieldValue
+	}{
+		{CustomField{Type: "number"}, TextFieldValue("1")},
+		{CustomField{Type: "automatic_progress"}, ManualProgressFieldValue{Current: 1}},
+		{CustomField{Type: "formula"}, NumberFieldValue(1)},
+		{CustomField{Type: "rating", TypeConfig: map[string]interface{}{"count": 5}}, RatingFieldValue(6)},
+		{CustomField{Type: "emoji", TypeConfig: map[string]interface{}{"count": 3}}, EmojiFieldValue(4)},
+		{CustomField{Type: "manual_progress", TypeConfig: map[string]interface{}{"start": 0, "end": 10}}, ManualProgressFieldValue{Current: 11}},
+		{dropDownField, DropDownFieldValue{OptionName: "Dev"}},
+		{labelsField, LabelsFieldValue{OptionNames: []string{"Android"}}},
+	}
+
+	for _, tt := range tests {
+		if _, err := CustomFieldValueBody(&tt.field, tt.value); err == nil {
+			t.Errorf("CustomFieldValueBody(%q, %#v) returned no error", tt.field.Type, tt.value)
+		}
+	}
+}
+
+func TestNewCustomFieldInTaskRequest(t *testing.T) {
+	got, err := NewCustomFieldInTaskRequest(&dropDownField, DropDownFieldValue{OptionName: "Production"})
+	if err != nil {
+		t.Fatalf("NewCustomFieldInTaskRequest returned error: %v", err)
+	}
+
+	want := CustomFieldInTaskRequest{ID: "cf-dd", Value: "opt-prod"}
+	if !cmp.Equal(got, want) {
+		t.Errorf("NewCustomFieldInTaskRequest returned %+v, want %+v", got, want)
+	}
+	// Value options, such as whether a date shows its time, are kept.
+	date := CustomField{ID: "cf-date", Type: "date"}
+	got, err = NewCustomFieldInTaskRequest(&date, DateFieldValue{Time: time.UnixMilli(1704067200000), IncludeTime: true})
+	if err != nil {
+		t.Fatalf("NewCustomFieldInTaskRequest returned error: %v", err)
+	}
+	b, err := json.Marshal(TaskRequest{Name: "Release", CustomFields: []CustomFieldInTaskRequest{got}})
+	if err != nil {
+		t.Fatalf("json.Marshal returned error: %v", err)
+	}
+	if want := `"custom_fields":[{"id":"cf-date","value":1704067200000,"value_options":{"time":true}}]`; !strings.Contains(string(b), want) {
+		t.Errorf("TaskRequest body = %s, want it to contain %s", b, want)
+	}
+}
+
+func TestCustomFieldsService_SetCustomFieldTypedValue(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/task/9hz/field/cf-labels", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decoding request body: %v", err)
+		}
+		want := map[string]interface{}{"value": []interface{}{"lbl-ios"}}
+		if !cmp.Equal(body, want) {
+			t.Errorf("Request body = %+v, want %+v", body, want)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ctx := context.Background()
+	_, err := client.CustomFields.SetCustomFieldTypedValue(ctx, "9hz", &labelsField, LabelsFieldValue{OptionNames: []string{"ios"}}, nil)
+	if err != nil {
+		t.Errorf("CustomFields.SetCustomFieldTypedValue returned error: %v", err)
+	}
+}
@@ -169,6 +169,63 @@ type DropDownOption struct {
 	Color      string
 }
 
+type RatingValue struct {
+	Value      int
+	TypeConfig RatingTypeConfig
+}
+
+type RatingTypeConfig struct {
+	CodePoint string `json:"code_point"`
+	Count     int    `json:"count"`
+}
+
+type ListRelationshipItem struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Access  bool   `json:"access"`
+	Deleted bool   `json:"deleted"`
+}
+
+type ListRelationshipValue []ListRelationshipItem
+
+type FormulaTypeConfig struct {
+	// ReturnType is "number", "text" or "date".
+	ReturnType string `json:"return_type"`
+}
+
+// ButtonValue is a button field. Buttons run automations and hold no value.
+type ButtonValue struct {
+	TypeConfig map[string]interface{}
+}
+
+type VotesValue struct {
+	Voters UsersValue
+}
+
+func (v VotesValue) Count() int {
+	return len(v.Voters)
+}
+
+// SignatureValue is the image of a signature, stored as an attachment.
+type SignatureValue AttachmentValue
+
+// TimeTrackedValue is the time tracked on a task, rolled up over its subtasks
+// if the field is configured to.
+type TimeTrackedValue struct {
+	Duration   time.Duration
+	TypeConfig TimeTrackedTypeConfig
+}
+
+type TimeTrackedTypeConfig struct {
+	IncludeSubtasks bool `json:"include_subtasks"`
+}
+
+// UnknownValue is the value of a custom field type this package does not decode.
+type UnknownValue struct {
+	Type string
+	Raw  json.RawMessage
+}
+
 func (cf CustomField) GetValue() interface{} {
 	switch cf.Type {
 	case "url", "email", "phone", "text", "short_text":
@@ -178,13 +235,15 @@ func (cf CustomField) GetValue() interface{} {
 		}
 
 		return str
-	case "number", "formula":
+	case "number":
 		num, ok := getFloatValue(cf.Value)
 		if !ok {
 			return nil
 		}
 
 		return num
+	case "formula":
+		return getFormulaValue(cf.Value, cf.TypeConfig)
 	case "currency":
 		num, ok := getFloatValue(cf.Value)
 		if !ok {
@@ -285,9 +344,103 @@ func (cf CustomField) GetValue() interface{} {
 		}
 
 		return v
+	case "rating":
+		num, ok := getIntValue(cf.Value)
+		if !ok {
+			return nil
+		}
+
+		tc := RatingTypeConfig{}
+		if ok := getStructValue(cf.TypeConfig, &tc); !ok {
+			return nil
+		}
+
+		return RatingValue{
+			Value:      int(num),
+			TypeConfig: tc,
+		}
+	case "list_relationship":
+		v := ListRelationshipValue{}
+		if ok := getStructValue(cf.Value, &v); !ok {
+			return nil
+		}
+
+		return v
+	case "button":
+		tc := map[string]interface{}{}
+		getStructValue(cf.TypeConfig, &tc)
+
+		return ButtonValue{TypeConfig: tc}
+	case "votes":
+		v := UsersValue{}
+		if ok := getStructValue(cf.Value, &v); !ok {
+			return nil
+		}
+
+		return VotesValue{Voters: v}
+	case "signature":
+		v := SignatureValue{}
+		if ok := getStructValue(cf.Value, &v); !ok {
+			return nil
+		}
+
+		return v
+	case "time_tracked":
+		ms, ok := getIntValue(cf.Value)
+		if !ok {
+			return nil
+		}
+
+		tc := TimeTrackedTypeConfig{}
+		getStructValue(cf.TypeConfig, &tc)
+
+		return TimeTrackedValue{
+			Duration:   time.Duration(ms) * time.Millisecond,
+			TypeConfig: tc,
+		}
 	}
 
-	return cf.Value
+	if cf.Value == nil {
+		return nil
+	}
+
+	raw, err := json.Marshal(cf.Value)
+	if err != nil {
+		return nil
+	}
+
+	return UnknownValue{Type: cf.Type, Raw: raw}
+}
+
+// getFormulaValue returns a float64, string or time.Time depending on the
+// return type of the formula. Formulas without a return type are numbers.
+func getFormulaValue(v, typeConfig interface{}) interface{} {
+	tc := FormulaTypeConfig{}
+	getStructValue(typeConfig, &tc)
+
+	switch tc.ReturnType {
+	case "text":
+		str, ok := getStringValue(v)
+		if !ok {
+			return nil
+		}
+
+		return str
+	case "date":
+		date, ok := getDateValue(v)
+		if !ok {
+			return nil
+		}
+
+		return date
+	}
+
+	num, ok := getFloatValue(v)
+	if !ok {
+		return nil
+	}
+
+	return num
 }
 
 func getStringValue(v interface{}) (string, bool) {
@@ -320,6 +473,12 @@ func getFloatValue(v interface{}) (float64, bool) {
 func getIntValue(v interface{}) (int64, bool) {
 	switch v := v.(type) {
 	case int:
+		return int64(v), true
+	case float64:
+		if v != float64(int64(v)) {
+			return 0, false
+		}
+
 		return int64(v), true
 	case string:
 		num, err := strconv.ParseInt(v, 10, 64)
@@ -591,7 +750,25 @@ type CustomFieldOptions struct {
 }
 
 func (s *CustomFieldsService) GetAccessibleCustomFields(ctx context.Context, listID string) ([]CustomField, *Response, error) {
-	u := fmt.Sprintf("list/%s/field", listID)
+	return s.getCustomFields(ctx, fmt.Sprintf("list/%s/field", listID))
+}
+
+// GetFolderCustomFields returns the custom fields created at the folder level.
+func (s *CustomFieldsService) GetFolderCustomFields(ctx context.Context, folderID string) ([]CustomField, *Response, error) {
+	return s.getCustomFields(ctx, fmt.Sprintf("folder/%s/field", folderID))
+}
+
+// GetSpaceCustomFields returns the custom fields created at the space level.
+func (s *CustomFieldsService) GetSpaceCustomFields(ctx context.Context, spaceID string) ([]CustomField, *Response, error) {
+	return s.getCustomFields(ctx, fmt.Sprintf("space/%s/field", spaceID))
+}
+
+// GetWorkspaceCustomFields returns the custom fields created at the workspace level.
+func (s *CustomFieldsService) GetWorkspaceCustomFields(ctx context.Context, teamID string) ([]CustomField, *Response, error) {
+	return s.getCustomFields(ctx, fmt.Sprintf("team/%s/field", teamID))
+}
+
+func (s *CustomFieldsService) getCustomFields(ctx context.Context, u string) ([]CustomField, *Response, error) {
 	req, err := s.client.NewRequest("GET", u, nil)
 	if err != nil {
 		return nil, nil, err
@@ -606,6 +783,82 @@ func (s *CustomFieldsService) GetAccessibleCustomFields(ctx context.Context, lis
 	return cr.Fields, resp, nil
 }
 
+type CustomFieldLevel string
+
+const (
+	CustomFieldLevelList      CustomFieldLevel = "list"
+	CustomFieldLevelFolder    CustomFieldLevel = "folder"
+	CustomFieldLevelSpace     CustomFieldLevel = "space"
+	CustomFieldLevelWorkspace CustomFieldLevel = "workspace"
+)
+
+// InheritedCustomField is a custom field visible to a list, with the level
+// and the ID of the location it was created at.
+type InheritedCustomField struct {
+	CustomField
+	Level    CustomFieldLevel
+	SourceID string
+}
+
+// GetListCustomFieldSources returns every custom field accessible from a list
+// and where it is inherited from. A field is attributed to the highest level
+// that defines it, the workspace being the highest.
+func (s *CustomFieldsService) GetListCustomFieldSources(ctx context.Context, teamID string, listID string) ([]InheritedCustomField, *Response, error) {
+	list, resp, err := s.client.Lists.GetList(ctx, listID)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	fields, resp, err := s.GetAccessibleCustomFields(ctx, listID)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	levels := []struct {
+		level CustomFieldLevel
+		id    string
+		get   func(ctx context.Context, id string) ([]CustomField, *Response, error)
+	}{
+		{CustomFieldLevelWorkspace, teamID, s.GetWorkspaceCustomFields},
+		{CustomFieldLevelSpace, list.Space.ID, s.GetSpaceCustomFields},
+		{CustomFieldLevelFolder, list.Folder.ID, s.GetFolderCustomFields},
+	}
+
+	type source struct {
+		level CustomFieldLevel
+		id    string
+	}
+	sources := map[string]source{}
+	for _, l := range levels {
+		// Folderless lists live in a hidden folder that has no fields of its own.
+		if l.id == "" || (l.level == CustomFieldLevelFolder && list.Folder.Hidden) {
+			continue
+		}
+
+		defined, r, err := l.get(ctx, l.id)
+		resp = r
+		if err != nil {
+			return nil, resp, err
+		}
+		for _, f := range defined {
+			if _, ok := sources[f.ID]; !ok {
+				sources[f.ID] = source{l.level, l.id}
+			}
+		}
+	}
+
+	inherited := make([]InheritedCustomField, len(fields))
+	for i, f := range fields {
+		src, ok := sources[f.ID]
+		if !ok {
+			src = source{CustomFieldLevelList, listID}
+		}
+		inherited[i] = InheritedCustomField{CustomField: f, Level: src.level, SourceID: src.id}
+	}
+
+	return inherited, resp, nil
+}
+
 // The accessible fields can be found on the task object from the get task route. This is where you can retrieve the fieldID.
 // If you set tasks, example is as follow.
 //
@@ -2,9 +2,11 @@ package clickup
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -83,3 +85,134 @@ func TestCustomFieldsService_RemoveCustomFieldValue(t *testing.T) {
 		t.Errorf("Actions.ListArtifacts returned error: %v", err)
 	}
 }
+
+func TestCustomFieldsService_GetLevelCustomFields(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	for _, path := range []string{"/folder/f1/field", "/space/s1/field", "/team/t1/field"} {
+		path := path
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			testMethod(t, r, "GET")
+			fmt.Fprintf(w, `{"fields": [{"id": "%s", "name": "Field", "type": "text"}]}`, path)
+		})
+	}
+
+	ctx := context.Background()
+	for path, get := range map[string]func() ([]CustomField, *Response, error){
+		"/folder/f1/field": func() ([]CustomField, *Response, error) {
+			return client.CustomFields.GetFolderCustomFields(ctx, "f1")
+		},
+		"/space/s1/field": func() ([]CustomField, *Response, error) {
+			return client.CustomFields.GetSpaceCustomFields(ctx, "s1")
+		},
+		"/team/t1/field": func() ([]CustomField, *Response, error) {
+			return client.CustomFields.GetWorkspaceCustomFields(ctx, "t1")
+		},
+	} {
+		fields, _, err := get()
+		if err != nil {
+			t.Errorf("fetching %s returned error: %v", path, err)
+			continue
+		}
+		if len(fields) != 1 || fields[0].ID != path {
+			t.Errorf("fetching %s returned %+v", path, fields)
+		}
+	}
+}
+
+func TestCustomFieldsService_GetListCustomFieldSources(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/list/123", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": "123", "folder": {"id": "f1"}, "space": {"id": "s1"}}`)
+	})
+	mux.HandleFunc("/list/123/field", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"fields": [{"id": "cf-ws"}, {"id": "cf-space"}, {"id": "cf-folder"}, {"id": "cf-list"}]}`)
+	})
+	mux.HandleFunc("/team/t1/field", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"fields": [{"id": "cf-ws"}]}`)
+	})
+	mux.HandleFunc("/space/s1/field", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"fields": [{"id": "cf-space"}, {"id": "cf-ws"}]}`)
+	})
+	mux.HandleFunc("/folder/f1/field", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"fields": [{"id": "cf-folder"}]}`)
+	})
+
+	fields, _, err := client.CustomFields.GetListCustomFieldSources(context.Background(), "t1", "123")
+	if err != nil {
+		t.Fatalf("CustomFields.GetListCustomFieldSources returned error: %v", err)
+	}
+
+	type source struct {
+		ID       string
+		Level    CustomFieldLevel
+		SourceID string
+	}
+	var got []source
+	for _, f := range fields {
+		got = append(got, source{f.ID, f.Level, f.SourceID})
+	}
+	want := []source{
+		{"cf-ws", CustomFieldLevelWorkspace, "t1"},
+		{"cf-space", CustomFieldLevelSpace, "s1"},
+		{"cf-folder", CustomFieldLevelFolder, "f1"},
+		{"cf-list", CustomFieldLevelList, "123"},
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("CustomFields.GetListCustomFieldSources returned %+v, want %+v", got, want)
+	}
+}
+
+func TestCustomField_GetValueRemainingTypes(t *testing.T) {
+	due := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		field CustomField
+		want  interface{}
+	}{
+		{
+			CustomField{Type: "rating", Value: float64(3), TypeConfig: map[string]interface{}{"code_point": "2b50", "count": float64(5)}},
+			RatingValue{Value: 3, TypeConfig: RatingTypeConfig{CodePoint: "2b50", Count: 5}},
+		},
+		{
+			CustomField{Type: "list_relationship", Value: []interface{}{map[string]interface{}{"id": "901", "name": "Backlog", "access": true}}},
+			ListRelationshipValue{{ID: "901", Name: "Backlog", Access: true}},
+		},
+		{CustomField{Type: "formula", Value: "4.5"}, 4.5},
+		{CustomField{Type: "formula", Value: "late", TypeConfig: map[string]interface{}{"return_type": "text"}}, "late"},
+		{
+			CustomField{Type: "formula", Value: fmt.Sprint(due.UnixMilli()), TypeConfig: map[string]interface{}{"return_type": "date"}},
+			time.UnixMilli(due.UnixMilli()),
+		},
+		{
+			CustomField{Type: "button", TypeConfig: map[string]interface{}{"label": "Deploy"}},
+			ButtonValue{TypeConfig: map[string]interface{}{"label": "Deploy"}},
+		},
+		{
+			CustomField{Type: "votes", Value: []interface{}{map[string]interface{}{"id": float64(7), "username": "ann"}}},
+			VotesValue{Voters: UsersValue{{ID: "7", Username: "ann"}}},
+		},
+		{
+			CustomField{Type: "signature", Value: map[string]interface{}{"id": "att-1", "title": "signature.png"}},
+			SignatureValue{ID: "att-1", Title: "signature.png"},
+		},
+		{
+			CustomField{Type: "time_tracked", Value: "5400000", TypeConfig: map[string]interface{}{"include_subtasks": true}},
+			TimeTrackedValue{Duration: 90 * time.Minute, TypeConfig: TimeTrackedTypeConfig{IncludeSubtasks: true}},
+		},
+		{
+			CustomField{Type: "hologram", Value: map[string]interface{}{"depth": float64(3)}},
+			UnknownValue{Type: "hologram", Raw: json.RawMessage(`{"depth":3}`)},
+		},
+		{CustomField{Type: "hologram"}, nil},
+	}
+
+	for _, tt := range tests {
+		if got := tt.field.GetValue(); !cmp.Equal(got, tt.want) {
+			t.Errorf("GetValue of %s field = %#v, want %#v", tt.field.Type, got, tt.want)
+		}
+	}
+}
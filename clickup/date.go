@@ -2,6 +2,7 @@ package clickup
 
 import (
 	"encoding/json"
+	"net/url"
 	"strconv"
 	"time"
 )
@@ -92,6 +93,16 @@ func (d Date) MarshalJSON() ([]byte, error) {
 	return json.Marshal(d.unix)
 }
 
+// EncodeValues encodes the date as Unix milliseconds in query parameters.
+func (d Date) EncodeValues(key string, v *url.Values) error {
+	if d.null {
+		return nil
+	}
+
+	v.Set(key, d.unix.String())
+	return nil
+}
+
 func int64ToJsonNumber(n int64) json.Number {
 	b := []byte(strconv.Itoa(int(n)))
 
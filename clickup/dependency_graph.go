@@ -0,0 +1,490 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DependencyGraph is the graph of "waiting on" dependencies between tasks,
+// built from Task.Dependencies. An edge points from a task to the tasks
+// waiting on it. Linked tasks are kept separately as they impose no order.
+type DependencyGraph struct {
+	order      []string
+	tasks      map[string]*Task
+	dependsOn  map[string][]string
+	dependents map[string][]string
+	external   map[string][]string
+	links      map[string][]string
+}
+
+// DependencyCycleError is returned when dependencies form a cycle.
+// Cycle lists the task IDs in order, starting and ending with the same task.
+type DependencyCycleError struct {
+	Cycle []string
+}
+
+func (e *DependencyCycleError) Error() string {
+	return fmt.Sprintf("dependency cycle: %s", strings.Join(e.Cycle, " -> "))
+}
+
+// BlockedTask is an open task waiting on open dependencies.
+type BlockedTask struct {
+	Task      *Task
+	BlockedBy []*Task
+}
+
+// ScheduledTask is the schedule of a task computed by the critical path method.
+type ScheduledTask struct {
+	Task           *Task
+	Duration       time.Duration
+	EarliestStart  time.Time
+	EarliestFinish time.Time
+	LatestStart    time.Time
+	LatestFinish   time.Time
+	Slack          time.Duration
+}
+
+// Critical reports whether delaying the task delays the whole graph.
+func (st *ScheduledTask) Critical() bool {
+	return st.Slack == 0
+}
+
+// CriticalPath is the result of DependencyGraph.CriticalPath.
+type CriticalPath struct {
+	// Path is the longest chain of dependent tasks, in order.
+	Path []*ScheduledTask
+	// Tasks holds the schedule of every task by task ID.
+	Tasks  map[string]*ScheduledTask
+	Start  time.Time
+	Finish time.Time
+}
+
+// NewDependencyGraph builds a DependencyGraph from tasks. Dependencies on
+// tasks which are not in tasks are reported by External.
+func NewDependencyGraph(tasks []Task) *DependencyGraph {
+	g := &DependencyGraph{
+		tasks:      map[string]*Task{},
+		dependsOn:  map[string][]string{},
+		dependents: map[string][]string{},
+		external:   map[string][]string{},
+		links:      map[string][]string{},
+	}
+
+	for i := range tasks {
+		t := &tasks[i]
+		if _, ok := g.tasks[t.ID]; ok {
+			continue
+		}
+		g.tasks[t.ID] = t
+		g.order = append(g.order, t.ID)
+	}
+
+	for _, id := range g.order {
+		t := g.tasks[id]
+		for _, d := range t.Dependencies {
+			g.addEdge(d.DependsOn, d.TaskID)
+		}
+		for _, l := range t.LinkedTasks {
+			other := l.LinkID
+			if other == id {
+				other = l.TaskID
+			}
+			if !containsString(g.links[id], other) {
+				g.links[id] = append(g.links[id], other)
+			}
+		}
+	}
+
+	return g
+}
+
+func (g *DependencyGraph) addEdge(from, to string) {
+	_, fromOK := g.tasks[from]
+	_, toOK := g.tasks[to]
+	switch {
+	case from == "" || to == "":
+		return
+	case fromOK && toOK:
+		if !containsString(g.dependsOn[to], from) {
+			g.dependsOn[to] = append(g.dependsOn[to], from)
+			g.dependents[from] = append(g.dependents[from], to)
+		}
+	case toOK:
+		if !containsString(g.external[to], from) {
+			g.external[to] = append(g.external[to], from)
+		}
+	}
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Task returns the task with the given ID, or nil.
+func (g *DependencyGraph) Task(taskID string) *Task {
+	return g.tasks[taskID]
+}
+
+// TaskIDs returns the IDs of all tasks in the order they were added.
+func (g *DependencyGraph) TaskIDs() []string {
+	return append([]string(nil), g.order...)
+}
+
+// DependsOn returns the IDs of the tasks taskID is waiting on.
+func (g *DependencyGraph) DependsOn(taskID string) []string {
+	return g.dependsOn[taskID]
+}
+
+// Dependents returns the IDs of the tasks waiting on taskID.
+func (g *DependencyGraph) Dependents(taskID string) []string {
+	return g.dependents[taskID]
+}
+
+// External returns the IDs of tasks outside the graph that taskID is waiting on.
+func (g *DependencyGraph) External(taskID string) []string {
+	return g.external[taskID]
+}
+
+// Links returns the IDs of the tasks linked to taskID.
+func (g *DependencyGraph) Links(taskID string) []string {
+	return g.links[taskID]
+}
+
+// Cycles returns every dependency cycle in the graph.
+func (g *DependencyGraph) Cycles() [][]string {
+	// Tarjan's strongly connected components.
+	index := map[string]int{}
+	low := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	var sccs [][]string
+
+	var connect func(v string)
+	connect = func(v string) {
+		index[v] = len(index)
+		low[v] = index[v]
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range g.dependents[v] {
+			if _, ok := index[w]; !ok {
+				connect(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+
+		if low[v] == index[v] {
+			var scc []string
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				scc = append(scc, w)
+				if w == v {
+					break
+				}
+			}
+			if len(scc) > 1 || containsString(g.dependents[v], v) {
+				sccs = append(sccs, scc)
+			}
+		}
+	}
+
+	for _, id := range g.order {
+		if _, ok := index[id]; !ok {
+			connect(id)
+		}
+	}
+
+	cycles := make([][]string, len(sccs))
+	for i, scc := range sccs {
+		cycles[i] = g.cycleWithin(scc)
+	}
+
+	return cycles
+}
+
+// cycleWithin returns a cycle within a strongly connected component, starting at its root.
+func (g *DependencyGraph) cycleWithin(scc []string) []string {
+	in := map[string]bool{}
+	for _, id := range scc {
+		in[id] = true
+	}
+	start := scc[len(scc)-1]
+
+	path := g.path(start, start, in)
+	return append([]string{start}, path...)
+}
+
+// path returns the tasks along a path of dependents from from to to,
+// excluding from, restricted to tasks in allowed if it is not nil.
+func (g *DependencyGraph) path(from, to string, allowed map[string]bool) []string {
+	prev := map[string]string{}
+	queue := []string{from}
+	seen := map[string]bool{}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range g.dependents[v] {
+			if allowed != nil && !allowed[w] {
+				continue
+			}
+			if w == to {
+				path := []string{w}
+				for u := v; u != from; u = prev[u] {
+					path = append([]string{u}, path...)
+				}
+				return path
+			}
+			if !seen[w] {
+				seen[w] = true
+				prev[w] = v
+				queue = append(queue, w)
+			}
+		}
+	}
+
+	return nil
+}
+
+// WouldCreateCycle checks whether making taskID wait on dependsOn would
+// create a cycle, and returns it as a *DependencyCycleError if so.
+func (g *DependencyGraph) WouldCreateCycle(taskID, dependsOn string) error {
+	if taskID == dependsOn {
+		return &DependencyCycleError{Cycle: []string{taskID, taskID}}
+	}
+
+	if path := g.path(taskID, dependsOn, nil); path != nil {
+		return &DependencyCycleError{Cycle: append([]string{dependsOn, taskID}, path...)}
+	}
+
+	return nil
+}
+
+// AddDependency records that taskID waits on dependsOn, unless it would
+// create a cycle. Both tasks must already be in the graph.
+func (g *DependencyGraph) AddDependency(taskID, dependsOn string) error {
+	if g.tasks[taskID] == nil || g.tasks[dependsOn] == nil {
+		return fmt.Errorf("task %q or %q is not in the dependency graph", taskID, dependsOn)
+	}
+	if err := g.WouldCreateCycle(taskID, dependsOn); err != nil {
+		return err
+	}
+	g.addEdge(dependsOn, taskID)
+
+	return nil
+}
+
+// TopologicalOrder returns the task IDs so that every task comes after the
+// tasks it waits on. Ties keep the order tasks were added in.
+// If the graph has a cycle, a *DependencyCycleError is returned.
+func (g *DependencyGraph) TopologicalOrder() ([]string, error) {
+	indegree := map[string]int{}
+	for _, id := range g.order {
+		indegree[id] = len(g.dependsOn[id])
+	}
+
+	var ready, sorted []string
+	for _, id := range g.order {
+		if indegree[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+
+	for len(ready) > 0 {
+		v := ready[0]
+		ready = ready[1:]
+		sorted = append(sorted, v)
+		for _, w := range g.dependents[v] {
+			indegree[w]--
+			if indegree[w] == 0 {
+				ready = append(ready, w)
+			}
+		}
+	}
+
+	if len(sorted) < len(g.order) {
+		return nil, &DependencyCycleError{Cycle: g.Cycles()[0]}
+	}
+
+	return sorted, nil
+}
+
+// isTaskClosed reports whether t has a done or closed status.
+func isTaskClosed(t *Task) bool {
+	return t.Status.Type == "closed" || t.Status.Type == "done"
+}
+
+// Blocked returns the open tasks which wait on at least one open task in the graph.
+func (g *DependencyGraph) Blocked() []BlockedTask {
+	var blocked []BlockedTask
+	for _, id := range g.order {
+		t := g.tasks[id]
+		if isTaskClosed(t) {
+			continue
+		}
+
+		bt := BlockedTask{Task: t}
+		for _, dep := range g.dependsOn[id] {
+			if d := g.tasks[dep]; !isTaskClosed(d) {
+				bt.BlockedBy = append(bt.BlockedBy, d)
+			}
+		}
+		if len(bt.BlockedBy) > 0 {
+			blocked = append(blocked, bt)
+		}
+	}
+
+	return blocked
+}
+
+// taskDuration is the TimeEstimate of t, or the time between its start and
+// due date if it has no estimate.
+func taskDuration(t *Task) time.Duration {
+	if t.TimeEstimate > 0 {
+		return time.Duration(t.TimeEstimate) * time.Millisecond
+	}
+
+	start, due := taskStartTime(t), taskDueTime(t)
+	if start != nil && due != nil && due.After(*start) {
+		return due.Sub(*start)
+	}
+
+	return 0
+}
+
+func taskStartTime(t *Task) *time.Time {
+	return unixMilliString(t.StartDate)
+}
+
+func taskDueTime(t *Task) *time.Time {
+	if t.DueDate == nil {
+		return nil
+	}
+
+	return t.DueDate.Time()
+}
+
+// CriticalPath schedules every task as early as its dependencies and start
+// date allow, using taskDuration, and returns the longest chain of dependent
+// tasks. Tasks without a start date may begin at origin.
+// If the graph has a cycle, a *DependencyCycleError is returned.
+func (g *DependencyGraph) CriticalPath(origin time.Time) (*CriticalPath, error) {
+	order, err := g.TopologicalOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	cp := &CriticalPath{Tasks: map[string]*ScheduledTask{}, Start: origin, Finish: origin}
+	for _, id := range order {
+		t := g.tasks[id]
+		st := &ScheduledTask{Task: t, Duration: taskDuration(t), EarliestStart: origin}
+		if start := taskStartTime(t); start != nil && start.After(st.EarliestStart) {
+			st.EarliestStart = *start
+		}
+		for _, dep := range g.dependsOn[id] {
+			if f := cp.Tasks[dep].EarliestFinish; f.After(st.EarliestStart) {
+				st.EarliestStart = f
+			}
+		}
+		st.EarliestFinish = st.EarliestStart.Add(st.Duration)
+		if st.EarliestFinish.After(cp.Finish) {
+			cp.Finish = st.EarliestFinish
+		}
+		cp.Tasks[id] = st
+	}
+
+	for i := len(order) - 1; i >= 0; i-- {
+		st := cp.Tasks[order[i]]
+		st.LatestFinish = cp.Finish
+		for _, dep := range g.dependents[order[i]] {
+			if s := cp.Tasks[dep].LatestStart; s.Before(st.LatestFinish) {
+				st.LatestFinish = s
+			}
+		}
+		st.LatestStart = st.LatestFinish.Add(-st.Duration)
+		st.Slack = st.LatestStart.Sub(st.EarliestStart)
+	}
+
+	// Walk back from the critical task finishing last.
+	var cur *ScheduledTask
+	for _, id := range order {
+		st := cp.Tasks[id]
+		if st.Critical() && st.EarliestFinish.Equal(cp.Finish) {
+			cur = st
+		}
+	}
+	for cur != nil {
+		cp.Path = append([]*ScheduledTask{cur}, cp.Path...)
+		var prev *ScheduledTask
+		for _, dep := range g.dependsOn[cur.Task.ID] {
+			st := cp.Tasks[dep]
+			if st.Critical() && st.EarliestFinish.Equal(cur.EarliestStart) {
+				prev = st
+				break
+			}
+		}
+		cur = prev
+	}
+
+	return cp, nil
+}
+
+// GetListDependencyGraph fetches every task in a list, including subtasks
+// and closed tasks, and builds their dependency graph.
+func (s *TasksService) GetListDependencyGraph(ctx context.Context, listID string) (*DependencyGraph, *Response, error) {
+	opts := &GetTasksOptions{Subtasks: true, IncludeClosed: true}
+	tasks, resp, err := fetchAllTasks(ctx, opts, func(ctx context.Context, opts *GetTasksOptions) ([]Task, *Response, error) {
+		return s.GetTasks(ctx, listID, opts)
+	})
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return NewDependencyGraph(tasks), resp, nil
+}
+
+// GetSpaceDependencyGraph fetches every task in a space, including subtasks
+// and closed tasks, and builds their dependency graph.
+func (s *TasksService) GetSpaceDependencyGraph(ctx context.Context, teamID string, spaceID string) (*DependencyGraph, *Response, error) {
+	opts := &GetTasksOptions{Subtasks: true, IncludeClosed: true, SpaceIds: []string{spaceID}}
+	tasks, resp, err := fetchAllTasks(ctx, opts, func(ctx context.Context, opts *GetTasksOptions) ([]Task, *Response, error) {
+		return s.GetFilteredTeamTasks(ctx, teamID, opts)
+	})
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return NewDependencyGraph(tasks), resp, nil
+}
+
+// AddDependencyChecked adds a dependency like AddDependency, but first
+// checks that it would not create a cycle in g. On success the dependency is
+// also recorded in g.
+func (s *DependenciesService) AddDependencyChecked(ctx context.Context, g *DependencyGraph, taskID string, adr *AddDependencyRequest, opts *AddDependencyOptions) (*Response, error) {
+	waiting, dependsOn := taskID, adr.DependsOn
+	if adr.DependencyOf != "" {
+		waiting, dependsOn = adr.DependencyOf, taskID
+	}
+	if err := g.WouldCreateCycle(waiting, dependsOn); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.AddDependency(ctx, taskID, adr, opts)
+	if err != nil {
+		return resp, err
+	}
+	g.addEdge(dependsOn, waiting)
+
+	return resp, nil
+}
@@ -0,0 +1,203 @@
+package clickup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// depTask returns a task waiting on dependsOn.
+func depTask(id string, estimate time.Duration, status string, dependsOn ...string) Task {
+	t := Task{ID: id, TimeEstimate: estimate.Milliseconds(), Status: TaskStatus{Type: status}}
+	for _, d := range dependsOn {
+		t.Dependencies = append(t.Dependencies, Dependence{TaskID: id, DependsOn: d})
+	}
+	return t
+}
+
+func TestDependencyGraph_TopologicalOrder(t *testing.T) {
+	g := NewDependencyGraph([]Task{
+		depTask("deploy", 0, "open", "test", "build"),
+		depTask("test", 0, "open", "build"),
+		depTask("build", 0, "open"),
+		depTask("docs", 0, "open", "outside"),
+	})
+
+	got, err := g.TopologicalOrder()
+	if err != nil {
+		t.Fatalf("TopologicalOrder returned error: %v", err)
+	}
+	if want := []string{"build", "docs", "test", "deploy"}; !cmp.Equal(got, want) {
+		t.Errorf("TopologicalOrder = %v, want %v", got, want)
+	}
+	if want := []string{"outside"}; !cmp.Equal(g.External("docs"), want) {
+		t.Errorf("External = %v, want %v", g.External("docs"), want)
+	}
+	if len(g.Cycles()) != 0 {
+		t.Errorf("Cycles = %v, want none", g.Cycles())
+	}
+}
+
+func TestDependencyGraph_Cycles(t *testing.T) {
+	g := NewDependencyGraph([]Task{
+		depTask("a", 0, "open", "c"),
+		depTask("b", 0, "open", "a"),
+		depTask("c", 0, "open", "b"),
+		depTask("d", 0, "open", "d"),
+		depTask("e", 0, "open", "a"),
+	})
+
+	want := [][]string{{"a", "b", "c", "a"}, {"d", "d"}}
+	if got := g.Cycles(); !cmp.Equal(got, want) {
+		t.Errorf("Cycles = %v, want %v", got, want)
+	}
+
+	_, err := g.TopologicalOrder()
+	var cerr *DependencyCycleError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("TopologicalOrder returned %v, want *DependencyCycleError", err)
+	}
+	if want := "dependency cycle: a -> b -> c -> a"; cerr.Error() != want {
+		t.Errorf("Error = %q, want %q", cerr.Error(), want)
+	}
+}
+
+func TestDependencyGraph_WouldCreateCycle(t *testing.T) {
+	g := NewDependencyGraph([]Task{
+		depTask("a", 0, "open"),
+		depTask("b", 0, "open", "a"),
+		depTask("c", 0, "open", "b"),
+	})
+
+	err := g.WouldCreateCycle("a", "c")
+	var cerr *DependencyCycleError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("WouldCreateCycle returned %v, want *DependencyCycleError", err)
+	}
+	if want := []string{"c", "a", "b", "c"}; !cmp.Equal(cerr.Cycle, want) {
+		t.Errorf("Cycle = %v, want %v", cerr.Cycle, want)
+	}
+	if err := g.WouldCreateCycle("c", "a"); err != nil {
+		t.Errorf("WouldCreateCycle(c, a) returned %v, want nil", err)
+	}
+	if err := g.AddDependency("a", "a"); err == nil {
+		t.Errorf("AddDependency(a, a) returned nil, want error")
+	}
+}
+
+func TestDependencyGraph_Blocked(t *testing.T) {
+	g := NewDependencyGraph([]Task{
+		depTask("a", 0, "closed"),
+		depTask("b", 0, "open"),
+		depTask("c", 0, "custom", "a", "b"),
+		depTask("d", 0, "open", "a"),
+		depTask("e", 0, "done", "b"),
+	})
+
+	blocked := g.Blocked()
+	if len(blocked) != 1 || blocked[0].Task.ID != "c" || len(blocked[0].BlockedBy) != 1 || blocked[0].BlockedBy[0].ID != "b" {
+		t.Errorf("Blocked = %+v, want c blocked by b", blocked)
+	}
+}
+
+func TestDependencyGraph_CriticalPath(t *testing.T) {
+	origin := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	late := depTask("late", time.Hour, "open")
+	late.StartDate = fmt.Sprint(origin.Add(2 * time.Hour).UnixMilli())
+
+	g := NewDependencyGraph([]Task{
+		depTask("design", 4*time.Hour, "open"),
+		depTask("backend", 8*time.Hour, "open", "design"),
+		depTask("frontend", 3*time.Hour, "open", "design"),
+		depTask("release", time.Hour, "open", "backend", "frontend"),
+		late,
+	})
+
+	cp, err := g.CriticalPath(origin)
+	if err != nil {
+		t.Fatalf("CriticalPath returned error: %v", err)
+	}
+
+	var path []string
+	for _, st := range cp.Path {
+		path = append(path, st.Task.ID)
+	}
+	if want := []string{"design", "backend", "release"}; !cmp.Equal(path, want) {
+		t.Errorf("Path = %v, want %v", path, want)
+	}
+	if want := origin.Add(13 * time.Hour); !cp.Finish.Equal(want) {
+		t.Errorf("Finish = %v, want %v", cp.Finish, want)
+	}
+	if got, want := cp.Tasks["frontend"].Slack, 5*time.Hour; got != want {
+		t.Errorf("frontend slack = %v, want %v", got, want)
+	}
+	if got, want := cp.Tasks["late"].EarliestStart, origin.Add(2*time.Hour); !got.Equal(want) {
+		t.Errorf("late earliest start = %v, want %v", got, want)
+	}
+
+	cyclic := NewDependencyGraph([]Task{depTask("x", 0, "open", "y"), depTask("y", 0, "open", "x")})
+	if _, err := cyclic.CriticalPath(origin); err == nil {
+		t.Errorf("CriticalPath on a cycle returned nil error")
+	}
+}
+
+func TestDependenciesService_AddDependencyChecked(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	calls := 0
+	mux.HandleFunc("/task/a/dependency", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		calls++
+	})
+
+	g := NewDependencyGraph([]Task{depTask("a", 0, "open"), depTask("b", 0, "open", "a")})
+	ctx := context.Background()
+
+	if _, err := client.Dependencies.AddDependencyChecked(ctx, g, "a", &AddDependencyRequest{DependsOn: "b"}, nil); err == nil {
+		t.Errorf("AddDependencyChecked returned nil error for a cycle")
+	}
+	if calls != 0 {
+		t.Errorf("AddDependencyChecked called the API for a cycle")
+	}
+
+	c := depTask("c", 0, "open")
+	g = NewDependencyGraph([]Task{depTask("a", 0, "open"), depTask("b", 0, "open", "a"), c})
+	if _, err := client.Dependencies.AddDependencyChecked(ctx, g, "a", &AddDependencyRequest{DependencyOf: "c"}, nil); err != nil {
+		t.Fatalf("AddDependencyChecked returned error: %v", err)
+	}
+	if calls != 1 || !cmp.Equal(g.DependsOn("c"), []string{"a"}) {
+		t.Errorf("AddDependencyChecked calls = %d, DependsOn(c) = %v", calls, g.DependsOn("c"))
+	}
+}
+
+func TestTasksService_GetListDependencyGraph(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/list/123/task", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{"subtasks": "true", "include_closed": "true"})
+		fmt.Fprint(w, `{"tasks": [
+			{"id": "a", "dependencies": [{"task_id": "b", "depends_on": "a", "type": 1}]},
+			{"id": "b", "dependencies": [{"task_id": "b", "depends_on": "a", "type": 0}],
+			 "linked_tasks": [{"task_id": "b", "link_id": "a"}]}
+		]}`)
+	})
+
+	g, _, err := client.Tasks.GetListDependencyGraph(context.Background(), "123")
+	if err != nil {
+		t.Fatalf("Tasks.GetListDependencyGraph returned error: %v", err)
+	}
+	if want := []string{"b"}; !cmp.Equal(g.Dependents("a"), want) {
+		t.Errorf("Dependents(a) = %v, want %v", g.Dependents("a"), want)
+	}
+	if want := []string{"a"}; !cmp.Equal(g.Links("b"), want) {
+		t.Errorf("Links(b) = %v, want %v", g.Links("b"), want)
+	}
+}
@@ -0,0 +1,434 @@
+package clickup
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+type DiagramFormat int
+
+const (
+	MermaidGantt DiagramFormat = iota
+	GraphvizDOT
+	SVGGantt
+)
+
+// Swimlane groups the tasks of a diagram.
+type Swimlane int
+
+const (
+	NoSwimlanes Swimlane = iota
+	// SwimlaneByAssignee groups tasks by their first assignee.
+	SwimlaneByAssignee
+	// SwimlaneByList groups tasks by the list they belong to.
+	SwimlaneByList
+)
+
+type DiagramOptions struct {
+	Title     string
+	Swimlanes Swimlane
+	// DefaultDuration is used for tasks with only a start or a due date and
+	// no time estimate. Defaults to a day.
+	DefaultDuration time.Duration
+}
+
+// WriteDiagram renders tasks as a diagram in the given format.
+// Mermaid and SVG gantt charts leave out tasks with neither a start nor a due date.
+func WriteDiagram(w io.Writer, tasks []Task, format DiagramFormat, opts *DiagramOptions) error {
+	switch format {
+	case MermaidGantt:
+		return WriteMermaidGantt(w, tasks, opts)
+	case GraphvizDOT:
+		return WriteDOT(w, tasks, opts)
+	case SVGGantt:
+		return WriteSVGGantt(w, tasks, opts)
+	}
+
+	return fmt.Errorf("unknown diagram format %d", format)
+}
+
+// ExportListDiagram fetches every task in a list, including subtasks and
+// closed tasks, and renders them with WriteDiagram.
+func (s *TasksService) ExportListDiagram(ctx context.Context, listID string, w io.Writer, format DiagramFormat, opts *DiagramOptions) (*Response, error) {
+	o := &GetTasksOptions{Subtasks: true, IncludeClosed: true}
+	tasks, resp, err := fetchAllTasks(ctx, o, func(ctx context.Context, opts *GetTasksOptions) ([]Task, *Response, error) {
+		return s.GetTasks(ctx, listID, opts)
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, WriteDiagram(w, tasks, format, opts)
+}
+
+// ExportFolderDiagram fetches every task in a folder, including subtasks and
+// closed tasks, and renders them with WriteDiagram.
+func (s *TasksService) ExportFolderDiagram(ctx context.Context, teamID string, folderID string, w io.Writer, format DiagramFormat, opts *DiagramOptions) (*Response, error) {
+	o := &GetTasksOptions{Subtasks: true, IncludeClosed: true, ProjectIds: []string{folderID}}
+	tasks, resp, err := fetchAllTasks(ctx, o, func(ctx context.Context, opts *GetTasksOptions) ([]Task, *Response, error) {
+		return s.GetFilteredTeamTasks(ctx, teamID, opts)
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, WriteDiagram(w, tasks, format, opts)
+}
+
+// diagramItem is a task placed on a diagram.
+type diagramItem struct {
+	task       *Task
+	id         string
+	start, end time.Time
+	dated      bool
+}
+
+type diagramLane struct {
+	name  string
+	items []*diagramItem
+}
+
+type diagram struct {
+	lanes []*diagramLane
+	items map[string]*diagramItem
+	g     *DependencyGraph
+}
+
+func newDiagram(tasks []Task, opts *DiagramOptions) *diagram {
+	o := DiagramOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.DefaultDuration <= 0 {
+		o.DefaultDuration = 24 * time.Hour
+	}
+
+	d := &diagram{items: map[string]*diagramItem{}, g: NewDependencyGraph(tasks)}
+	lanes := map[string]*diagramLane{}
+	for _, id := range d.g.order {
+		t := d.g.tasks[id]
+		it := &diagramItem{task: t, id: diagramID(t.ID)}
+		it.start, it.end, it.dated = diagramSpan(t, o.DefaultDuration)
+		d.items[t.ID] = it
+
+		name := ""
+		switch o.Swimlanes {
+		case SwimlaneByAssignee:
+			name = "Unassigned"
+			if len(t.Assignees) > 0 {
+				name = t.Assignees[0].Username
+			}
+		case SwimlaneByList:
+			name = t.List.Name
+			if name == "" {
+				name = t.List.ID
+			}
+		}
+
+		lane, ok := lanes[name]
+		if !ok {
+			lane = &diagramLane{name: name}
+			lanes[name] = lane
+			d.lanes = append(d.lanes, lane)
+		}
+		lane.items = append(lane.items, it)
+	}
+
+	for _, lane := range d.lanes {
+		sort.SliceStable(lane.items, func(i, j int) bool {
+			return lane.items[i].start.Before(lane.items[j].start)
+		})
+	}
+
+	return d
+}
+
+func diagramSpan(t *Task, def time.Duration) (time.Time, time.Time, bool) {
+	start, due := taskStartTime(t), taskDueTime(t)
+	dur := def
+	if t.TimeEstimate > 0 {
+		dur = time.Duration(t.TimeEstimate) * time.Millisecond
+	}
+
+	switch {
+	case start != nil && due != nil && !due.Before(*start):
+		return *start, *due, true
+	case start != nil:
+		return *start, start.Add(dur), true
+	case due != nil:
+		return due.Add(-dur), *due, true
+	}
+
+	return time.Time{}, time.Time{}, false
+}
+
+var diagramIDPattern = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+func diagramID(taskID string) string {
+	return "t_" + diagramIDPattern.ReplaceAllString(taskID, "_")
+}
+
+// diagramStatus classifies a task status as done, active or open.
+func diagramStatus(t *Task) string {
+	switch {
+	case isTaskClosed(t):
+		return "done"
+	case t.Status.Type == "custom":
+		return "active"
+	}
+
+	return "open"
+}
+
+// WriteMermaidGantt renders tasks as a Mermaid gantt chart.
+func WriteMermaidGantt(w io.Writer, tasks []Task, opts *DiagramOptions) error {
+	d := newDiagram(tasks, opts)
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "gantt")
+	if opts != nil && opts.Title != "" {
+		fmt.Fprintf(bw, "    title %s\n", mermaidText(opts.Title))
+	}
+	fmt.Fprintln(bw, "    dateFormat YYYY-MM-DDTHH:mm")
+	io.WriteString(bw, "    axisFormat %m-%d\n")
+
+	const layout = "2006-01-02T15:04"
+	for _, lane := range d.lanes {
+		if lane.name != "" {
+			fmt.Fprintf(bw, "    section %s\n", mermaidText(lane.name))
+		}
+		for _, it := range lane.items {
+			if !it.dated {
+				continue
+			}
+
+			tags := []string{}
+			if s := diagramStatus(it.task); s != "open" {
+				tags = append(tags, s)
+			}
+			if it.start.Equal(it.end) {
+				tags = append(tags, "milestone")
+			}
+			tags = append(tags, it.id)
+
+			start := it.start.Format(layout)
+			if taskStartTime(it.task) == nil {
+				// Without a start date, begin after the dependencies if there are any.
+				var after []string
+				for _, dep := range d.g.dependsOn[it.task.ID] {
+					if d.items[dep].dated {
+						after = append(after, d.items[dep].id)
+					}
+				}
+				if len(after) > 0 {
+					start = "after " + strings.Join(after, " ")
+				}
+			}
+
+			fmt.Fprintf(bw, "    %s :%s, %s, %s\n", mermaidText(it.task.Name), strings.Join(tags, ", "), start, it.end.Format(layout))
+		}
+	}
+
+	return bw.Flush()
+}
+
+var mermaidReplacer = strings.NewReplacer(":", " ", "#", " ", ";", " ", "\n", " ")
+
+func mermaidText(s string) string {
+	return strings.TrimSpace(mermaidReplacer.Replace(s))
+}
+
+// WriteDOT renders tasks and their dependencies as a Graphviz DOT graph.
+// Dependencies are solid edges and linked tasks are dashed edges.
+func WriteDOT(w io.Writer, tasks []Task, opts *DiagramOptions) error {
+	d := newDiagram(tasks, opts)
+	bw := bufio.NewWriter(w)
+
+	title := "tasks"
+	if opts != nil && opts.Title != "" {
+		title = opts.Title
+	}
+	fmt.Fprintf(bw, "digraph %s {\n", dotQuote(title))
+	fmt.Fprintf(bw, "  label=%s;\n", dotQuote(title))
+	fmt.Fprintln(bw, "  rankdir=LR;")
+	fmt.Fprintln(bw, `  node [shape=box, style="rounded,filled", fillcolor="#ffffff"];`)
+
+	for i, lane := range d.lanes {
+		indent := "  "
+		if lane.name != "" {
+			fmt.Fprintf(bw, "  subgraph cluster_%d {\n    label=%s;\n", i, dotQuote(lane.name))
+			indent = "    "
+		}
+		for _, it := range lane.items {
+			label := it.task.Name
+			if it.task.Status.Status != "" {
+				label += "\n" + it.task.Status.Status
+			}
+			attrs := "label=" + dotQuote(label)
+			if c := it.task.Status.Color; c != "" {
+				attrs += ", fillcolor=" + dotQuote(c)
+			}
+			fmt.Fprintf(bw, "%s%s [%s];\n", indent, it.id, attrs)
+		}
+		if lane.name != "" {
+			fmt.Fprintln(bw, "  }")
+		}
+	}
+
+	for _, id := range d.g.order {
+		for _, dep := range d.g.dependents[id] {
+			fmt.Fprintf(bw, "  %s -> %s;\n", d.items[id].id, d.items[dep].id)
+		}
+	}
+	drawn := map[[2]string]bool{}
+	for _, id := range d.g.order {
+		for _, other := range d.g.links[id] {
+			// A link is usually stored on both tasks; draw it once.
+			o, ok := d.items[other]
+			if !ok || drawn[[2]string{other, id}] {
+				continue
+			}
+			drawn[[2]string{id, other}] = true
+			fmt.Fprintf(bw, "  %s -> %s [style=dashed, dir=none];\n", d.items[id].id, o.id)
+		}
+	}
+	fmt.Fprintln(bw, "}")
+
+	return bw.Flush()
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+
+	return `"` + s + `"`
+}
+
+// Layout of the SVG gantt chart, in pixels.
+const (
+	svgLabelWidth = 240
+	svgChartWidth = 960
+	svgRowHeight  = 24
+	svgHeader     = 40
+)
+
+// WriteSVGGantt renders tasks as a standalone SVG gantt chart with
+// dependency arrows between bars.
+func WriteSVGGantt(w io.Writer, tasks []Task, opts *DiagramOptions) error {
+	d := newDiagram(tasks, opts)
+	bw := bufio.NewWriter(w)
+
+	var first, last time.Time
+	for _, it := range d.items {
+		if !it.dated {
+			continue
+		}
+		if first.IsZero() || it.start.Before(first) {
+			first = it.start
+		}
+		if last.IsZero() || it.end.After(last) {
+			last = it.end
+		}
+	}
+	span := last.Sub(first)
+	if span <= 0 {
+		span = 24 * time.Hour
+	}
+	x := func(t time.Time) float64 {
+		return svgLabelWidth + float64(t.Sub(first))/float64(span)*svgChartWidth
+	}
+
+	type row struct {
+		lane string
+		item *diagramItem
+	}
+	var rows []row
+	for _, lane := range d.lanes {
+		if lane.name != "" {
+			rows = append(rows, row{lane: lane.name})
+		}
+		for _, it := range lane.items {
+			if it.dated {
+				rows = append(rows, row{item: it})
+			}
+		}
+	}
+
+	width := svgLabelWidth + svgChartWidth + 20
+	height := svgHeader + len(rows)*svgRowHeight + 10
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n", width, height, width, height)
+	fmt.Fprintln(bw, `<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto"><path d="M0,0 L10,5 L0,10 z" fill="#555"/></marker></defs>`)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", width, height)
+	if opts != nil && opts.Title != "" {
+		fmt.Fprintf(bw, `<text x="8" y="16" font-size="14" font-weight="bold">%s</text>`+"\n", svgText(opts.Title))
+	}
+
+	// Day ticks, thinned out to at most about 20 labels.
+	days := int(span/(24*time.Hour)) + 1
+	step := 1 + days/20
+	for day := truncateToDay(first); !day.After(last); day = day.AddDate(0, 0, step) {
+		if day.Before(first) {
+			continue
+		}
+		fmt.Fprintf(bw, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="#e0e0e0"/>`+"\n", x(day), svgHeader-8, x(day), height)
+		fmt.Fprintf(bw, `<text x="%.1f" y="%d" fill="#777">%s</text>`+"\n", x(day)+2, svgHeader-12, day.Format("01-02"))
+	}
+
+	rowY := map[string]int{}
+	for i, r := range rows {
+		y := svgHeader + i*svgRowHeight
+		if r.item == nil {
+			fmt.Fprintf(bw, `<rect x="0" y="%d" width="%d" height="%d" fill="#f3f3f3"/>`+"\n", y, width, svgRowHeight)
+			fmt.Fprintf(bw, `<text x="8" y="%d" font-weight="bold">%s</text>`+"\n", y+16, svgText(r.lane))
+			continue
+		}
+
+		it := r.item
+		rowY[it.task.ID] = y
+		color := it.task.Status.Color
+		if color == "" {
+			color = "#7b68ee"
+		}
+		fmt.Fprintf(bw, `<text x="16" y="%d">%s</text>`+"\n", y+16, svgText(it.task.Name))
+		bar := x(it.end) - x(it.start)
+		if bar < 2 {
+			bar = 2
+		}
+		fmt.Fprintf(bw, `<rect x="%.1f" y="%d" width="%.1f" height="%d" rx="3" fill="%s"><title>%s</title></rect>`+"\n",
+			x(it.start), y+4, bar, svgRowHeight-8, svgText(color), svgText(it.task.Name+" ("+it.task.Status.Status+")"))
+	}
+
+	for _, id := range d.g.order {
+		from := d.items[id]
+		fy, ok := rowY[id]
+		if !ok {
+			continue
+		}
+		for _, dep := range d.g.dependents[id] {
+			ty, ok := rowY[dep]
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(bw, `<path d="M%.1f,%d H%.1f V%d H%.1f" fill="none" stroke="#555" marker-end="url(#arrow)"/>`+"\n",
+				x(from.end), fy+svgRowHeight/2, x(from.end)+6, ty+svgRowHeight/2, x(d.items[dep].start))
+		}
+	}
+	fmt.Fprintln(bw, "</svg>")
+
+	return bw.Flush()
+}
+
+func svgText(s string) string {
+	sb := strings.Builder{}
+	xml.EscapeText(&sb, []byte(s))
+
+	return sb.String()
+}
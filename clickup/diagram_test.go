@@ -0,0 +1,147 @@
+package clickup
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func diagramTasks() []Task {
+	spec := scheduledTask("spec", day(1, 9), day(2, 17))
+	spec.Status = TaskStatus{Status: "complete", Type: "closed", Color: "#6bc950"}
+	spec.Assignees = []User{{ID: 1, Username: "alice"}}
+	spec.List = ListOfTaskBelonging{ID: "10", Name: "Backend"}
+
+	build := scheduledTask("build", day(3, 9), day(6, 17), "spec")
+	build.Name = "build: api"
+	build.Status = TaskStatus{Status: "in progress", Type: "custom", Color: "#4194f6"}
+	build.Assignees = []User{{ID: 2, Username: "bob"}}
+	build.List = ListOfTaskBelonging{ID: "10", Name: "Backend"}
+	build.LinkedTasks = []LinkedTask{{TaskID: "build", LinkID: "docs"}}
+
+	release := depTask("release", 0, "open", "build")
+	release.Name = "release"
+	release.DueDate = NewDate(day(7, 12))
+	release.TimeEstimate = (3 * time.Hour).Milliseconds()
+	release.List = ListOfTaskBelonging{ID: "11", Name: "Ops"}
+
+	docs := Task{ID: "docs", Name: "docs", LinkedTasks: []LinkedTask{{TaskID: "docs", LinkID: "build"}}}
+
+	return []Task{spec, build, release, docs}
+}
+
+func TestWriteMermaidGantt(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteMermaidGantt(&buf, diagramTasks(), &DiagramOptions{Title: "Sprint: 1", Swimlanes: SwimlaneByAssignee}); err != nil {
+		t.Fatalf("WriteMermaidGantt returned error: %v", err)
+	}
+
+	want := `gantt
+    title Sprint  1
+    dateFormat YYYY-MM-DDTHH:mm
+    axisFormat %m-%d
+    section alice
+    spec :done, t_spec, 2024-05-01T09:00, 2024-05-02T17:00
+    section bob
+    build  api :active, t_build, 2024-05-03T09:00, 2024-05-06T17:00
+    section Unassigned
+    release :t_release, after t_build, 2024-05-07T12:00
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteMermaidGantt =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteDOT(&buf, diagramTasks(), &DiagramOptions{Swimlanes: SwimlaneByList}); err != nil {
+		t.Fatalf("WriteDOT returned error: %v", err)
+	}
+
+	want := `digraph "tasks" {
+  label="tasks";
+  rankdir=LR;
+  node [shape=box, style="rounded,filled", fillcolor="#ffffff"];
+  subgraph cluster_0 {
+    label="Backend";
+    t_spec [label="spec\ncomplete", fillcolor="#6bc950"];
+    t_build [label="build: api\nin progress", fillcolor="#4194f6"];
+  }
+  subgraph cluster_1 {
+    label="Ops";
+    t_release [label="release"];
+  }
+  t_docs [label="docs"];
+  t_spec -> t_build;
+  t_build -> t_release;
+  t_build -> t_docs [style=dashed, dir=none];
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteDOT =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteSVGGantt(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSVGGantt(&buf, diagramTasks(), &DiagramOptions{Title: "Q2 <plan>", Swimlanes: SwimlaneByList}); err != nil {
+		t.Fatalf("WriteSVGGantt returned error: %v", err)
+	}
+
+	// The output must be well-formed XML.
+	dec := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+	counts := map[string]int{}
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("WriteSVGGantt wrote invalid XML: %v\n%s", err, buf.String())
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			counts[se.Name.Local]++
+		}
+	}
+
+	// Two lane headers plus three bars, and two dependency arrows.
+	if counts["rect"] != 1+2+3 {
+		t.Errorf("WriteSVGGantt drew %d rects, want 6", counts["rect"])
+	}
+	if counts["path"] != 1+2 {
+		t.Errorf("WriteSVGGantt drew %d paths, want 3", counts["path"])
+	}
+	if !strings.Contains(buf.String(), "Q2 &lt;plan&gt;") {
+		t.Errorf("WriteSVGGantt did not escape the title")
+	}
+}
+
+func TestWriteDiagram_unknownFormat(t *testing.T) {
+	if err := WriteDiagram(io.Discard, nil, DiagramFormat(42), nil); err == nil {
+		t.Errorf("WriteDiagram returned nil error for an unknown format")
+	}
+}
+
+func TestTasksService_ExportListDiagram(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/list/123/task", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"tasks": [{"id": "a", "name": "A"}, {"id": "b", "name": "B", "dependencies": [{"task_id": "b", "depends_on": "a"}]}]}`)
+	})
+
+	var buf bytes.Buffer
+	if _, err := client.Tasks.ExportListDiagram(context.Background(), "123", &buf, GraphvizDOT, nil); err != nil {
+		t.Fatalf("Tasks.ExportListDiagram returned error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "t_a -> t_b;") {
+		t.Errorf("Tasks.ExportListDiagram =\n%s\nwant an edge from a to b", buf.String())
+	}
+}
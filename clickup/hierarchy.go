@@ -0,0 +1,554 @@
+package clickup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// Hierarchy is the tree of spaces, folders and lists of a team, as built by
+// CrawlHierarchy. It can be serialized as JSON.
+type Hierarchy struct {
+	TeamID string            `json:"team_id"`
+	Spaces []*HierarchySpace `json:"spaces"`
+	// Shared holds what is shared with the user without access to its
+	// space or folder.
+	Shared HierarchyShared `json:"shared"`
+	Views  []View          `json:"views,omitempty"`
+}
+
+type HierarchySpace struct {
+	Space   Space              `json:"space"`
+	Folders []*HierarchyFolder `json:"folders"`
+	// Lists are the folderless lists of the space.
+	Lists []*HierarchyList `json:"lists"`
+	Views []View           `json:"views,omitempty"`
+}
+
+type HierarchyFolder struct {
+	Folder Folder           `json:"folder"`
+	Lists  []*HierarchyList `json:"lists"`
+	Views  []View           `json:"views,omitempty"`
+	// Shared is set for folders only reachable through sharing.
+	Shared bool `json:"shared,omitempty"`
+}
+
+type HierarchyList struct {
+	List  List   `json:"list"`
+	Tasks []Task `json:"tasks,omitempty"`
+	Views []View `json:"views,omitempty"`
+	// Shared is set for lists only reachable through sharing.
+	Shared bool `json:"shared,omitempty"`
+}
+
+type HierarchyShared struct {
+	Folders []*HierarchyFolder `json:"folders,omitempty"`
+	Lists   []*HierarchyList   `json:"lists,omitempty"`
+	Tasks   []Task             `json:"tasks,omitempty"`
+}
+
+// Hierarchy node kinds.
+const (
+	HierarchyKindSpace  = "space"
+	HierarchyKindFolder = "folder"
+	HierarchyKindList   = "list"
+	HierarchyKindTask   = "task"
+)
+
+// HierarchyNode is a node visited by Hierarchy.Walk, with its ancestors.
+// Space and Folder are nil for nodes outside of them.
+type HierarchyNode struct {
+	Kind   string
+	Depth  int
+	Space  *HierarchySpace
+	Folder *HierarchyFolder
+	List   *HierarchyList
+	Task   *Task
+}
+
+// ID returns the ID of the node.
+func (n HierarchyNode) ID() string {
+	switch n.Kind {
+	case HierarchyKindSpace:
+		return n.Space.Space.ID
+	case HierarchyKindFolder:
+		return n.Folder.Folder.ID
+	case HierarchyKindList:
+		return n.List.List.ID
+	case HierarchyKindTask:
+		return n.Task.ID
+	}
+
+	return ""
+}
+
+// Name returns the name of the node.
+func (n HierarchyNode) Name() string {
+	switch n.Kind {
+	case HierarchyKindSpace:
+		return n.Space.Space.Name
+	case HierarchyKindFolder:
+		return n.Folder.Folder.Name
+	case HierarchyKindList:
+		return n.List.List.Name
+	case HierarchyKindTask:
+		return n.Task.Name
+	}
+
+	return ""
+}
+
+// SkipChildren is returned by a Walk function to skip the children of a
+// node.
+var SkipChildren = errors.New("skip children")
+
+// Walk calls fn for every node depth first: each space, its folders with
+// their lists, then its folderless lists, with the tasks of every list.
+// Shared folders, lists and tasks outside the spaces come last at depth 0.
+// An error from fn other than SkipChildren stops the walk and is returned.
+func (h *Hierarchy) Walk(fn func(n HierarchyNode) error) error {
+	visit := func(n HierarchyNode, children func(n HierarchyNode) error) error {
+		err := fn(n)
+		if err == SkipChildren {
+			return nil
+		}
+		if err != nil || children == nil {
+			return err
+		}
+		return children(n)
+	}
+
+	var walkList func(n HierarchyNode) error
+	walkList = func(n HierarchyNode) error {
+		for i := range n.List.Tasks {
+			t := HierarchyNode{Kind: HierarchyKindTask, Depth: n.Depth + 1, Space: n.Space, Folder: n.Folder, List: n.List, Task: &n.List.Tasks[i]}
+			if err := visit(t, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	walkFolder := func(n HierarchyNode) error {
+		for _, l := range n.Folder.Lists {
+			if err := visit(HierarchyNode{Kind: HierarchyKindList, Depth: n.Depth + 1, Space: n.Space, Folder: n.Folder, List: l}, walkList); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	walkSpace := func(n HierarchyNode) error {
+		for _, f := range n.Space.Folders {
+			if err := visit(HierarchyNode{Kind: HierarchyKindFolder, Depth: 1, Space: n.Space, Folder: f}, walkFolder); err != nil {
+				return err
+			}
+		}
+		for _, l := range n.Space.Lists {
+			if err := visit(HierarchyNode{Kind: HierarchyKindList, Depth: 1, Space: n.Space, List: l}, walkList); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, s := range h.Spaces {
+		if err := visit(HierarchyNode{Kind: HierarchyKindSpace, Space: s}, walkSpace); err != nil {
+			return err
+		}
+	}
+	for _, f := range h.Shared.Folders {
+		if err := visit(HierarchyNode{Kind: HierarchyKindFolder, Folder: f}, walkFolder); err != nil {
+			return err
+		}
+	}
+	for _, l := range h.Shared.Lists {
+		if err := visit(HierarchyNode{Kind: HierarchyKindList, List: l}, walkList); err != nil {
+			return err
+		}
+	}
+	for i := range h.Shared.Tasks {
+		if err := visit(HierarchyNode{Kind: HierarchyKindTask, Task: &h.Shared.Tasks[i]}, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Lists returns every list in the tree, including shared ones.
+func (h *Hierarchy) Lists() []*HierarchyList {
+	var lists []*HierarchyList
+	h.Walk(func(n HierarchyNode) error {
+		if n.Kind == HierarchyKindList {
+			lists = append(lists, n.List)
+			return SkipChildren
+		}
+		return nil
+	})
+
+	return lists
+}
+
+type CrawlHierarchyOptions struct {
+	// Archived includes archived spaces, folders and lists.
+	Archived bool
+	// Tasks fetches the tasks of every list with TaskOptions.
+	Tasks       bool
+	TaskOptions *GetTasksOptions
+	// Views fetches the views of the team and of every space, folder and
+	// list.
+	Views bool
+	// Concurrency is how many requests are made at once; the default is
+	// four.
+	Concurrency int
+	// RateLimitReserve is the number of requests left to other callers. The
+	// crawler waits for the rate limit to reset rather than use them.
+	RateLimitReserve int
+}
+
+// CrawlHierarchy builds the tree of spaces, folders and lists of a team,
+// fetching them concurrently. Folders, lists and tasks the user can only
+// reach through sharing are merged in from SharedHierarchy: into their
+// space or folder when it is in the tree, and into Shared otherwise.
+//
+// On error the tree built so far is returned with the error.
+func (s *TeamsService) CrawlHierarchy(ctx context.Context, teamID string, opts *CrawlHierarchyOptions) (*Hierarchy, error) {
+	c := &hierarchyCrawl{client: s.client, h: &Hierarchy{TeamID: teamID}}
+	if opts != nil {
+		c.opts = *opts
+	}
+	if c.opts.Concurrency <= 0 {
+		c.opts.Concurrency = 4
+	}
+	c.sem = make(chan struct{}, c.opts.Concurrency)
+	ctx, c.cancel = context.WithCancel(ctx)
+	defer c.cancel()
+
+	team, err := strconv.Atoi(teamID)
+	if err != nil {
+		return nil, fmt.Errorf("team ID %q is not a number", teamID)
+	}
+
+	var shared *Shared
+	c.call(ctx, func(ctx context.Context) error {
+		var err error
+		shared, _, err = s.client.SharedHierarchy.SharedHierarchy(ctx, team)
+		return err
+	})
+	c.call(ctx, func(ctx context.Context) error {
+		var spaces []Space
+		err := c.eachArchived(ctx, func(archived bool) error {
+			page, _, err := s.client.Spaces.GetSpaces(ctx, teamID, archived)
+			spaces = append(spaces, page...)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, sp := range spaces {
+			hs := &HierarchySpace{Space: sp}
+			c.h.Spaces = append(c.h.Spaces, hs)
+			c.crawlSpace(ctx, hs)
+		}
+		return nil
+	})
+	if c.opts.Views {
+		c.views(ctx, TeamView, teamID, &c.h.Views)
+	}
+	if err := c.wait(); err != nil {
+		return c.h, err
+	}
+
+	if shared == nil {
+		return c.h, nil
+	}
+
+	// Shared folders are crawled before shared lists are merged, so lists
+	// both shared and in a shared folder are not added twice.
+	c.mu.Lock()
+	c.mergeSharedFolders(ctx, shared.Folders)
+	c.mu.Unlock()
+	if err := c.wait(); err != nil {
+		return c.h, err
+	}
+	c.mu.Lock()
+	c.mergeSharedLists(ctx, shared.Lists)
+	c.mu.Unlock()
+	if err := c.wait(); err != nil {
+		return c.h, err
+	}
+
+	// Shared tasks are merged once the tasks of shared lists are fetched too.
+	c.mu.Lock()
+	c.mergeSharedTasks(shared.Tasks)
+	c.mu.Unlock()
+
+	return c.h, nil
+}
+
+type hierarchyCrawl struct {
+	client *Client
+	opts   CrawlHierarchyOptions
+	sem    chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// mu guards the tree and err.
+	mu  sync.Mutex
+	h   *Hierarchy
+	err error
+}
+
+// call runs f in a new goroutine once a request slot is free and the rate
+// limit allows. The first error cancels the crawl.
+func (c *hierarchyCrawl) call(ctx context.Context, f func(ctx context.Context) error) {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		select {
+		case c.sem <- struct{}{}:
+		case <-ctx.Done():
+			c.fail(ctx.Err())
+			return
+		}
+		err := c.client.waitRateLimit(ctx, c.opts.RateLimitReserve)
+		if err == nil {
+			err = f(ctx)
+		}
+		<-c.sem
+
+		if err != nil {
+			c.fail(err)
+		}
+	}()
+}
+
+func (c *hierarchyCrawl) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+		c.cancel()
+	}
+}
+
+func (c *hierarchyCrawl) wait() error {
+	c.wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// eachArchived calls f for the active items and then, with Archived, for
+// the archived ones, so siblings keep the same order on every crawl.
+func (c *hierarchyCrawl) eachArchived(ctx context.Context, f func(archived bool) error) error {
+	if err := f(false); err != nil || !c.opts.Archived {
+		return err
+	}
+	if err := c.client.waitRateLimit(ctx, c.opts.RateLimitReserve); err != nil {
+		return err
+	}
+
+	return f(true)
+}
+
+func (c *hierarchyCrawl) views(ctx context.Context, viewType ViewType, id string, dst *[]View) {
+	c.call(ctx, func(ctx context.Context) error {
+		views, _, err := c.client.Views.GetViewsOf(ctx, viewType, id)
+		if err != nil {
+			return err
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		*dst = views
+		return nil
+	})
+}
+
+// crawlSpace fetches the folders and folderless lists of hs. The caller
+// holds c.mu.
+func (c *hierarchyCrawl) crawlSpace(ctx context.Context, hs *HierarchySpace) {
+	c.call(ctx, func(ctx context.Context) error {
+		var folders []Folder
+		err := c.eachArchived(ctx, func(archived bool) error {
+			page, _, err := c.client.Folders.GetFolders(ctx, hs.Space.ID, archived)
+			folders = append(folders, page...)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, f := range folders {
+			hf := &HierarchyFolder{Folder: f}
+			hs.Folders = append(hs.Folders, hf)
+			c.crawlFolder(ctx, hf)
+		}
+		return nil
+	})
+	c.call(ctx, func(ctx context.Context) error {
+		var lists []List
+		err := c.eachArchived(ctx, func(archived bool) error {
+			page, _, err := c.client.Lists.GetFolderlessLists(ctx, hs.Space.ID, archived)
+			lists = append(lists, page...)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		hs.Lists = append(hs.Lists, c.newLists(ctx, lists, false)...)
+		return nil
+	})
+	if c.opts.Views {
+		c.views(ctx, SpaceView, hs.Space.ID, &hs.Views)
+	}
+}
+
+// crawlFolder fetches the lists of hf. The caller holds c.mu.
+func (c *hierarchyCrawl) crawlFolder(ctx context.Context, hf *HierarchyFolder) {
+	c.call(ctx, func(ctx context.Context) error {
+		var lists []List
+		err := c.eachArchived(ctx, func(archived bool) error {
+			page, _, err := c.client.Lists.GetLists(ctx, hf.Folder.ID, archived)
+			lists = append(lists, page...)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		hf.Lists = append(hf.Lists, c.newLists(ctx, lists, hf.Shared)...)
+		return nil
+	})
+	if c.opts.Views {
+		c.views(ctx, FolderView, hf.Folder.ID, &hf.Views)
+	}
+}
+
+// newLists wraps lists and crawls them. The caller holds c.mu.
+func (c *hierarchyCrawl) newLists(ctx context.Context, lists []List, shared bool) []*HierarchyList {
+	hls := make([]*HierarchyList, len(lists))
+	for i, l := range lists {
+		hls[i] = &HierarchyList{List: l, Shared: shared}
+		c.crawlList(ctx, hls[i])
+	}
+
+	return hls
+}
+
+// crawlList fetches the tasks and views of hl. The caller holds c.mu.
+func (c *hierarchyCrawl) crawlList(ctx context.Context, hl *HierarchyList) {
+	if c.opts.Tasks {
+		c.call(ctx, func(ctx context.Context) error {
+			tasks, _, err := fetchAllTasks(ctx, c.opts.TaskOptions, func(ctx context.Context, opts *GetTasksOptions) ([]Task, *Response, error) {
+				if err := c.client.waitRateLimit(ctx, c.opts.RateLimitReserve); err != nil {
+					return nil, nil, err
+				}
+				return c.client.Tasks.GetTasks(ctx, hl.List.ID, opts)
+			})
+			if err != nil {
+				return err
+			}
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			hl.Tasks = tasks
+			return nil
+		})
+	}
+	if c.opts.Views {
+		c.views(ctx, ListView, hl.List.ID, &hl.Views)
+	}
+}
+
+// index returns the spaces, folders and lists in the tree by ID. The caller
+// holds c.mu.
+func (c *hierarchyCrawl) index() (map[string]*HierarchySpace, map[string]*HierarchyFolder, map[string]*HierarchyList) {
+	spaces := map[string]*HierarchySpace{}
+	folders := map[string]*HierarchyFolder{}
+	lists := map[string]*HierarchyList{}
+	for _, hs := range c.h.Spaces {
+		spaces[hs.Space.ID] = hs
+		for _, hf := range hs.Folders {
+			folders[hf.Folder.ID] = hf
+		}
+	}
+	for _, hf := range c.h.Shared.Folders {
+		folders[hf.Folder.ID] = hf
+	}
+	for _, hl := range c.h.Lists() {
+		lists[hl.List.ID] = hl
+	}
+
+	return spaces, folders, lists
+}
+
+// mergeSharedFolders adds the shared folders missing from the tree and
+// crawls them. The caller holds c.mu.
+func (c *hierarchyCrawl) mergeSharedFolders(ctx context.Context, shared []Folder) {
+	spaces, folders, _ := c.index()
+	for _, f := range shared {
+		if folders[f.ID] != nil {
+			continue
+		}
+		hf := &HierarchyFolder{Folder: f, Shared: true}
+		folders[f.ID] = hf
+		if hs := spaces[f.Space.ID]; hs != nil {
+			hs.Folders = append(hs.Folders, hf)
+		} else {
+			c.h.Shared.Folders = append(c.h.Shared.Folders, hf)
+		}
+		c.crawlFolder(ctx, hf)
+	}
+}
+
+// mergeSharedLists adds the shared lists missing from the tree and crawls
+// them. The caller holds c.mu.
+func (c *hierarchyCrawl) mergeSharedLists(ctx context.Context, shared []List) {
+	spaces, folders, lists := c.index()
+	for _, l := range shared {
+		if lists[l.ID] != nil {
+			continue
+		}
+		hl := &HierarchyList{List: l, Shared: true}
+		lists[l.ID] = hl
+		switch {
+		case folders[l.Folder.ID] != nil:
+			hf := folders[l.Folder.ID]
+			hf.Lists = append(hf.Lists, hl)
+		case l.Folder.Hidden && spaces[l.Space.ID] != nil:
+			hs := spaces[l.Space.ID]
+			hs.Lists = append(hs.Lists, hl)
+		default:
+			c.h.Shared.Lists = append(c.h.Shared.Lists, hl)
+		}
+		c.crawlList(ctx, hl)
+	}
+}
+
+// mergeSharedTasks adds the shared tasks missing from the tree. The caller
+// holds c.mu.
+func (c *hierarchyCrawl) mergeSharedTasks(tasks []Task) {
+	_, _, lists := c.index()
+
+	// Without Tasks nothing was fetched to check shared tasks against.
+	seen := map[string]bool{}
+	for _, hl := range lists {
+		for _, t := range hl.Tasks {
+			seen[t.ID] = true
+		}
+	}
+	for _, t := range tasks {
+		if !seen[t.ID] {
+			c.h.Shared.Tasks = append(c.h.Shared.Tasks, t)
+		}
+	}
+}
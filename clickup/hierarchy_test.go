@@ -0,0 +1,175 @@
+package clickup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// serveHierarchy serves a team with space 1 holding folder 10 (list 100) and
+// folderless list 101, an archived folder 11 (list 110), and shares folder
+// 20 of space 2 (list 200), list 102 in space 1, and tasks t9, t100 and
+// t102.
+func serveHierarchy(mux *http.ServeMux) {
+	archived := func(r *http.Request) bool { return r.URL.Query().Get("archived") == "true" }
+	mux.HandleFunc("/team/1/space", func(w http.ResponseWriter, r *http.Request) {
+		if archived(r) {
+			fmt.Fprint(w, `{"spaces": []}`)
+			return
+		}
+		fmt.Fprint(w, `{"spaces": [{"id": "1", "name": "Eng"}]}`)
+	})
+	mux.HandleFunc("/space/1/folder", func(w http.ResponseWriter, r *http.Request) {
+		if archived(r) {
+			fmt.Fprint(w, `{"folders": [{"id": "11", "name": "Old", "archived": true}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"folders": [{"id": "10", "name": "Backend"}]}`)
+	})
+	mux.HandleFunc("/space/1/list", func(w http.ResponseWriter, r *http.Request) {
+		if archived(r) {
+			fmt.Fprint(w, `{"lists": []}`)
+			return
+		}
+		fmt.Fprint(w, `{"lists": [{"id": "101", "name": "Inbox"}]}`)
+	})
+	for folder, list := range map[string]string{"10": "100", "11": "110", "20": "200"} {
+		list := list
+		mux.HandleFunc("/folder/"+folder+"/list", func(w http.ResponseWriter, r *http.Request) {
+			if archived(r) {
+				fmt.Fprint(w, `{"lists": []}`)
+				return
+			}
+			fmt.Fprintf(w, `{"lists": [{"id": "%s", "name": "L%s"}]}`, list, list)
+		})
+	}
+	mux.HandleFunc("/team/1/shared", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"shared": {
+			"tasks": [{"id": "t9", "name": "Shared task"}, {"id": "t100", "name": "Task 100"}, {"id": "t102", "name": "Task 102"}],
+			"lists": [{"id": "100"}, {"id": "102", "name": "Private", "space": {"id": "1"}, "folder": {"id": "5", "hidden": true}}],
+			"folders": [{"id": "20", "name": "Partner", "space": {"id": "2"}}]
+		}}`)
+	})
+}
+
+func TestTeamsService_CrawlHierarchy(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+	serveHierarchy(mux)
+	mux.HandleFunc("/list/", func(w http.ResponseWriter, r *http.Request) {
+		// /list/{id}/task
+		id := strings.Split(r.URL.Path, "/")[2]
+		fmt.Fprintf(w, `{"tasks": [{"id": "t%s"}]}`, id)
+	})
+	mux.HandleFunc("/space/1/view", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"views": [{"id": "v1", "name": "Board"}]}`)
+	})
+	for _, p := range []string{"/team/1/view", "/space/2/view"} {
+		mux.HandleFunc(p, func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, `{"views": []}`) })
+	}
+	for _, p := range []string{"/folder/", "/view/"} {
+		mux.HandleFunc(p, func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, `{"views": []}`) })
+	}
+
+	h, err := client.Teams.CrawlHierarchy(context.Background(), "1", &CrawlHierarchyOptions{
+		Archived:    true,
+		Tasks:       true,
+		Views:       true,
+		Concurrency: 2,
+	})
+	if err != nil {
+		t.Fatalf("Teams.CrawlHierarchy returned error: %v", err)
+	}
+
+	var got []string
+	h.Walk(func(n HierarchyNode) error {
+		got = append(got, fmt.Sprintf("%d %s %s", n.Depth, n.Kind, n.ID()))
+		return nil
+	})
+	want := []string{
+		"0 space 1",
+		"1 folder 10", "2 list 100", "3 task t100",
+		"1 folder 11", "2 list 110", "3 task t110",
+		"1 list 101", "2 task t101",
+		"1 list 102", "2 task t102",
+		"0 folder 20", "1 list 200", "2 task t200",
+		"0 task t9",
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("Walk visited %q, want %q", got, want)
+	}
+
+	if s := h.Spaces[0]; len(s.Views) != 1 || s.Views[0].Name != "Board" {
+		t.Errorf("space views = %+v", s.Views)
+	}
+	if !h.Shared.Folders[0].Shared || !h.Shared.Folders[0].Lists[0].Shared || !h.Spaces[0].Lists[1].Shared {
+		t.Error("shared folders and lists are not marked Shared")
+	}
+	if h.Spaces[0].Folders[0].Lists[0].Shared {
+		t.Error("crawled list 100 is marked Shared")
+	}
+	if n := len(h.Lists()); n != 5 {
+		t.Errorf("Lists returned %d lists, want 5", n)
+	}
+
+	b, err := json.Marshal(h)
+	if err != nil {
+		t.Fatalf("json.Marshal returned error: %v", err)
+	}
+	var back Hierarchy
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+	if back.TeamID != "1" || back.Shared.Folders[0].Folder.Name != "Partner" || back.Spaces[0].Lists[0].Tasks[0].ID != "t101" {
+		t.Errorf("JSON round trip = %s", b)
+	}
+}
+
+func TestTeamsService_CrawlHierarchy_skipAndErrors(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+	serveHierarchy(mux)
+
+	h, err := client.Teams.CrawlHierarchy(context.Background(), "1", nil)
+	if err != nil {
+		t.Fatalf("Teams.CrawlHierarchy returned error: %v", err)
+	}
+	if n := len(h.Spaces[0].Folders); n != 1 {
+		t.Errorf("crawled %d folders without Archived, want 1", n)
+	}
+	if !cmp.Equal(h.Shared.Tasks, []Task{{ID: "t9", Name: "Shared task"}, {ID: "t100", Name: "Task 100"}, {ID: "t102", Name: "Task 102"}}) {
+		t.Errorf("shared tasks = %+v", h.Shared.Tasks)
+	}
+
+	var kinds []string
+	h.Walk(func(n HierarchyNode) error {
+		kinds = append(kinds, n.Kind)
+		if n.Kind == HierarchyKindSpace || n.Kind == HierarchyKindFolder {
+			return SkipChildren
+		}
+		return nil
+	})
+	if !cmp.Equal(kinds, []string{"space", "folder", "task", "task", "task"}) {
+		t.Errorf("Walk with SkipChildren visited %q", kinds)
+	}
+
+	mux.HandleFunc("/space/9/folder", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/team/9/space", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"spaces": [{"id": "9"}]}`)
+	})
+	mux.HandleFunc("/space/9/list", func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, `{"lists": []}`) })
+	mux.HandleFunc("/team/9/shared", func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, `{"shared": {}}`) })
+	if _, err := client.Teams.CrawlHierarchy(context.Background(), "9", nil); err == nil {
+		t.Error("Teams.CrawlHierarchy returned no error for a failing request")
+	}
+	if _, err := client.Teams.CrawlHierarchy(context.Background(), "abc", nil); err == nil {
+		t.Error("Teams.CrawlHierarchy returned no error for a non-numeric team ID")
+	}
+}
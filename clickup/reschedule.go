@@ -0,0 +1,306 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Calendar decides on which days work can be scheduled.
+type Calendar interface {
+	IsWorkingDay(t time.Time) bool
+}
+
+// WorkCalendar is a Calendar skipping weekends and holidays.
+type WorkCalendar struct {
+	SkipWeekends bool
+	// Holidays are compared by date in the location of the time being checked.
+	Holidays []time.Time
+}
+
+func (c *WorkCalendar) IsWorkingDay(t time.Time) bool {
+	if c.SkipWeekends && (t.Weekday() == time.Saturday || t.Weekday() == time.Sunday) {
+		return false
+	}
+
+	y, m, d := t.Date()
+	for _, h := range c.Holidays {
+		hy, hm, hd := h.In(t.Location()).Date()
+		if y == hy && m == hm && d == hd {
+			return false
+		}
+	}
+
+	return true
+}
+
+type RescheduleOptions struct {
+	// Calendar restricts start and due dates to working days, and durations
+	// skip non-working days. If nil, every day is a working day.
+	Calendar Calendar
+}
+
+// RescheduleChange is the new start and due date of a task.
+// Nil dates are unset and left unchanged.
+type RescheduleChange struct {
+	TaskID   string
+	Name     string
+	OldStart *time.Time
+	OldDue   *time.Time
+	NewStart *time.Time
+	NewDue   *time.Time
+}
+
+// ReschedulePlan lists the tasks to move, in dependency order.
+type ReschedulePlan struct {
+	Changes []RescheduleChange
+}
+
+func (p *ReschedulePlan) String() string {
+	format := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.Format("2006-01-02 15:04")
+	}
+
+	sb := strings.Builder{}
+	for _, c := range p.Changes {
+		fmt.Fprintf(&sb, "%s %q: start %s -> %s, due %s -> %s\n",
+			c.TaskID, c.Name, format(c.OldStart), format(c.NewStart), format(c.OldDue), format(c.NewDue))
+	}
+
+	return sb.String()
+}
+
+// Reschedule plans moving taskID to newStart and newDue, either of which may
+// be nil. If only one is given, the task keeps its duration. Every task
+// depending on it directly or transitively is then moved later, keeping its
+// duration, so that it starts no earlier than the due dates of the tasks it
+// waits on. Dependent tasks are never moved earlier.
+func (g *DependencyGraph) Reschedule(taskID string, newStart, newDue *time.Time, opts *RescheduleOptions) (*ReschedulePlan, error) {
+	root := g.tasks[taskID]
+	if root == nil {
+		return nil, fmt.Errorf("task %q is not in the dependency graph", taskID)
+	}
+	if newStart == nil && newDue == nil {
+		return nil, fmt.Errorf("a new start or due date is required")
+	}
+
+	order, err := g.TopologicalOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	var cal Calendar = everyDay{}
+	if opts != nil && opts.Calendar != nil {
+		cal = opts.Calendar
+	}
+
+	affected := map[string]bool{taskID: true}
+	for queue := []string{taskID}; len(queue) > 0; queue = queue[1:] {
+		for _, d := range g.dependents[queue[0]] {
+			if !affected[d] {
+				affected[d] = true
+				queue = append(queue, d)
+			}
+		}
+	}
+
+	plan := &ReschedulePlan{}
+	finish := map[string]*time.Time{}
+	for _, id := range order {
+		if !affected[id] {
+			continue
+		}
+
+		t := g.tasks[id]
+		c := RescheduleChange{TaskID: id, Name: t.Name, OldStart: taskStartTime(t), OldDue: taskDueTime(t)}
+		c.NewStart, c.NewDue = c.OldStart, c.OldDue
+
+		if id == taskID {
+			c.NewStart, c.NewDue = planRootDates(c.OldStart, c.OldDue, newStart, newDue, cal)
+		} else {
+			var required *time.Time
+			for _, dep := range g.dependsOn[id] {
+				f := finish[dep]
+				if !affected[dep] {
+					f = taskFinishTime(g.tasks[dep])
+				}
+				if f != nil && (required == nil || f.After(*required)) {
+					required = f
+				}
+			}
+
+			if begin := taskBeginTime(t); begin != nil && required != nil && begin.Before(*required) {
+				c.NewStart, c.NewDue = shiftDates(c.OldStart, c.OldDue, *required, cal)
+			}
+		}
+
+		finish[id] = c.NewDue
+		if finish[id] == nil {
+			finish[id] = c.NewStart
+		}
+
+		if !timesEqual(c.OldStart, c.NewStart) || !timesEqual(c.OldDue, c.NewDue) {
+			plan.Changes = append(plan.Changes, c)
+		}
+	}
+
+	return plan, nil
+}
+
+// ApplyReschedulePlan updates the start and due dates of every task in plan,
+// stopping at the first error. It returns the updated tasks.
+func (s *TasksService) ApplyReschedulePlan(ctx context.Context, plan *ReschedulePlan) ([]Task, *Response, error) {
+	var (
+		updated []Task
+		resp    *Response
+	)
+	for _, c := range plan.Changes {
+		tr := &TaskUpdateRequest{}
+		if c.NewStart != nil {
+			tr.StartDate = NewDate(*c.NewStart)
+			tr.StartDateTime = true
+		}
+		if c.NewDue != nil {
+			tr.DueDate = NewDate(*c.NewDue)
+			tr.DueDateTime = true
+		}
+
+		task, r, err := s.UpdateTask(ctx, c.TaskID, nil, tr)
+		resp = r
+		if err != nil {
+			return updated, resp, err
+		}
+		updated = append(updated, *task)
+	}
+
+	return updated, resp, nil
+}
+
+type everyDay struct{}
+
+func (everyDay) IsWorkingDay(time.Time) bool { return true }
+
+// taskBeginTime is the start date of t, or its due date if it has no start date.
+func taskBeginTime(t *Task) *time.Time {
+	if s := taskStartTime(t); s != nil {
+		return s
+	}
+
+	return taskDueTime(t)
+}
+
+// taskFinishTime is the due date of t, or its start date if it has no due date.
+func taskFinishTime(t *Task) *time.Time {
+	if d := taskDueTime(t); d != nil {
+		return d
+	}
+
+	return taskStartTime(t)
+}
+
+func timesEqual(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
+
+func planRootDates(oldStart, oldDue, newStart, newDue *time.Time, cal Calendar) (*time.Time, *time.Time) {
+	switch {
+	case newStart != nil && newDue != nil:
+		return newStart, newDue
+	case newStart != nil:
+		return shiftDates(oldStart, oldDue, *newStart, cal)
+	}
+
+	if oldStart == nil || oldDue == nil {
+		return oldStart, newDue
+	}
+
+	// Move the start so the task keeps its duration before the new due date.
+	days := workingDaysBetween(*oldStart, *oldDue, cal)
+	start := addWorkingDays(*newDue, -days, cal)
+	start = time.Date(start.Year(), start.Month(), start.Day(), oldStart.Hour(), oldStart.Minute(), oldStart.Second(), oldStart.Nanosecond(), start.Location())
+
+	return &start, newDue
+}
+
+// shiftDates moves a task to begin at begin, on the next working day,
+// keeping its working time: the working days between its start and due date
+// plus the difference between their times of day. The due date is moved past
+// any non-working day it lands on.
+func shiftDates(start, due *time.Time, begin time.Time, cal Calendar) (*time.Time, *time.Time) {
+	begin = nextWorkingDay(begin, cal)
+	if start == nil {
+		return nil, &begin
+	}
+	if due == nil {
+		return &begin, nil
+	}
+
+	days := workingDaysBetween(*start, *due, cal)
+	end := addWorkingDays(begin, days, cal).Add(timeOfDay(*due) - timeOfDay(*start))
+	end = nextWorkingDay(end, cal)
+	if end.Before(begin) {
+		end = begin.Add(due.Sub(*start))
+	}
+
+	return &begin, &end
+}
+
+func nextWorkingDay(t time.Time, cal Calendar) time.Time {
+	// A year without any working day is a broken calendar; give up rather than loop forever.
+	for i := 0; i < 366 && !cal.IsWorkingDay(t); i++ {
+		t = t.AddDate(0, 0, 1)
+	}
+
+	return t
+}
+
+// workingDaysBetween counts the working days after the date of from up to the date of to.
+func workingDaysBetween(from, to time.Time, cal Calendar) int {
+	n := 0
+	d := truncateToDay(from)
+	end := truncateToDay(to)
+	for d.Before(end) {
+		d = d.AddDate(0, 0, 1)
+		if cal.IsWorkingDay(d) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// addWorkingDays moves t by n working days, backwards if n is negative.
+func addWorkingDays(t time.Time, n int, cal Calendar) time.Time {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for skipped := 0; n > 0 && skipped < 366; {
+		t = t.AddDate(0, 0, step)
+		if cal.IsWorkingDay(t) {
+			n--
+			skipped = 0
+		} else {
+			skipped++
+		}
+	}
+
+	return t
+}
+
+func timeOfDay(t time.Time) time.Duration {
+	return t.Sub(truncateToDay(t))
+}
+
+func truncateToDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,180 @@
+package clickup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// day returns the given hour of a day in May 2024, local time. May 4 and 5 are a weekend.
+func day(d int, hour int) time.Time {
+	return time.Date(2024, 5, d, hour, 0, 0, 0, time.Local)
+}
+
+func scheduledTask(id string, start, due time.Time, dependsOn ...string) Task {
+	t := depTask(id, 0, "open", dependsOn...)
+	t.Name = id
+	t.StartDate = fmt.Sprint(start.UnixMilli())
+	t.DueDate = NewDate(due)
+	return t
+}
+
+func TestDependencyGraph_Reschedule(t *testing.T) {
+	g := NewDependencyGraph([]Task{
+		scheduledTask("spec", day(1, 9), day(2, 17)),
+		scheduledTask("build", day(3, 9), day(6, 17), "spec"),
+		scheduledTask("qa", day(7, 9), day(7, 17), "build"),
+		scheduledTask("slack", day(20, 9), day(21, 17), "spec"),
+		scheduledTask("other", day(1, 9), day(1, 17)),
+	})
+
+	newDue := day(3, 17)
+	plan, err := g.Reschedule("spec", nil, &newDue, nil)
+	if err != nil {
+		t.Fatalf("Reschedule returned error: %v", err)
+	}
+
+	want := map[string][2]time.Time{
+		"spec":  {day(2, 9), day(3, 17)},
+		"build": {day(3, 17), day(7, 1)},
+	}
+	if len(plan.Changes) != len(want) {
+		t.Fatalf("Reschedule planned %d changes, want %d:\n%s", len(plan.Changes), len(want), plan)
+	}
+	for _, c := range plan.Changes {
+		w, ok := want[c.TaskID]
+		if !ok || !c.NewStart.Equal(w[0]) || !c.NewDue.Equal(w[1]) {
+			t.Errorf("change for %s = %v..%v, want %v", c.TaskID, c.NewStart, c.NewDue, w)
+		}
+	}
+}
+
+func TestDependencyGraph_RescheduleCalendar(t *testing.T) {
+	g := NewDependencyGraph([]Task{
+		scheduledTask("spec", day(1, 9), day(2, 17)),
+		scheduledTask("build", day(3, 9), day(6, 17), "spec"),
+		scheduledTask("qa", day(7, 9), day(7, 17), "build"),
+	})
+
+	cal := &WorkCalendar{SkipWeekends: true, Holidays: []time.Time{day(8, 0)}}
+	newStart := day(2, 9)
+	plan, err := g.Reschedule("spec", &newStart, nil, &RescheduleOptions{Calendar: cal})
+	if err != nil {
+		t.Fatalf("Reschedule returned error: %v", err)
+	}
+
+	// spec keeps 1 working day, so build starts Fri 17:00 and keeps its
+	// 1 working day and 8 hours over the weekend, to Tue 01:00. qa already
+	// starts after build is due.
+	want := []struct {
+		id         string
+		start, due time.Time
+	}{
+		{"spec", day(2, 9), day(3, 17)},
+		{"build", day(3, 17), day(7, 1)},
+	}
+	if len(plan.Changes) != 2 {
+		t.Fatalf("Reschedule planned %d changes, want 2:\n%s", len(plan.Changes), plan)
+	}
+	for i, c := range plan.Changes {
+		if c.TaskID != want[i].id || !c.NewStart.Equal(want[i].start) || !c.NewDue.Equal(want[i].due) {
+			t.Errorf("change %d = %s %v..%v, want %+v", i, c.TaskID, c.NewStart, c.NewDue, want[i])
+		}
+	}
+
+	newStart = day(3, 9)
+	plan, _ = g.Reschedule("spec", &newStart, nil, &RescheduleOptions{Calendar: cal})
+	// spec is due Mon and build starts Mon 17:00. Its 1 working day and 8
+	// hours end on the May 8 holiday, so build and then qa move to Thu.
+	if n := len(plan.Changes); n != 3 {
+		t.Fatalf("Reschedule planned %d changes, want 3:\n%s", n, plan)
+	}
+	if c := plan.Changes[0]; !c.NewDue.Equal(day(6, 17)) {
+		t.Errorf("spec due = %v, want %v", c.NewDue, day(6, 17))
+	}
+	if c := plan.Changes[1]; !c.NewStart.Equal(day(6, 17)) || !c.NewDue.Equal(day(9, 1)) {
+		t.Errorf("build = %v..%v, want %v..%v", c.NewStart, c.NewDue, day(6, 17), day(9, 1))
+	}
+	if c := plan.Changes[2]; !c.NewStart.Equal(day(9, 1)) || !c.NewDue.Equal(day(9, 9)) {
+		t.Errorf("qa = %v..%v, want %v..%v", c.NewStart, c.NewDue, day(9, 1), day(9, 9))
+	}
+}
+
+func TestDependencyGraph_RescheduleSameDayTask(t *testing.T) {
+	g := NewDependencyGraph([]Task{
+		scheduledTask("a", day(2, 9), day(2, 17)),
+		scheduledTask("b", day(3, 9), day(3, 17), "a"),
+	})
+
+	for _, tt := range []struct {
+		cal        Calendar
+		start, due time.Time
+	}{
+		{nil, day(3, 17), day(4, 1)},
+		// Fri 17:00 plus 8 hours is Sat, so b is due Mon.
+		{&WorkCalendar{SkipWeekends: true}, day(3, 17), day(6, 1)},
+	} {
+		newDue := day(3, 17)
+		plan, err := g.Reschedule("a", nil, &newDue, &RescheduleOptions{Calendar: tt.cal})
+		if err != nil {
+			t.Fatalf("Reschedule returned error: %v", err)
+		}
+		if len(plan.Changes) != 2 {
+			t.Fatalf("Reschedule planned %d changes, want 2:\n%s", len(plan.Changes), plan)
+		}
+		if c := plan.Changes[1]; !c.NewStart.Equal(tt.start) || !c.NewDue.Equal(tt.due) {
+			t.Errorf("b = %v..%v, want %v..%v", c.NewStart, c.NewDue, tt.start, tt.due)
+		}
+	}
+}
+
+func TestWorkCalendar_IsWorkingDay(t *testing.T) {
+	cal := &WorkCalendar{SkipWeekends: true, Holidays: []time.Time{day(1, 0)}}
+	for d, want := range map[int]bool{1: false, 2: true, 4: false, 5: false, 6: true} {
+		if got := cal.IsWorkingDay(day(d, 12)); got != want {
+			t.Errorf("IsWorkingDay(May %d) = %v, want %v", d, got, want)
+		}
+	}
+}
+
+func TestDependencyGraph_RescheduleErrors(t *testing.T) {
+	g := NewDependencyGraph([]Task{scheduledTask("a", day(1, 9), day(1, 17))})
+	due := day(2, 17)
+	if _, err := g.Reschedule("missing", nil, &due, nil); err == nil {
+		t.Errorf("Reschedule of a missing task returned nil error")
+	}
+	if _, err := g.Reschedule("a", nil, nil, nil); err == nil {
+		t.Errorf("Reschedule without dates returned nil error")
+	}
+}
+
+func TestTasksService_ApplyReschedulePlan(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/task/a/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if got, want := body["due_date"], float64(day(3, 17).UnixMilli()); got != want {
+			t.Errorf("due_date = %v, want %v", got, want)
+		}
+		if got, want := body["start_date"], float64(day(2, 9).UnixMilli()); got != want {
+			t.Errorf("start_date = %v, want %v", got, want)
+		}
+		fmt.Fprint(w, `{"id": "a"}`)
+	})
+
+	start, due := day(2, 9), day(3, 17)
+	plan := &ReschedulePlan{Changes: []RescheduleChange{{TaskID: "a", NewStart: &start, NewDue: &due}}}
+	tasks, _, err := client.Tasks.ApplyReschedulePlan(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Tasks.ApplyReschedulePlan returned error: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != "a" {
+		t.Errorf("Tasks.ApplyReschedulePlan returned %+v", tasks)
+	}
+}
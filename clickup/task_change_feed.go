@@ -0,0 +1,515 @@
+package clickup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultTaskChangeFeedOverlap is how far before its high-water mark a
+// TaskChangeFeed polls unless Overlap is set.
+const DefaultTaskChangeFeedOverlap = time.Minute
+
+// DefaultTaskChangeFeedRetention is how long a TaskChangeFeed remembers a
+// task that is not updated unless Retention is set.
+const DefaultTaskChangeFeedRetention = 30 * 24 * time.Hour
+
+// TaskSnapshot is what a TaskChangeFeed remembers of a task to detect how it
+// changed. The description, which can be large, is only kept as a hash.
+type TaskSnapshot struct {
+	Name            string                `json:"name"`
+	DescriptionHash string                `json:"description_hash,omitempty"`
+	Status          TaskStatus            `json:"status"`
+	Priority        TaskPriority          `json:"priority"`
+	Assignees       []User                `json:"assignees,omitempty"`
+	Tags            []Tag                 `json:"tags,omitempty"`
+	DueDate         *time.Time            `json:"due_date,omitempty"`
+	TimeEstimate    int64                 `json:"time_estimate"`
+	List            ListOfTaskBelonging   `json:"list"`
+	Folder          FolderOftaskBelonging `json:"folder"`
+	Space           SpaceOfTaskBelonging  `json:"space"`
+	DateUpdated     string                `json:"date_updated"`
+}
+
+func newTaskSnapshot(t *Task) TaskSnapshot {
+	s := TaskSnapshot{
+		Name:         t.Name,
+		Status:       t.Status,
+		Priority:     t.Priority,
+		Assignees:    t.Assignees,
+		Tags:         t.Tags,
+		TimeEstimate: t.TimeEstimate,
+		List:         t.List,
+		Folder:       t.Folder,
+		Space:        t.Space,
+		DateUpdated:  t.DateUpdated,
+	}
+	if t.DueDate != nil {
+		s.DueDate = t.DueDate.Time()
+	}
+	if t.Description != "" {
+		sum := sha256.Sum256([]byte(t.Description))
+		s.DescriptionHash = hex.EncodeToString(sum[:])
+	}
+
+	return s
+}
+
+// TaskChangeFeedState is the progress of a TaskChangeFeed.
+type TaskChangeFeedState struct {
+	// HighWater is the latest update time seen, by ClickUp's clock.
+	HighWater time.Time               `json:"high_water"`
+	Tasks     map[string]TaskSnapshot `json:"tasks"`
+}
+
+// TaskChangeFeedStore persists the state of a TaskChangeFeed.
+type TaskChangeFeedStore interface {
+	// LoadState returns nil when no state was saved.
+	LoadState(ctx context.Context) (*TaskChangeFeedState, error)
+	SaveState(ctx context.Context, state *TaskChangeFeedState) error
+}
+
+// FileTaskChangeFeedStore is a TaskChangeFeedStore kept in a JSON file.
+type FileTaskChangeFeedStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileTaskChangeFeedStore(path string) *FileTaskChangeFeedStore {
+	return &FileTaskChangeFeedStore{path: path}
+}
+
+func (f *FileTaskChangeFeedStore) LoadState(ctx context.Context) (*TaskChangeFeedState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state *TaskChangeFeedState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, fmt.Errorf("reading task change feed state from %s: %w", f.path, err)
+	}
+
+	return state, nil
+}
+
+func (f *FileTaskChangeFeedStore) SaveState(ctx context.Context, state *TaskChangeFeedState) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(f.path, b)
+}
+
+type TaskChangeFeedOptions struct {
+	// Interval between polls; the default is one minute.
+	Interval time.Duration
+	// Overlap is subtracted from the high-water mark for each poll, so
+	// updates that become visible late or carry a skewed timestamp are not
+	// missed. Updates seen before are skipped. The default is
+	// DefaultTaskChangeFeedOverlap.
+	Overlap time.Duration
+	// Since is where a feed without saved state starts; the default is when
+	// it first polls.
+	Since time.Time
+	// Retention is how long a task that is not updated is remembered,
+	// counted back from the high-water mark. A later update of a forgotten
+	// task is reported like one of a task not seen before. The default is
+	// DefaultTaskChangeFeedRetention.
+	Retention time.Duration
+	// Filter narrows the polled tasks, such as by space or list. The feed
+	// sets its paging, ordering and update time, and includes closed tasks
+	// and subtasks.
+	Filter *GetTasksOptions
+	// Store persists the state between runs; by default it is kept in
+	// memory.
+	Store TaskChangeFeedStore
+	// RateLimitReserve is the number of requests left to other callers. The
+	// feed waits for the rate limit to reset rather than use them.
+	RateLimitReserve int
+	// OnError is called by Run when a poll fails.
+	OnError func(err error)
+}
+
+// TaskChangeFeed polls a team for updated tasks and passes their changes to
+// a handler as the webhook events ClickUp would deliver, for environments
+// that cannot receive webhooks. The handler can be a WebhookDispatcher:
+//
+//	d := clickup.NewWebhookDispatcher()
+//	d.OnTaskStatusUpdated(...)
+//	feed := clickup.NewTaskChangeFeed(client, teamID, d.Handle, nil)
+//	err := feed.Run(ctx)
+//
+// Each task is compared with its last snapshot. Changes of status, priority,
+// assignees, due date, tags, list and time estimate become their typed
+// events; name and description changes become taskUpdated, without the
+// previous description, which the feed does not keep. Other updates,
+// and updates to tasks the feed has not seen before, become taskUpdated with
+// a date_updated history item. Tasks created since the feed started are
+// reported with taskCreated. Deletions, comments and tracked time cannot
+// be seen by polling and are not reported.
+//
+// Events carry no webhook ID. History item IDs are derived from the task and
+// its update time, so a change reported twice, for example after a handler
+// error, has the same WebhookIdempotencyKey.
+type TaskChangeFeed struct {
+	client  *Client
+	teamID  string
+	handler WebhookHandlerFunc
+	opts    TaskChangeFeedOptions
+
+	mu    sync.Mutex
+	state *TaskChangeFeedState
+	// dirty reports whether state changed since it was last saved.
+	dirty bool
+	now   func() time.Time
+}
+
+func NewTaskChangeFeed(client *Client, teamID string, handler WebhookHandlerFunc, opts *TaskChangeFeedOptions) *TaskChangeFeed {
+	f := &TaskChangeFeed{client: client, teamID: teamID, handler: handler, now: time.Now}
+	if opts != nil {
+		f.opts = *opts
+	}
+	if f.opts.Interval <= 0 {
+		f.opts.Interval = time.Minute
+	}
+	if f.opts.Overlap <= 0 {
+		f.opts.Overlap = DefaultTaskChangeFeedOverlap
+	}
+	if f.opts.Retention <= 0 {
+		f.opts.Retention = DefaultTaskChangeFeedRetention
+	}
+
+	return f
+}
+
+// Run polls every Interval until ctx is done.
+func (f *TaskChangeFeed) Run(ctx context.Context) error {
+	ticker := time.NewTicker(f.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := f.Poll(ctx); err != nil && ctx.Err() == nil && f.opts.OnError != nil {
+			f.opts.OnError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches the tasks updated since the high-water mark once and passes
+// their changes to the handler, oldest first. When the handler fails, the
+// progress up to the failed task is saved and the next poll resumes there.
+// Otherwise the state is saved when it changed.
+func (f *TaskChangeFeed) Poll(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.loadState(ctx); err != nil {
+		return err
+	}
+	since := f.state.HighWater.Add(-f.opts.Overlap)
+
+	o := GetTasksOptions{}
+	if f.opts.Filter != nil {
+		o = *f.opts.Filter
+	}
+	o.DateUpdatedGt = NewDate(since)
+	o.OrderBy = "updated"
+	o.Reverse = true
+	o.IncludeClosed = true
+	o.Subtasks = true
+
+	tasks, _, err := fetchAllTasks(ctx, &o, func(ctx context.Context, o *GetTasksOptions) ([]Task, *Response, error) {
+		if err := f.client.waitRateLimit(ctx, f.opts.RateLimitReserve); err != nil {
+			return nil, nil, err
+		}
+		return f.client.Tasks.GetFilteredTeamTasks(ctx, f.teamID, o)
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return taskUpdatedMillis(&tasks[i]) < taskUpdatedMillis(&tasks[j])
+	})
+
+	for i := range tasks {
+		t := &tasks[i]
+		updated := taskUpdatedMillis(t)
+
+		prev, seen := f.state.Tasks[t.ID]
+		if seen && updated <= unixMillis(prev.DateUpdated) {
+			continue
+		}
+
+		for _, e := range taskChangeEvents(t, prev, seen, since) {
+			if err := f.handler(ctx, e); err != nil {
+				if serr := f.saveState(ctx); serr != nil {
+					return errors.Join(err, serr)
+				}
+				return err
+			}
+		}
+
+		f.state.Tasks[t.ID] = newTaskSnapshot(t)
+		if hw := time.UnixMilli(updated); hw.After(f.state.HighWater) {
+			f.state.HighWater = hw
+		}
+		f.dirty = true
+	}
+
+	forget := f.state.HighWater.Add(-f.opts.Retention).UnixMilli()
+	for id, s := range f.state.Tasks {
+		if unixMillis(s.DateUpdated) < forget {
+			delete(f.state.Tasks, id)
+			f.dirty = true
+		}
+	}
+
+	if !f.dirty {
+		return nil
+	}
+	return f.saveState(ctx)
+}
+
+// State returns a copy of the progress of the feed.
+func (f *TaskChangeFeed) State() TaskChangeFeedState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.state == nil {
+		return TaskChangeFeedState{}
+	}
+	s := TaskChangeFeedState{HighWater: f.state.HighWater, Tasks: make(map[string]TaskSnapshot, len(f.state.Tasks))}
+	for id, t := range f.state.Tasks {
+		s.Tasks[id] = t
+	}
+
+	return s
+}
+
+func (f *TaskChangeFeed) loadState(ctx context.Context) error {
+	if f.state != nil {
+		return nil
+	}
+
+	if f.opts.Store != nil {
+		state, err := f.opts.Store.LoadState(ctx)
+		if err != nil {
+			return err
+		}
+		f.state = state
+	}
+	if f.state == nil {
+		f.state = &TaskChangeFeedState{HighWater: f.opts.Since}
+		if f.state.HighWater.IsZero() {
+			f.state.HighWater = f.now()
+		}
+		f.dirty = true
+	}
+	if f.state.Tasks == nil {
+		f.state.Tasks = map[string]TaskSnapshot{}
+	}
+
+	return nil
+}
+
+func (f *TaskChangeFeed) saveState(ctx context.Context) error {
+	if f.opts.Store == nil {
+		f.dirty = false
+		return nil
+	}
+
+	if err := f.opts.Store.SaveState(ctx, f.state); err != nil {
+		return err
+	}
+	f.dirty = false
+
+	return nil
+}
+
+func taskUpdatedMillis(t *Task) int64 {
+	return unixMillis(t.DateUpdated)
+}
+
+func unixMillis(s string) int64 {
+	if t := unixMilliString(s); t != nil {
+		return t.UnixMilli()
+	}
+
+	return 0
+}
+
+// taskChangeEvents compares t with its previous snapshot and returns the
+// webhook events describing the update.
+func taskChangeEvents(t *Task, prev TaskSnapshot, seen bool, since time.Time) []*WebhookEvent {
+	cur := newTaskSnapshot(t)
+	date := time.UnixMilli(taskUpdatedMillis(t))
+
+	var events []*WebhookEvent
+	n := 0
+	add := func(event string, changes ...SimulatedChange) {
+		items := make([]map[string]interface{}, len(changes))
+		for i, c := range changes {
+			n++
+			id := fmt.Sprintf("%s:%s:%d", t.ID, t.DateUpdated, n)
+			items[i] = simulatedHistoryItem(id, 1, date, t.List.ID, User{}, c)
+		}
+		body, err := simulatedPayload(event, "", map[string]interface{}{"task_id": t.ID}, items)
+		if err != nil {
+			return
+		}
+		e := &WebhookEvent{}
+		if err := json.Unmarshal(body, e); err != nil {
+			return
+		}
+		e.Raw = body
+		events = append(events, e)
+	}
+
+	// A taskUpdated event without a detected change carries the update time,
+	// so that its history item, and thus its idempotency key, is new for
+	// every update.
+	touched := SimulatedChange{Field: "date_updated", After: t.DateUpdated}
+
+	if !seen {
+		created := unixMilliString(t.DateCreated)
+		if created != nil && !created.Before(since) {
+			add(WebhookEventTaskCreated, SimulatedChange{Field: "task_creation", Data: map[string]string{"status_type": t.Status.Type}})
+		} else {
+			add(WebhookEventTaskUpdated, touched)
+		}
+		return events
+	}
+
+	if prev.Status.Status != cur.Status.Status {
+		add(WebhookEventTaskStatusUpdated, SimulatedChange{Field: "status", Before: webhookStatus(prev.Status), After: webhookStatus(cur.Status)})
+	}
+	if prev.Priority.Priority != cur.Priority.Priority {
+		add(WebhookEventTaskPriorityUpdated, SimulatedChange{Field: "priority", Before: webhookPriority(prev.Priority), After: webhookPriority(cur.Priority)})
+	}
+
+	var assignees []SimulatedChange
+	for _, u := range usersNotIn(cur.Assignees, prev.Assignees) {
+		assignees = append(assignees, SimulatedChange{Field: "assignee_add", After: u})
+	}
+	for _, u := range usersNotIn(prev.Assignees, cur.Assignees) {
+		assignees = append(assignees, SimulatedChange{Field: "assignee_rem", Before: u})
+	}
+	if len(assignees) > 0 {
+		add(WebhookEventTaskAssigneeUpdated, assignees...)
+	}
+
+	if !sameTime(prev.DueDate, cur.DueDate) {
+		add(WebhookEventTaskDueDateUpdated, SimulatedChange{Field: "due_date", Before: prev.DueDate, After: cur.DueDate})
+	}
+
+	var tags []SimulatedChange
+	if added := tagsNotIn(cur.Tags, prev.Tags); len(added) > 0 {
+		tags = append(tags, SimulatedChange{Field: "tag", After: added})
+	}
+	if removed := tagsNotIn(prev.Tags, cur.Tags); len(removed) > 0 {
+		tags = append(tags, SimulatedChange{Field: "tag_removed", Before: removed})
+	}
+	if len(tags) > 0 {
+		add(WebhookEventTaskTagUpdated, tags...)
+	}
+
+	if prev.List.ID != cur.List.ID {
+		add(WebhookEventTaskMoved, SimulatedChange{
+			Field:  "section_moved",
+			Before: newWebhookLocation(prev.List, prev.Folder, prev.Space),
+			After:  newWebhookLocation(cur.List, cur.Folder, cur.Space),
+		})
+	}
+	if prev.TimeEstimate != cur.TimeEstimate {
+		add(WebhookEventTaskTimeEstimateUpdated, SimulatedChange{Field: "time_estimate", Before: millisValue(prev.TimeEstimate), After: millisValue(cur.TimeEstimate)})
+	}
+
+	var updates []SimulatedChange
+	if prev.Name != cur.Name {
+		updates = append(updates, SimulatedChange{Field: "name", Before: prev.Name, After: cur.Name})
+	}
+	if prev.DescriptionHash != cur.DescriptionHash {
+		updates = append(updates, SimulatedChange{Field: "content", After: t.Description})
+	}
+	if len(updates) == 0 && len(events) == 0 {
+		updates = append(updates, touched)
+	}
+	if len(updates) > 0 {
+		add(WebhookEventTaskUpdated, updates...)
+	}
+
+	return events
+}
+
+// millisValue returns the duration of ms milliseconds, or nil for 0.
+func millisValue(ms int64) interface{} {
+	if ms == 0 {
+		return nil
+	}
+
+	return time.Duration(ms) * time.Millisecond
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
+
+func usersNotIn(users, other []User) []User {
+	ids := map[int]bool{}
+	for _, u := range other {
+		ids[u.ID] = true
+	}
+
+	var out []User
+	for _, u := range users {
+		if !ids[u.ID] {
+			out = append(out, u)
+		}
+	}
+
+	return out
+}
+
+func tagsNotIn(tags, other []Tag) []Tag {
+	names := map[string]bool{}
+	for _, t := range other {
+		names[t.Name] = true
+	}
+
+	var out []Tag
+	for _, t := range tags {
+		if !names[t.Name] {
+			out = append(out, t)
+		}
+	}
+
+	return out
+}
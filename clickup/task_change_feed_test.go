@@ -0,0 +1,273 @@
+package clickup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTaskChangeFeed_Poll(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	tasks := `[
+		{"id": "a", "name": "Old", "status": {"status": "to do"}, "date_created": "1000", "date_updated": "1704067200000", "list": {"id": "5"}},
+		{"id": "b", "name": "New", "status": {"status": "to do"}, "date_created": "1704067230000", "date_updated": "1704067230000", "list": {"id": "5"}}
+	]`
+	var queries []string
+	mux.HandleFunc("/team/1/task", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		q := r.URL.Query()
+		queries = append(queries, q.Get("date_updated_gt")+" "+q.Get("order_by")+" "+q.Get("include_closed")+" "+q.Get("subtasks"))
+		fmt.Fprintf(w, `{"tasks": %s}`, tasks)
+	})
+
+	var got []string
+	d := NewWebhookDispatcher()
+	d.OnOther(func(ctx context.Context, event string, raw json.RawMessage) error {
+		got = append(got, event)
+		return nil
+	})
+	d.OnTaskCreated(func(ctx context.Context, e TaskCreatedEvent) error {
+		got = append(got, "created "+e.TaskID)
+		return nil
+	})
+	d.OnTaskUpdated(func(ctx context.Context, e TaskUpdatedEvent) error {
+		s := "updated " + e.TaskID
+		for _, h := range e.History {
+			var before, after string
+			h.DecodeValues(&before, &after)
+			s += fmt.Sprintf(" %s %s->%s", h.Field, before, after)
+		}
+		got = append(got, s)
+		return nil
+	})
+	d.OnTaskStatusUpdated(func(ctx context.Context, e TaskStatusUpdatedEvent) error {
+		got = append(got, fmt.Sprintf("status %s %s->%s", e.TaskID, e.Before.Status, e.After.Status))
+		return nil
+	})
+	d.OnTaskAssigneeUpdated(func(ctx context.Context, e TaskAssigneeUpdatedEvent) error {
+		got = append(got, fmt.Sprintf("assignees %s +%d -%d", e.TaskID, len(e.Added), len(e.Removed)))
+		return nil
+	})
+	d.OnTaskMoved(func(ctx context.Context, e TaskMovedEvent) error {
+		got = append(got, fmt.Sprintf("moved %s %s->%s", e.TaskID, e.Before.ID, e.After.ID))
+		return nil
+	})
+	d.OnTaskDueDateUpdated(func(ctx context.Context, e TaskDueDateUpdatedEvent) error {
+		got = append(got, fmt.Sprintf("due %s %v->%v", e.TaskID, e.Before, e.After.UnixMilli()))
+		return nil
+	})
+
+	start := time.UnixMilli(1704067200000).Add(-30 * time.Second)
+	feed := NewTaskChangeFeed(client, "1", d.Handle, &TaskChangeFeedOptions{Since: start})
+	ctx := context.Background()
+
+	if err := feed.Poll(ctx); err != nil {
+		t.Fatalf("Poll returned error: %v", err)
+	}
+
+	// The same tasks again, as the overlap returns them, plus changes to b.
+	tasks = `[
+		{"id": "a", "name": "Old", "status": {"status": "to do"}, "date_created": "1000", "date_updated": "1704067200000", "list": {"id": "5"}},
+		{"id": "b", "name": "Newer", "status": {"status": "done"}, "assignees": [{"id": 7}], "due_date": "1704153600000",
+		 "date_created": "1704067230000", "date_updated": "1704067260000", "list": {"id": "6"}}
+	]`
+	if err := feed.Poll(ctx); err != nil {
+		t.Fatalf("Poll returned error: %v", err)
+	}
+
+	want := []string{
+		"updated a date_updated ->1704067200000",
+		"created b",
+		"status b to do->done",
+		"assignees b +1 -0",
+		"due b <nil>->1704153600000",
+		"moved b 5->6",
+		"updated b name New->Newer",
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+
+	wantQueries := []string{
+		"1704067110000 updated true true",
+		"1704067170000 updated true true",
+	}
+	if !cmp.Equal(queries, wantQueries) {
+		t.Errorf("queries = %q, want %q", queries, wantQueries)
+	}
+	if hw := feed.State().HighWater; !hw.Equal(time.UnixMilli(1704067260000)) {
+		t.Errorf("high-water mark = %v", hw)
+	}
+}
+
+func TestTaskChangeFeed_handlerError(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/team/1/task", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"tasks": [
+			{"id": "a", "date_created": "1704067200000", "date_updated": "1704067200000"},
+			{"id": "b", "date_created": "1704067210000", "date_updated": "1704067210000"}
+		]}`)
+	})
+
+	store := NewFileTaskChangeFeedStore(filepath.Join(t.TempDir(), "feed.json"))
+	var keys []string
+	fail := true
+	handler := func(ctx context.Context, e *WebhookEvent) error {
+		if e.TaskID == "b" && fail {
+			return errors.New("boom")
+		}
+		keys = append(keys, WebhookIdempotencyKey(e))
+		return nil
+	}
+	opts := &TaskChangeFeedOptions{Since: time.UnixMilli(1704067200000), Store: store}
+
+	ctx := context.Background()
+	if err := NewTaskChangeFeed(client, "1", handler, opts).Poll(ctx); err == nil {
+		t.Fatal("Poll returned no error for a failing handler")
+	}
+
+	state, err := store.LoadState(ctx)
+	if err != nil {
+		t.Fatalf("LoadState returned error: %v", err)
+	}
+	if _, ok := state.Tasks["a"]; !ok || len(state.Tasks) != 1 || !state.HighWater.Equal(time.UnixMilli(1704067200000)) {
+		t.Errorf("saved state = %+v", state)
+	}
+
+	// A new feed resumes from the saved state and only reports b.
+	fail = false
+	if err := NewTaskChangeFeed(client, "1", handler, opts).Poll(ctx); err != nil {
+		t.Fatalf("Poll returned error: %v", err)
+	}
+	if want := []string{":a:1704067200000:1", ":b:1704067210000:1"}; !cmp.Equal(keys, want) {
+		t.Errorf("idempotency keys = %q, want %q", keys, want)
+	}
+}
+
+func TestTaskChangeEvents_undetectedUpdate(t *testing.T) {
+	// Changes the feed cannot see, such as to custom fields, still make a
+	// new taskUpdated delivery for each update.
+	task := &Task{ID: "a", Name: "A", DateCreated: "1000", DateUpdated: "1704067200000"}
+	prev := newTaskSnapshot(task)
+
+	var keys []string
+	for _, updated := range []string{"1704067260000", "1704067320000"} {
+		task.DateUpdated = updated
+		events := taskChangeEvents(task, prev, true, time.UnixMilli(0))
+		if len(events) != 1 || events[0].Event != WebhookEventTaskUpdated {
+			t.Fatalf("taskChangeEvents returned %+v, want one taskUpdated event", events)
+		}
+		keys = append(keys, WebhookIdempotencyKey(events[0]))
+	}
+	if want := []string{":a:1704067260000:1", ":a:1704067320000:1"}; !cmp.Equal(keys, want) {
+		t.Errorf("idempotency keys = %q, want %q", keys, want)
+	}
+}
+
+func TestTaskChangeFeed_rateLimit(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/team/1/task", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"tasks": []}`)
+	})
+	client.rateLimits = Rate{Limit: 100, Remaining: 5, Reset: Timestamp{time.Now().Add(time.Hour)}}
+
+	feed := NewTaskChangeFeed(client, "1", func(context.Context, *WebhookEvent) error { return nil }, &TaskChangeFeedOptions{RateLimitReserve: 5})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := feed.Poll(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Poll returned %v, want to wait for the rate limit reset", err)
+	}
+
+	client.rateLimits.Remaining = 6
+	if err := feed.Poll(context.Background()); err != nil {
+		t.Errorf("Poll returned error: %v", err)
+	}
+}
+
+type countingFeedStore struct {
+	TaskChangeFeedStore
+	saves int
+}
+
+func (s *countingFeedStore) SaveState(ctx context.Context, state *TaskChangeFeedState) error {
+	s.saves++
+	return s.TaskChangeFeedStore.SaveState(ctx, state)
+}
+
+func TestTaskChangeFeed_state(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	tasks := `[
+		{"id": "old", "date_created": "1000", "date_updated": "1700000000000"},
+		{"id": "a", "description": "long text", "date_created": "1000", "date_updated": "1704067200000"}
+	]`
+	mux.HandleFunc("/team/1/task", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"tasks": %s}`, tasks)
+	})
+
+	var got []string
+	d := NewWebhookDispatcher()
+	d.OnTaskUpdated(func(ctx context.Context, e TaskUpdatedEvent) error {
+		for _, h := range e.History {
+			var after string
+			h.DecodeValues(nil, &after)
+			got = append(got, h.Field+" "+after)
+		}
+		return nil
+	})
+
+	store := &countingFeedStore{TaskChangeFeedStore: NewFileTaskChangeFeedStore(filepath.Join(t.TempDir(), "feed.json"))}
+	feed := NewTaskChangeFeed(client, "1", d.Handle, &TaskChangeFeedOptions{
+		Since:     time.UnixMilli(1690000000000),
+		Retention: 24 * time.Hour,
+		Store:     store,
+	})
+	ctx := context.Background()
+	if err := feed.Poll(ctx); err != nil {
+		t.Fatalf("Poll returned error: %v", err)
+	}
+
+	// Tasks not updated within the retention are forgotten, and
+	// descriptions are only kept as a hash.
+	state := feed.State()
+	if _, ok := state.Tasks["old"]; ok || len(state.Tasks) != 1 {
+		t.Errorf("remembered tasks = %v, want a only", state.Tasks)
+	}
+	if h := state.Tasks["a"].DescriptionHash; h == "" || h == "long text" {
+		t.Errorf("DescriptionHash = %q", h)
+	}
+
+	// Nothing changed, so nothing is saved.
+	tasks = `[{"id": "a", "description": "long text", "date_created": "1000", "date_updated": "1704067200000"}]`
+	if err := feed.Poll(ctx); err != nil {
+		t.Fatalf("Poll returned error: %v", err)
+	}
+	if store.saves != 1 {
+		t.Errorf("saved %d times, want 1", store.saves)
+	}
+
+	tasks = `[{"id": "a", "description": "edited", "date_created": "1000", "date_updated": "1704067260000"}]`
+	if err := feed.Poll(ctx); err != nil {
+		t.Fatalf("Poll returned error: %v", err)
+	}
+	if len(got) == 0 || got[len(got)-1] != "content edited" {
+		t.Errorf("events = %q, want the description change last", got)
+	}
+	if store.saves != 2 {
+		t.Errorf("saved %d times, want 2", store.saves)
+	}
+}
@@ -0,0 +1,805 @@
+package clickup
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tasks are mapped to and from user structs with `clickup` field tags. A tag
+// is either the name of a task attribute, such as `clickup:"assignees"`, or
+// `clickup:"cf:<name>"` for the custom field with that name. Untagged fields
+// and fields tagged `clickup:"-"` are ignored.
+//
+// Task attributes:
+//
+//	id, custom_id, name, description, text_content, markdown_description,
+//	status, priority, assignees, watchers, creator, tags, parent, url,
+//	archived, points, time_estimate, time_spent, due_date, start_date,
+//	date_created, date_updated, date_closed, list, folder, space, team_id
+//
+// Only name, description, markdown_description, status, priority, assignees,
+// tags, parent, time_estimate, due_date, start_date and custom fields are
+// encoded; the other attributes are read-only.
+//
+// Zero values are not encoded unless the tag ends in ",keepzero", such as
+// `clickup:"cf:Done,keepzero"`. A zero checkbox, number, currency, emoji,
+// rating, progress, text or labels custom field is then set to its zero
+// value, and a zero description, priority, time_estimate, due_date or
+// start_date is cleared by updates. With keepzero, an empty assignees, users,
+// votes, tasks or list_relationship field removes all IDs from the task passed
+// to EncodeTaskUpdateRequest. Nil pointers are never encoded.
+
+// TaskMappingError reports the struct field that failed to map.
+type TaskMappingError struct {
+	Struct string
+	Field  string
+	Tag    string
+	Err    error
+}
+
+func (e *TaskMappingError) Error() string {
+	return fmt.Sprintf("clickup: %s.%s (%s): %v", e.Struct, e.Field, e.Tag, e.Err)
+}
+
+func (e *TaskMappingError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeTask stores task in the struct pointed to by v. Custom fields are
+// converted with CustomField.GetValue, then to the type of the struct field:
+// drop-down values decode to the option name, labels to their names, users to
+// their IDs or usernames, currency, emoji and progress values to their number.
+// Time estimates and time spent decode to a time.Duration or to milliseconds.
+// Unset values leave the zero value.
+func DecodeTask(task *Task, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("clickup: DecodeTask needs a non-nil pointer to a struct, got %T", v)
+	}
+
+	return walkTaskTags(rv.Elem(), func(tag string, _ bool, field reflect.Value) error {
+		src, err := taskAttribute(task, tag)
+		if err != nil {
+			return err
+		}
+
+		return assignTaskValue(field, src)
+	})
+}
+
+// EncodeTaskRequest builds a TaskRequest from the struct v. fields are the
+// custom fields accessible from the list of the task, as returned by
+// GetAccessibleCustomFields. Zero values are left out.
+func EncodeTaskRequest(v interface{}, fields []CustomField) (*TaskRequest, error) {
+	e, err := encodeTask(v, nil, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TaskRequest{
+		Name:                e.name,
+		Description:         e.description,
+		MarkdownDescription: e.markdownDescription,
+		Assignees:           e.assignees,
+		Tags:                e.tags,
+		Status:              e.status,
+		Priority:            e.priority,
+		DueDate:             e.dueDate,
+		DueDateTime:         e.dueDateTime,
+		TimeEstimate:        e.timeEstimate,
+		StartDate:           e.startDate,
+		StartDateTime:       e.startDateTime,
+		Parent:              e.parent,
+		CustomFields:        e.customFields,
+	}, nil
+}
+
+// EncodeTaskUpdateRequest builds a TaskUpdateRequest from the struct v, like
+// EncodeTaskRequest. current is the task being updated, as last fetched or
+// decoded into v. Assignees and the IDs of users, votes, tasks and
+// list_relationship custom fields that current has but v lacks are removed;
+// when current is nil they are only added. markdown_description is not
+// supported by updates and is ignored.
+func EncodeTaskUpdateRequest(v interface{}, current *Task, fields []CustomField) (*TaskUpdateRequest, error) {
+	e, err := encodeTask(v, current, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	assignees := TaskAssigneeUpdateRequest{Add: e.assignees}
+	if current != nil && e.hasAssignees {
+		var ids []int
+		for _, u := range current.Assignees {
+			ids = append(ids, u.ID)
+		}
+		assignees.Add, assignees.Rem = diffIDs(e.assignees, ids)
+	}
+
+	return &TaskUpdateRequest{
+		Name:          e.name,
+		Description:   e.description,
+		Assignees:     assignees,
+		Tags:          e.tags,
+		Status:        e.status,
+		Priority:      e.priority,
+		DueDate:       e.dueDate,
+		DueDateTime:   e.dueDateTime,
+		TimeEstimate:  e.timeEstimate,
+		StartDate:     e.startDate,
+		StartDateTime: e.startDateTime,
+		Parent:        e.parent,
+		CustomFields:  e.customFields,
+		Clear:         e.clear,
+	}, nil
+}
+
+func walkTaskTags(rv reflect.Value, fn func(tag string, keepZero bool, field reflect.Value) error) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag, ok := sf.Tag.Lookup("clickup")
+		if !ok || tag == "-" || !sf.IsExported() {
+			continue
+		}
+
+		name, keepZero := strings.CutSuffix(tag, ",keepzero")
+		if err := fn(name, keepZero, rv.Field(i)); err != nil {
+			return &TaskMappingError{Struct: rt.Name(), Field: sf.Name, Tag: tag, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// findCustomFieldByName prefers an exact match over a case-insensitive one.
+func findCustomFieldByName(fields []CustomField, name string) (*CustomField, error) {
+	for _, equal := range []func(a, b string) bool{
+		func(a, b string) bool { return a == b },
+		strings.EqualFold,
+	} {
+		var found *CustomField
+		for i := range fields {
+			if !equal(fields[i].Name, name) {
+				continue
+			}
+			if found != nil {
+				return nil, fmt.Errorf("custom field name %q is ambiguous: matches fields %s and %s", name, found.ID, fields[i].ID)
+			}
+			found = &fields[i]
+		}
+		if found != nil {
+			return found, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no custom field named %q", name)
+}
+
+func taskAttribute(task *Task, tag string) (interface{}, error) {
+	if name, ok := strings.CutPrefix(tag, "cf:"); ok {
+		field, err := findCustomFieldByName(task.CustomFields, name)
+		if err != nil {
+			return nil, err
+		}
+
+		return field.GetValue(), nil
+	}
+
+	switch tag {
+	case "id":
+		return task.ID, nil
+	case "custom_id":
+		return task.CustomID, nil
+	case "name":
+		return task.Name, nil
+	case "description":
+		return task.Description, nil
+	case "text_content":
+		return task.TextContent, nil
+	case "markdown_description":
+		return task.MarkdownDescription, nil
+	case "status":
+		return task.Status.Status, nil
+	case "priority":
+		return task.Priority.Priority, nil
+	case "assignees":
+		return task.Assignees, nil
+	case "watchers":
+		return task.Watchers, nil
+	case "creator":
+		return task.Creator, nil
+	case "tags":
+		names := make([]string, len(task.Tags))
+		for i, t := range task.Tags {
+			names[i] = t.Name
+		}
+		return names, nil
+	case "parent":
+		return task.Parent, nil
+	case "url":
+		return task.URL, nil
+	case "archived":
+		return task.Archived, nil
+	case "points":
+		if p, ok := pointValue(task.Points); ok {
+			return p, nil
+		}
+		return nil, nil
+	case "time_estimate":
+		return time.Duration(task.TimeEstimate) * time.Millisecond, nil
+	case "time_spent":
+		return time.Duration(task.TimeSpent) * time.Millisecond, nil
+	case "due_date":
+		return taskDueTime(task), nil
+	case "start_date":
+		return taskStartTime(task), nil
+	case "date_created":
+		return unixMilliString(task.DateCreated), nil
+	case "date_updated":
+		return unixMilliString(task.DateUpdated), nil
+	case "date_closed":
+		return unixMilliString(task.DateClosed), nil
+	case "list":
+		return task.List.ID, nil
+	case "folder":
+		return task.Folder.ID, nil
+	case "space":
+		return task.Space.ID, nil
+	case "team_id":
+		return task.TeamID, nil
+	}
+
+	return nil, fmt.Errorf("unknown task attribute %q", tag)
+}
+
+// assignTaskValue stores src in dst, converting between the decoded ClickUp
+// types and plain Go types.
+func assignTaskValue(dst reflect.Value, src interface{}) error {
+	sv := reflect.ValueOf(src)
+	if src == nil || (sv.Kind() == reflect.Ptr && sv.IsNil()) {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	if sv.Type().AssignableTo(dst.Type()) {
+		dst.Set(sv)
+		return nil
+	}
+	if dst.Kind() == reflect.Ptr {
+		p := reflect.New(dst.Type().Elem())
+		if err := assignTaskValue(p.Elem(), src); err != nil {
+			return err
+		}
+		dst.Set(p)
+		return nil
+	}
+	if sv.Kind() == reflect.Ptr {
+		return assignTaskValue(dst, sv.Elem().Interface())
+	}
+
+	switch v := src.(type) {
+	case DropDownValue:
+		return assignTaskValue(dst, v.Value)
+	case DropDownOption:
+		return assignTaskValue(dst, v.Name)
+	case LabelsValue:
+		return assignTaskValue(dst, v.Values)
+	case LabelOption:
+		return assignTaskValue(dst, v.Label)
+	case CurrencyValue:
+		return assignTaskValue(dst, v.Value)
+	case EmojiValue:
+		return assignTaskValue(dst, v.Value)
+	case RatingValue:
+		return assignTaskValue(dst, v.Value)
+	case TimeTrackedValue:
+		return assignTaskValue(dst, v.Duration)
+	case VotesValue:
+		return assignTaskValue(dst, v.Voters)
+	case ListRelationshipItem:
+		return assignTaskValue(dst, v.ID)
+	case AutomaticProgressValue:
+		return assignTaskValue(dst, v.PercentCompleted)
+	case ManualProgressValue:
+		return assignTaskValue(dst, v.PercentCompleted)
+	case TaskValue:
+		return assignTaskValue(dst, v.ID)
+	case Tag:
+		return assignTaskValue(dst, v.Name)
+	case User:
+		if dst.Kind() == reflect.String {
+			return assignTaskValue(dst, v.Username)
+		}
+		return assignTaskValue(dst, v.ID)
+	case UserValue:
+		if dst.Kind() == reflect.String {
+			return assignTaskValue(dst, v.Username)
+		}
+		return assignTaskValue(dst, v.ID)
+	case time.Duration:
+		if dst.Kind() != reflect.String {
+			return assignTaskValue(dst, v.Milliseconds())
+		}
+	}
+
+	if sv.Kind() == reflect.Slice && dst.Kind() == reflect.Slice {
+		out := reflect.MakeSlice(dst.Type(), sv.Len(), sv.Len())
+		for i := 0; i < sv.Len(); i++ {
+			if err := assignTaskValue(out.Index(i), sv.Index(i).Interface()); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		dst.Set(out)
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if f, ok := numberOf(sv); ok {
+			if f != math.Trunc(f) || dst.OverflowInt(int64(f)) {
+				return fmt.Errorf("%v does not fit in %s", src, dst.Type())
+			}
+			dst.SetInt(int64(f))
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if f, ok := numberOf(sv); ok {
+			if f < 0 || f != math.Trunc(f) || dst.OverflowUint(uint64(f)) {
+				return fmt.Errorf("%v does not fit in %s", src, dst.Type())
+			}
+			dst.SetUint(uint64(f))
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, ok := numberOf(sv); ok {
+			dst.SetFloat(f)
+			return nil
+		}
+	case reflect.String:
+		if sv.Kind() == reflect.String {
+			dst.SetString(sv.String())
+			return nil
+		}
+	}
+
+	return fmt.Errorf("cannot decode %T into %s", src, dst.Type())
+}
+
+// numberOf returns the value of a number, or of a string holding one such as a json.Number.
+func numberOf(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		f, err := strconv.ParseFloat(v.String(), 64)
+		return f, err == nil
+	}
+
+	return 0, false
+}
+
+type encodedTask struct {
+	name                string
+	description         string
+	markdownDescription string
+	assignees           []int
+	tags                []string
+	status              string
+	priority            int
+	dueDate             *Date
+	dueDateTime         bool
+	timeEstimate        int
+	startDate           *Date
+	startDateTime       bool
+	parent              string
+	customFields        []CustomFieldInTaskRequest
+	// clear lists the attributes an update removes from the task.
+	clear []string
+	// hasAssignees reports whether assignees were encoded, even if empty.
+	hasAssignees bool
+	current      *Task
+}
+
+func encodeTask(v interface{}, current *Task, fields []CustomField) (*encodedTask, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("clickup: encoding a task needs a struct, got %T", v)
+	}
+
+	e := &encodedTask{current: current}
+	err := walkTaskTags(rv, func(tag string, keepZero bool, field reflect.Value) error {
+		for field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface {
+			if field.IsNil() {
+				return nil
+			}
+			field = field.Elem()
+		}
+		if field.IsZero() {
+			if !keepZero {
+				return nil
+			}
+			return e.setZero(tag, field.Interface(), fields)
+		}
+
+		return e.set(tag, field.Interface(), fields)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+var priorityNumbers = map[string]int{"urgent": 1, "high": 2, "normal": 3, "low": 4}
+
+func (e *encodedTask) set(tag string, v interface{}, fields []CustomField) error {
+	if name, ok := strings.CutPrefix(tag, "cf:"); ok {
+		field, err := findCustomFieldByName(fields, name)
+		if err != nil {
+			return err
+		}
+
+		value, err := customFieldValueOf(field, v)
+		if err != nil {
+			return err
+		}
+		if value, err = e.withRemovals(field, value); err != nil || value == nil {
+			return err
+		}
+
+		cf, err := NewCustomFieldInTaskRequest(field, value)
+		if err != nil {
+			return err
+		}
+		e.customFields = append(e.customFields, cf)
+		return nil
+	}
+
+	var err error
+	switch tag {
+	case "name":
+		e.name, err = encodeString(v)
+	case "description":
+		e.description, err = encodeString(v)
+	case "markdown_description":
+		e.markdownDescription, err = encodeString(v)
+	case "status":
+		e.status, err = encodeString(v)
+	case "parent":
+		e.parent, err = encodeString(v)
+	case "priority":
+		if name, ok := v.(string); ok {
+			p, ok := priorityNumbers[strings.ToLower(name)]
+			if !ok {
+				return fmt.Errorf("unknown priority %q", name)
+			}
+			e.priority = p
+			return nil
+		}
+		var p int64
+		p, err = encodeInt(v)
+		e.priority = int(p)
+	case "assignees":
+		e.assignees, err = encodeUserIDs(v)
+		e.hasAssignees = true
+	case "tags":
+		if err = assignTaskValue(reflect.ValueOf(&e.tags).Elem(), v); err != nil {
+			err = fmt.Errorf("cannot encode %T as tags", v)
+		}
+	case "time_estimate":
+		var ms int64
+		ms, err = encodeInt(v)
+		e.timeEstimate = int(ms)
+	case "due_date":
+		e.dueDate, e.dueDateTime, err = encodeDate(v)
+	case "start_date":
+		e.startDate, e.startDateTime, err = encodeDate(v)
+	default:
+		if _, err := taskAttribute(&Task{}, tag); err != nil {
+			return err
+		}
+		// Read-only attribute.
+	}
+
+	return err
+}
+
+// setZero encodes the zero value v of a field tagged keepzero.
+func (e *encodedTask) setZero(tag string, v interface{}, fields []CustomField) error {
+	if name, ok := strings.CutPrefix(tag, "cf:"); ok {
+		field, err := findCustomFieldByName(fields, name)
+		if err != nil {
+			return err
+		}
+
+		switch field.Type {
+		case "date", "drop_down", "location", "attachment":
+			return fmt.Errorf("custom field %q of type %q cannot be cleared by a task request, use RemoveCustomFieldValue", field.Name, field.Type)
+		}
+
+		return e.set(tag, v, fields)
+	}
+
+	switch tag {
+	case "description", "priority", "time_estimate", "due_date", "start_date":
+		e.clear = append(e.clear, tag)
+	case "name", "markdown_description", "status", "parent":
+		return fmt.Errorf("%s cannot be cleared", tag)
+	case "assignees":
+		e.hasAssignees = true
+	case "tags":
+		// Nothing to add.
+	default:
+		if _, err := taskAttribute(&Task{}, tag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// withRemovals replaces the IDs added to a users, votes, tasks or
+// list_relationship field with the changes from the field on the current
+// task. It returns nil when there is nothing to change.
+func (e *encodedTask) withRemovals(field *CustomField, value CustomFieldValue) (CustomFieldValue, error) {
+	var current interface{}
+	if e.current != nil {
+		for i := range e.current.CustomFields {
+			if e.current.CustomFields[i].ID == field.ID {
+				current = e.current.CustomFields[i].GetValue()
+				break
+			}
+		}
+	}
+
+	var (
+		ids []int
+		ss  []string
+	)
+	decode := func(dst interface{}) error {
+		if current == nil {
+			return nil
+		}
+		if err := assignTaskValue(reflect.ValueOf(dst).Elem(), current); err != nil {
+			return fmt.Errorf("cannot decode the current value of custom field %q: %w", field.Name, err)
+		}
+		return nil
+	}
+
+	switch v := value.(type) {
+	case UsersFieldValue:
+		if v.Rem != nil {
+			return v, nil
+		}
+		if err := decode(&ids); err != nil {
+			return nil, err
+		}
+		if v.Add, v.Rem = diffIDs(v.Add, ids); len(v.Add)+len(v.Rem) > 0 {
+			return v, nil
+		}
+	case VotesFieldValue:
+		if v.Rem != nil {
+			return v, nil
+		}
+		if err := decode(&ids); err != nil {
+			return nil, err
+		}
+		if v.Add, v.Rem = diffIDs(v.Add, ids); len(v.Add)+len(v.Rem) > 0 {
+			return v, nil
+		}
+	case TasksFieldValue:
+		if v.Rem != nil {
+			return v, nil
+		}
+		if err := decode(&ss); err != nil {
+			return nil, err
+		}
+		if v.Add, v.Rem = diffIDs(v.Add, ss); len(v.Add)+len(v.Rem) > 0 {
+			return v, nil
+		}
+	case ListRelationshipFieldValue:
+		if v.Rem != nil {
+			return v, nil
+		}
+		if err := decode(&ss); err != nil {
+			return nil, err
+		}
+		if v.Add, v.Rem = diffIDs(v.Add, ss); len(v.Add)+len(v.Rem) > 0 {
+			return v, nil
+		}
+	default:
+		return value, nil
+	}
+
+	return nil, nil
+}
+
+// diffIDs returns the IDs of want missing from have, and those of have
+// missing from want.
+func diffIDs[T comparable](want, have []T) (add, rem []T) {
+	in := func(ids []T, id T) bool {
+		for _, x := range ids {
+			if x == id {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, id := range want {
+		if !in(have, id) {
+			add = append(add, id)
+		}
+	}
+	for _, id := range have {
+		if !in(want, id) {
+			rem = append(rem, id)
+		}
+	}
+
+	return add, rem
+}
+
+func encodeString(v interface{}) (string, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.String {
+		return "", fmt.Errorf("cannot encode %T as a string", v)
+	}
+
+	return rv.String(), nil
+}
+
+// encodeInt returns integers as is and durations in milliseconds.
+func encodeInt(v interface{}) (int64, error) {
+	var n int64
+	if err := assignTaskValue(reflect.ValueOf(&n).Elem(), v); err != nil {
+		return 0, fmt.Errorf("cannot encode %T as an integer", v)
+	}
+
+	return n, nil
+}
+
+func encodeUserIDs(v interface{}) ([]int, error) {
+	var ids []int
+	if err := assignTaskValue(reflect.ValueOf(&ids).Elem(), v); err != nil {
+		return nil, fmt.Errorf("cannot encode %T as user IDs", v)
+	}
+
+	return ids, nil
+}
+
+// encodeDate also reports whether the time of day is significant.
+func encodeDate(v interface{}) (*Date, bool, error) {
+	var t time.Time
+	switch d := v.(type) {
+	case time.Time:
+		t = d
+	case Date:
+		if d.Time() == nil {
+			return nil, false, nil
+		}
+		t = *d.Time()
+	default:
+		return nil, false, fmt.Errorf("cannot encode %T as a date", v)
+	}
+
+	return NewDate(t), !t.Equal(truncateToDay(t)), nil
+}
+
+// customFieldValueOf converts v to a value for field according to its type.
+func customFieldValueOf(field *CustomField, v interface{}) (CustomFieldValue, error) {
+	if value, ok := v.(CustomFieldValue); ok {
+		return value, nil
+	}
+
+	var (
+		s   string
+		f   float64
+		n   int64
+		ss  []string
+		ids []int
+	)
+	into := func(dst interface{}) bool {
+		return assignTaskValue(reflect.ValueOf(dst).Elem(), v) == nil
+	}
+
+	switch field.Type {
+	case "url", "email", "phone", "text", "short_text":
+		if into(&s) {
+			return TextFieldValue(s), nil
+		}
+	case "number":
+		if into(&f) {
+			return NumberFieldValue(f), nil
+		}
+	case "currency":
+		if into(&f) {
+			return CurrencyFieldValue(f), nil
+		}
+	case "emoji":
+		if into(&n) {
+			return EmojiFieldValue(n), nil
+		}
+	case "rating":
+		if into(&n) {
+			return RatingFieldValue(n), nil
+		}
+	case "list_relationship":
+		if into(&ss) {
+			return ListRelationshipFieldValue{Add: ss}, nil
+		}
+	case "votes":
+		if into(&ids) {
+			return VotesFieldValue{Add: ids}, nil
+		}
+	case "manual_progress":
+		if into(&n) {
+			return ManualProgressFieldValue{Current: n}, nil
+		}
+	case "date":
+		if d, withTime, err := encodeDate(v); err == nil && d != nil {
+			return DateFieldValue{Time: *d.Time(), IncludeTime: withTime}, nil
+		}
+	case "checkbox":
+		if b, ok := v.(bool); ok {
+			return CheckboxFieldValue(b), nil
+		}
+	case "location":
+		if l, ok := v.(LocationValue); ok {
+			return LocationFieldValue{Latitude: l.Latitude, Longitude: l.Longitude, FormattedAddress: l.FormattedAddress}, nil
+		}
+	case "tasks":
+		if into(&ss) {
+			return TasksFieldValue{Add: ss}, nil
+		}
+	case "users":
+		if into(&ids) {
+			return UsersFieldValue{Add: ids}, nil
+		}
+	case "attachment":
+		if into(&ss) {
+			return AttachmentsFieldValue(ss), nil
+		}
+	case "drop_down":
+		switch d := v.(type) {
+		case DropDownValue:
+			return DropDownFieldValue{OptionID: d.Value.ID}, nil
+		case DropDownOption:
+			return DropDownFieldValue{OptionID: d.ID}, nil
+		}
+		if into(&s) {
+			return DropDownFieldValue{OptionName: s}, nil
+		}
+	case "labels":
+		var opts []LabelOption
+		switch l := v.(type) {
+		case LabelsValue:
+			opts = l.Values
+		case []LabelOption:
+			opts = l
+		}
+		if opts != nil {
+			lv := LabelsFieldValue{}
+			for _, o := range opts {
+				lv.OptionIDs = append(lv.OptionIDs, o.ID)
+			}
+			return lv, nil
+		}
+		if into(&ss) {
+			return LabelsFieldValue{OptionNames: ss}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot encode %T as custom field %q of type %q", v, field.Name, field.Type)
+}
@@ -0,0 +1,313 @@
+package clickup
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type mappedBug struct {
+	ID        string        `clickup:"id"`
+	Title     string        `clickup:"name"`
+	Status    string        `clickup:"status"`
+	Priority  string        `clickup:"priority"`
+	Owner     []User        `clickup:"assignees"`
+	OwnerIDs  []int         `clickup:"-"`
+	Tags      []string      `clickup:"tags"`
+	Due       *time.Time    `clickup:"due_date"`
+	Estimate  time.Duration `clickup:"time_estimate"`
+	Points    float64       `clickup:"points"`
+	Severity  int           `clickup:"cf:Severity"`
+	Env       string        `clickup:"cf:Environment"`
+	Platforms []string      `clickup:"cf:Platforms"`
+	Reviewers []int         `clickup:"cf:Reviewers"`
+	Budget    float64       `clickup:"cf:Budget"`
+	Notes     *string       `clickup:"cf:Notes"`
+}
+
+var mappedFields = []CustomField{
+	{ID: "cf-sev", Name: "Severity", Type: "number"},
+	dropDownField,
+	{ID: "cf-labels", Name: "Platforms", Type: "labels", TypeConfig: labelsField.TypeConfig},
+	{ID: "cf-rev", Name: "Reviewers", Type: "users"},
+	{ID: "cf-budget", Name: "Budget", Type: "currency", TypeConfig: map[string]interface{}{"precision": 2, "currency_type": "USD"}},
+	{ID: "cf-notes", Name: "Notes", Type: "text"},
+}
+
+func TestDecodeTask(t *testing.T) {
+	due := time.Date(2024, 5, 3, 0, 0, 0, 0, time.Local)
+	two := int64(2)
+
+	fields := append([]CustomField{}, mappedFields...)
+	fields[0].Value = "3"
+	fields[1].Value = float64(1)
+	fields[2].Value = []interface{}{"lbl-web"}
+	fields[3].Value = []interface{}{map[string]interface{}{"id": 7, "username": "ann"}}
+	fields[4].Value = "12.5"
+
+	task := Task{
+		ID:           "t1",
+		Name:         "Crash on login",
+		Status:       TaskStatus{Status: "open"},
+		Priority:     TaskPriority{Priority: "high"},
+		Assignees:    []User{{ID: 183, Username: "John Doe"}},
+		Tags:         []Tag{{Name: "backend"}},
+		DueDate:      NewDate(due),
+		TimeEstimate: 90 * 60 * 1000,
+		Points:       Point{IntVal: &two},
+		CustomFields: fields,
+	}
+
+	var got mappedBug
+	if err := DecodeTask(&task, &got); err != nil {
+		t.Fatalf("DecodeTask returned error: %v", err)
+	}
+
+	want := mappedBug{
+		ID:        "t1",
+		Title:     "Crash on login",
+		Status:    "open",
+		Priority:  "high",
+		Owner:     []User{{ID: 183, Username: "John Doe"}},
+		Tags:      []string{"backend"},
+		Due:       &due,
+		Estimate:  90 * time.Minute,
+		Points:    2,
+		Severity:  3,
+		Env:       "Staging",
+		Platforms: []string{"Web"},
+		Reviewers: []int{7},
+		Budget:    12.5,
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("DecodeTask = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeTask_errors(t *testing.T) {
+	task := Task{CustomFields: []CustomField{
+		{ID: "a", Name: "Severity", Type: "number", Value: "3.5"},
+		{ID: "b", Name: "Owner", Type: "text"},
+		{ID: "c", Name: "owner", Type: "text"},
+	}}
+
+	var notPointer mappedBug
+	if err := DecodeTask(&task, notPointer); err == nil {
+		t.Error("DecodeTask into a struct value returned no error")
+	}
+
+	tests := []struct {
+		v     interface{}
+		field string
+	}{
+		{&struct {
+			Severity int `clickup:"cf:Severity"`
+		}{}, "Severity"},
+		{&struct {
+			Missing string `clickup:"cf:Missing"`
+		}{}, "Missing"},
+		{&struct {
+			Owner string `clickup:"cf:OWNER"`
+		}{}, "Owner"},
+		{&struct {
+			Color string `clickup:"color"`
+		}{}, "Color"},
+		{&struct {
+			Name int `clickup:"name"`
+		}{}, "Name"},
+	}
+
+	for _, tt := range tests {
+		err := DecodeTask(&task, tt.v)
+		var me *TaskMappingError
+		if !errors.As(err, &me) {
+			t.Errorf("DecodeTask(%T) returned %v, want a TaskMappingError", tt.v, err)
+			continue
+		}
+		if me.Field != tt.field {
+			t.Errorf("DecodeTask(%T) reported field %q, want %q", tt.v, me.Field, tt.field)
+		}
+	}
+}
+
+func TestEncodeTaskRequest(t *testing.T) {
+	due := time.Date(2024, 5, 3, 0, 0, 0, 0, time.Local)
+	notes := "flaky"
+
+	bug := mappedBug{
+		ID:        "ignored",
+		Title:     "Crash on login",
+		Priority:  "urgent",
+		Owner:     []User{{ID: 183}},
+		Tags:      []string{"backend"},
+		Due:       &due,
+		Estimate:  time.Hour,
+		Severity:  3,
+		Env:       "production",
+		Platforms: []string{"iOS"},
+		Notes:     &notes,
+	}
+
+	got, err := EncodeTaskRequest(&bug, mappedFields)
+	if err != nil {
+		t.Fatalf("EncodeTaskRequest returned error: %v", err)
+	}
+
+	want := &TaskRequest{
+		Name:         "Crash on login",
+		Priority:     1,
+		Assignees:    []int{183},
+		Tags:         []string{"backend"},
+		DueDate:      NewDate(due),
+		TimeEstimate: 3600000,
+		CustomFields: []CustomFieldInTaskRequest{
+			{ID: "cf-sev", Value: float64(3)},
+			{ID: "cf-dd", Value: "opt-prod"},
+			{ID: "cf-labels", Value: []string{"lbl-ios"}},
+			{ID: "cf-notes", Value: "flaky"},
+		},
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("EncodeTaskRequest = %+v, want %+v", got, want)
+	}
+
+	update, err := EncodeTaskUpdateRequest(bug, nil, mappedFields)
+	if err != nil {
+		t.Fatalf("EncodeTaskUpdateRequest returned error: %v", err)
+	}
+	if want := []int{183}; !cmp.Equal(update.Assignees.Add, want) {
+		t.Errorf("EncodeTaskUpdateRequest assignees = %v, want %v", update.Assignees.Add, want)
+	}
+	if !cmp.Equal(update.CustomFields, want.CustomFields) {
+		t.Errorf("EncodeTaskUpdateRequest custom fields = %+v, want %+v", update.CustomFields, want.CustomFields)
+	}
+}
+
+func TestEncodeTaskUpdateRequest_removals(t *testing.T) {
+	fields := append([]CustomField{}, mappedFields...)
+	fields[3].Value = []interface{}{
+		map[string]interface{}{"id": 7, "username": "ann"},
+		map[string]interface{}{"id": 8, "username": "bob"},
+	}
+	current := &Task{
+		ID:           "t1",
+		Assignees:    []User{{ID: 183}, {ID: 9}},
+		CustomFields: fields,
+	}
+
+	bug := mappedBug{Owner: []User{{ID: 183}, {ID: 5}}, Reviewers: []int{7}}
+	got, err := EncodeTaskUpdateRequest(bug, current, mappedFields)
+	if err != nil {
+		t.Fatalf("EncodeTaskUpdateRequest returned error: %v", err)
+	}
+	if want := (TaskAssigneeUpdateRequest{Add: []int{5}, Rem: []int{9}}); !cmp.Equal(got.Assignees, want) {
+		t.Errorf("EncodeTaskUpdateRequest assignees = %+v, want %+v", got.Assignees, want)
+	}
+	want := []CustomFieldInTaskRequest{
+		{ID: "cf-rev", Value: map[string]interface{}{"add": []int{}, "rem": []int{8}}},
+	}
+	if !cmp.Equal(got.CustomFields, want) {
+		t.Errorf("EncodeTaskUpdateRequest custom fields = %+v, want %+v", got.CustomFields, want)
+	}
+
+	// Unchanged IDs and empty fields without keepzero leave the task as is.
+	got, err = EncodeTaskUpdateRequest(mappedBug{Owner: []User{{ID: 9}, {ID: 183}}}, current, mappedFields)
+	if err != nil {
+		t.Fatalf("EncodeTaskUpdateRequest returned error: %v", err)
+	}
+	if got.Assignees.Add != nil || got.Assignees.Rem != nil || got.CustomFields != nil {
+		t.Errorf("EncodeTaskUpdateRequest = %+v, want no changes", got)
+	}
+
+	cleared := struct {
+		Owner     []int `clickup:"assignees,keepzero"`
+		Reviewers []int `clickup:"cf:Reviewers,keepzero"`
+	}{}
+	got, err = EncodeTaskUpdateRequest(cleared, current, mappedFields)
+	if err != nil {
+		t.Fatalf("EncodeTaskUpdateRequest returned error: %v", err)
+	}
+	if want := (TaskAssigneeUpdateRequest{Rem: []int{183, 9}}); !cmp.Equal(got.Assignees, want) {
+		t.Errorf("EncodeTaskUpdateRequest assignees = %+v, want %+v", got.Assignees, want)
+	}
+	want = []CustomFieldInTaskRequest{
+		{ID: "cf-rev", Value: map[string]interface{}{"add": []int{}, "rem": []int{7, 8}}},
+	}
+	if !cmp.Equal(got.CustomFields, want) {
+		t.Errorf("EncodeTaskUpdateRequest custom fields = %+v, want %+v", got.CustomFields, want)
+	}
+}
+
+func TestEncodeTaskUpdateRequest_keepZero(t *testing.T) {
+	fields := append([]CustomField{{ID: "cf-done", Name: "Done", Type: "checkbox"}}, mappedFields...)
+	v := struct {
+		Done     bool          `clickup:"cf:Done,keepzero"`
+		Severity int           `clickup:"cf:Severity,keepzero"`
+		Budget   float64       `clickup:"cf:Budget"`
+		Priority int           `clickup:"priority,keepzero"`
+		Estimate time.Duration `clickup:"time_estimate,keepzero"`
+		Due      *time.Time    `clickup:"due_date,keepzero"`
+	}{}
+
+	got, err := EncodeTaskUpdateRequest(v, nil, fields)
+	if err != nil {
+		t.Fatalf("EncodeTaskUpdateRequest returned error: %v", err)
+	}
+
+	want := &TaskUpdateRequest{
+		CustomFields: []CustomFieldInTaskRequest{
+			{ID: "cf-done", Value: false},
+			{ID: "cf-sev", Value: float64(0)},
+		},
+		Clear: []string{"priority", "time_estimate"},
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("EncodeTaskUpdateRequest = %+v, want %+v", got, want)
+	}
+
+	b, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("json.Marshal returned error: %v", err)
+	}
+	const body = `{"assignees":{},"custom_fields":[{"id":"cf-done","value":false},{"id":"cf-sev","value":0}],"priority":null,"time_estimate":null}`
+	if string(b) != body {
+		t.Errorf("json.Marshal = %s, want %s", b, body)
+	}
+}
+
+func TestEncodeTaskRequest_errors(t *testing.T) {
+	tests := []struct {
+		v     interface{}
+		field string
+	}{
+		{mappedBug{Env: "development"}, "Env"},
+		{mappedBug{Priority: "critical"}, "Priority"},
+		{struct {
+			Severity string `clickup:"cf:Severity"`
+		}{"high"}, "Severity"},
+		{struct {
+			Other string `clickup:"cf:Other"`
+		}{"x"}, "Other"},
+		{struct {
+			Env string `clickup:"cf:Environment,keepzero"`
+		}{}, "Env"},
+		{struct {
+			Title string `clickup:"name,keepzero"`
+		}{}, "Title"},
+	}
+
+	for _, tt := range tests {
+		_, err := EncodeTaskRequest(tt.v, mappedFields)
+		var me *TaskMappingError
+		if !errors.As(err, &me) {
+			t.Errorf("EncodeTaskRequest(%+v) returned %v, want a TaskMappingError", tt.v, err)
+			continue
+		}
+		if me.Field != tt.field {
+			t.Errorf("EncodeTaskRequest(%+v) reported field %q, want %q", tt.v, me.Field, tt.field)
+		}
+	}
+}
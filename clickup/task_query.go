@@ -0,0 +1,1215 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// TaskQuery is a parsed task query such as
+//
+//	status in ("in progress", "review") and assignee = me and due < 7d and cf."Severity" >= 3
+//
+// A query is a boolean expression over comparisons joined with and, or and not.
+// Supported fields are status, assignee, tag, priority, name, points,
+// time_spent, time_estimate, due, start, created, updated, archived, list,
+// folder, space and custom fields written as cf."Name" or cf.Name.
+//
+// Supported operators are =, !=, <, <=, >, >=, ~ (regular expression match),
+// !~, in (...), not in (...), is null and is not null.
+//
+// Dates accept now, today, relative durations such as 7d, -2w or 12h,
+// 2006-01-02, RFC 3339 or unix milliseconds. Durations for time_spent and
+// time_estimate accept values such as 90m or 2h, or plain milliseconds.
+type TaskQuery struct {
+	root queryExpr
+}
+
+// TaskQueryEnv is the context a TaskQuery is compiled against.
+type TaskQueryEnv struct {
+	// Me is the user ID that "me" refers to. Queries using me fail to
+	// compile without it.
+	Me int
+	// Now is the reference time for relative dates. The zero value means time.Now().
+	Now time.Time
+	// CustomFields are used to resolve cf."Name" to field IDs and drop-down
+	// or label option names to option IDs, e.g. the result of GetAccessibleCustomFields.
+	CustomFields []CustomField
+	// TeamLevel compiles list, folder and space comparisons to the filters
+	// only supported by GetFilteredTeamTasks. Otherwise they are evaluated locally.
+	TeamLevel bool
+}
+
+// CompiledTaskQuery is a TaskQuery split into the part the API can evaluate,
+// Options, and the residue which is evaluated locally by Match.
+type CompiledTaskQuery struct {
+	Options GetTasksOptions
+
+	env    TaskQueryEnv
+	server []queryExpr
+	local  []queryExpr
+	// regexps are the compiled ~ and !~ patterns. They are kept here rather
+	// than in the query so one query can be compiled concurrently.
+	regexps map[*queryCmp]*regexp.Regexp
+}
+
+// ParseTaskQuery parses a query string.
+func ParseTaskQuery(s string) (*TaskQuery, error) {
+	toks, err := lexTaskQuery(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{toks: toks}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	}
+
+	return &TaskQuery{root: root}, nil
+}
+
+func (q *TaskQuery) String() string {
+	return q.root.String()
+}
+
+// Compile splits q into API filters and a local residue. Top-level
+// conjuncts which the API can express exactly are moved into Options;
+// everything else, including any or and not, is evaluated locally.
+func (q *TaskQuery) Compile(env *TaskQueryEnv) (*CompiledTaskQuery, error) {
+	cq := &CompiledTaskQuery{regexps: map[*queryCmp]*regexp.Regexp{}}
+	if env != nil {
+		cq.env = *env
+	}
+	if cq.env.Now.IsZero() {
+		cq.env.Now = time.Now()
+	}
+
+	conjuncts := flattenAnd(q.root, nil)
+	for _, e := range conjuncts {
+		// The API skips archived tasks unless asked for them, so any other
+		// use of archived than a top-level archived = false needs them.
+		if !isArchivedFalse(e) && mentionsQueryField(e, "archived") {
+			cq.Options.Archived = true
+		}
+	}
+
+	for _, e := range conjuncts {
+		if err := cq.check(e); err != nil {
+			return nil, err
+		}
+
+		pushed, err := cq.push(e)
+		if err != nil {
+			return nil, err
+		}
+		if pushed {
+			cq.server = append(cq.server, e)
+		} else {
+			cq.local = append(cq.local, e)
+		}
+	}
+
+	return cq, nil
+}
+
+// Match reports whether t satisfies the locally evaluated part of the query.
+func (cq *CompiledTaskQuery) Match(t *Task) bool {
+	for _, e := range cq.local {
+		if !cq.eval(e, t) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Explain describes which parts of the query run on the server and which run locally.
+func (cq *CompiledTaskQuery) Explain() string {
+	sb := strings.Builder{}
+	for _, e := range cq.server {
+		sb.WriteString("server: " + e.String() + "\n")
+	}
+	for _, e := range cq.local {
+		sb.WriteString("local:  " + e.String() + "\n")
+	}
+
+	return sb.String()
+}
+
+// QueryTasks fetches every page of tasks in a list matching cq.
+func (s *TasksService) QueryTasks(ctx context.Context, listID string, cq *CompiledTaskQuery) ([]Task, *Response, error) {
+	return cq.run(ctx, func(ctx context.Context, opts *GetTasksOptions) ([]Task, *Response, error) {
+		return s.GetTasks(ctx, listID, opts)
+	})
+}
+
+// QueryTeamTasks fetches every page of tasks in a team matching cq.
+// cq should be compiled with TaskQueryEnv.TeamLevel set.
+func (s *TasksService) QueryTeamTasks(ctx context.Context, teamID string, cq *CompiledTaskQuery) ([]Task, *Response, error) {
+	return cq.run(ctx, func(ctx context.Context, opts *GetTasksOptions) ([]Task, *Response, error) {
+		return s.GetFilteredTeamTasks(ctx, teamID, opts)
+	})
+}
+
+func (cq *CompiledTaskQuery) run(ctx context.Context, fetch func(context.Context, *GetTasksOptions) ([]Task, *Response, error)) ([]Task, *Response, error) {
+	tasks, resp, err := fetchAllTasks(ctx, &cq.Options, fetch)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	var matched []Task
+	for i := range tasks {
+		if cq.Match(&tasks[i]) {
+			matched = append(matched, tasks[i])
+		}
+	}
+
+	return matched, resp, nil
+}
+
+func flattenAnd(e queryExpr, dst []queryExpr) []queryExpr {
+	if a, ok := e.(*queryAnd); ok {
+		dst = flattenAnd(a.left, dst)
+		return flattenAnd(a.right, dst)
+	}
+
+	return append(dst, e)
+}
+
+// push moves e into the API options if it can be expressed there exactly.
+func (cq *CompiledTaskQuery) push(e queryExpr) (bool, error) {
+	c, ok := e.(*queryCmp)
+	if !ok {
+		return false, nil
+	}
+
+	o := &cq.Options
+	switch c.field.name {
+	case "status":
+		if o.Statuses != nil || !c.isEqOrIn() {
+			return false, nil
+		}
+		o.Statuses = c.rawValues()
+		o.IncludeClosed = true
+	case "assignee":
+		if o.Assignees != nil || !c.isEqOrIn() {
+			return false, nil
+		}
+		for _, v := range c.values {
+			if v.isKeyword("me") {
+				o.Assignees = append(o.Assignees, strconv.Itoa(cq.env.Me))
+				continue
+			}
+			if _, err := strconv.Atoi(v.text); err != nil {
+				// The API only filters by user ID.
+				o.Assignees = nil
+				return false, nil
+			}
+			o.Assignees = append(o.Assignees, v.text)
+		}
+	case "tag":
+		if o.Tags != nil || !c.isEqOrIn() {
+			return false, nil
+		}
+		o.Tags = c.rawValues()
+	case "due", "created", "updated":
+		gt, lt := &o.DueDateGt, &o.DueDateLt
+		switch c.field.name {
+		case "created":
+			gt, lt = &o.DateCreatedGt, &o.DateCreatedLt
+		case "updated":
+			gt, lt = &o.DateUpdatedGt, &o.DateUpdatedLt
+		}
+
+		if len(c.values) != 1 {
+			return false, nil
+		}
+		t, err := parseQueryTime(c.values[0], cq.env.Now)
+		if err != nil {
+			return false, err
+		}
+
+		switch c.op {
+		case "<":
+			if *lt != nil {
+				return false, nil
+			}
+			*lt = NewDate(t)
+		case "<=":
+			if *lt != nil {
+				return false, nil
+			}
+			*lt = NewDate(t.Add(time.Millisecond))
+		case ">":
+			if *gt != nil {
+				return false, nil
+			}
+			*gt = NewDate(t)
+		case ">=":
+			if *gt != nil {
+				return false, nil
+			}
+			*gt = NewDate(t.Add(-time.Millisecond))
+		default:
+			return false, nil
+		}
+	case "archived":
+		// Archived tasks are excluded unless requested. Once they are,
+		// the API returns both and they are narrowed locally.
+		return !o.Archived && isArchivedFalse(c), nil
+	case "list", "folder", "space":
+		if !cq.env.TeamLevel || !c.isEqOrIn() {
+			return false, nil
+		}
+		ids := c.rawValues()
+		for _, id := range ids {
+			if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+				// The API only filters by ID; names are matched locally.
+				return false, nil
+			}
+		}
+		switch c.field.name {
+		case "list":
+			if o.ListIds != nil {
+				return false, nil
+			}
+			o.ListIds = ids
+		case "folder":
+			if o.ProjectIds != nil {
+				return false, nil
+			}
+			o.ProjectIds = ids
+		case "space":
+			if o.SpaceIds != nil {
+				return false, nil
+			}
+			o.SpaceIds = ids
+		}
+	case "cf":
+		cf := cq.env.customField(c.field.cf)
+		if cf == nil {
+			return false, nil
+		}
+
+		f := CustomFieldInGetTasksRequest{FieldId: cf.ID}
+		switch c.op {
+		case "=":
+			f.Operator = Equals
+		case "!=":
+			f.Operator = NotEqualTo
+		case "<":
+			f.Operator = LessThan
+		case "<=":
+			f.Operator = LessThanOrEqualTo
+		case ">":
+			f.Operator = GreaterThan
+		case ">=":
+			f.Operator = GreaterThanOrEqualTo
+		case "is null":
+			f.Operator = IsNull
+		case "is not null":
+			f.Operator = IsNotNull
+		case "in":
+			f.Operator = Any
+		case "not in":
+			f.Operator = NotAny
+		default:
+			return false, nil
+		}
+
+		for _, v := range c.values {
+			id, ok := customFieldFilterValue(cf, v.text)
+			if !ok {
+				return false, nil
+			}
+			f.Value = append(f.Value, id)
+		}
+		o.CustomFields = append(o.CustomFields, f)
+	default:
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// customFieldFilterValue converts a query value into the value the API filters on.
+// Drop-down and label option names are resolved to option IDs.
+func customFieldFilterValue(cf *CustomField, v string) (string, bool) {
+	switch cf.Type {
+	case "drop_down":
+		tc := DropDownTypeConfig{}
+		if ok := getStructValue(cf.TypeConfig, &tc); !ok {
+			return "", false
+		}
+		for _, o := range tc.Options {
+			if o.Name == v || o.ID == v {
+				return o.ID, true
+			}
+		}
+
+		return "", false
+	case "labels":
+		tc := LabelsTypeConfig{}
+		if ok := getStructValue(cf.TypeConfig, &tc); !ok {
+			return "", false
+		}
+		for _, o := range tc.Options {
+			if o.Label == v || o.ID == v {
+				return o.ID, true
+			}
+		}
+
+		return "", false
+	case "number", "currency", "emoji", "text", "short_text", "url", "email", "phone", "checkbox":
+		return v, true
+	}
+
+	return "", false
+}
+
+// isArchivedFalse reports whether e is archived = false.
+func isArchivedFalse(e queryExpr) bool {
+	c, ok := e.(*queryCmp)
+	if !ok || c.field.name != "archived" || c.op != "=" {
+		return false
+	}
+	b, err := strconv.ParseBool(c.values[0].text)
+
+	return err == nil && !b
+}
+
+// mentionsQueryField reports whether any comparison in e is on field.
+func mentionsQueryField(e queryExpr, field string) bool {
+	switch e := e.(type) {
+	case *queryAnd:
+		return mentionsQueryField(e.left, field) || mentionsQueryField(e.right, field)
+	case *queryOr:
+		return mentionsQueryField(e.left, field) || mentionsQueryField(e.right, field)
+	case *queryNot:
+		return mentionsQueryField(e.expr, field)
+	case *queryCmp:
+		return e.field.name == field
+	}
+
+	return false
+}
+
+func (env *TaskQueryEnv) customField(name string) *CustomField {
+	var found *CustomField
+	for i := range env.CustomFields {
+		cf := &env.CustomFields[i]
+		if cf.ID == name {
+			return cf
+		}
+		if cf.Name == name {
+			if found != nil {
+				// Ambiguous names are matched locally by name.
+				return nil
+			}
+			found = cf
+		}
+	}
+
+	return found
+}
+
+// check validates the values of every comparison in e so that
+// local evaluation never meets a malformed value.
+func (cq *CompiledTaskQuery) check(e queryExpr) error {
+	switch e := e.(type) {
+	case *queryAnd:
+		if err := cq.check(e.left); err != nil {
+			return err
+		}
+		return cq.check(e.right)
+	case *queryOr:
+		if err := cq.check(e.left); err != nil {
+			return err
+		}
+		return cq.check(e.right)
+	case *queryNot:
+		return cq.check(e.expr)
+	case *queryCmp:
+		switch e.op {
+		case "<", "<=", ">", ">=":
+			if !orderedQueryFields[e.field.name] {
+				return fmt.Errorf("query: %s does not support %s", e.field, e.op)
+			}
+		case "~", "!~":
+			if orderedQueryFields[e.field.name] && e.field.name != "cf" {
+				return fmt.Errorf("query: %s does not support %s", e.field, e.op)
+			}
+		}
+
+		for _, v := range e.values {
+			if v.isKeyword("me") && cq.env.Me == 0 {
+				return fmt.Errorf("query: %s compares with me, but TaskQueryEnv.Me is not set", e.field)
+			}
+			switch e.field.name {
+			case "due", "start", "created", "updated":
+				if _, err := parseQueryTime(v, cq.env.Now); err != nil {
+					return err
+				}
+			case "time_spent", "time_estimate":
+				if _, err := parseQueryDuration(v.text); err != nil {
+					return err
+				}
+			case "points":
+				if _, err := strconv.ParseFloat(v.text, 64); err != nil {
+					return fmt.Errorf("query: points expects a number, got %q", v.text)
+				}
+			case "archived":
+				if _, err := strconv.ParseBool(v.text); err != nil {
+					return fmt.Errorf("query: archived expects true or false, got %q", v.text)
+				}
+			}
+			if e.op == "~" || e.op == "!~" {
+				re, err := regexp.Compile(v.text)
+				if err != nil {
+					return fmt.Errorf("query: invalid regular expression %q: %v", v.text, err)
+				}
+				cq.regexps[e] = re
+			}
+		}
+	}
+
+	return nil
+}
+
+func (cq *CompiledTaskQuery) eval(e queryExpr, t *Task) bool {
+	switch e := e.(type) {
+	case *queryAnd:
+		return cq.eval(e.left, t) && cq.eval(e.right, t)
+	case *queryOr:
+		return cq.eval(e.left, t) || cq.eval(e.right, t)
+	case *queryNot:
+		return !cq.eval(e.expr, t)
+	case *queryCmp:
+		return cq.evalCmp(e, t)
+	}
+
+	return false
+}
+
+func (cq *CompiledTaskQuery) evalCmp(c *queryCmp, t *Task) bool {
+	re := cq.regexps[c]
+	switch c.field.name {
+	case "status":
+		return c.matchStrings(re, []string{t.Status.Status}, strings.EqualFold)
+	case "tag":
+		names := make([]string, len(t.Tags))
+		for i, tag := range t.Tags {
+			names[i] = tag.Name
+		}
+		return c.matchStrings(re, names, strings.EqualFold)
+	case "assignee":
+		var keys []string
+		for _, u := range t.Assignees {
+			keys = append(keys, strconv.Itoa(u.ID), u.Username, u.Email)
+			if u.ID == cq.env.Me {
+				keys = append(keys, "me")
+			}
+		}
+		return c.matchStrings(re, keys, strings.EqualFold)
+	case "priority":
+		var p []string
+		if t.Priority.Priority != "" {
+			p = []string{t.Priority.Priority}
+		}
+		return c.matchStrings(re, p, func(a, b string) bool {
+			return strings.EqualFold(a, b) || priorityNames[b] == strings.ToLower(a)
+		})
+	case "name":
+		return c.matchStrings(re, []string{t.Name}, func(a, b string) bool { return a == b })
+	case "list":
+		return c.matchStrings(re, []string{t.List.ID, t.List.Name}, strings.EqualFold)
+	case "folder":
+		return c.matchStrings(re, []string{t.Folder.ID, t.Folder.Name}, strings.EqualFold)
+	case "space":
+		return c.matchStrings(re, []string{t.Space.ID}, strings.EqualFold)
+	case "archived":
+		return c.matchStrings(re, []string{strconv.FormatBool(t.Archived)}, strings.EqualFold)
+	case "points":
+		v, ok := pointValue(t.Points)
+		return c.matchFloat(v, ok)
+	case "time_spent":
+		return c.matchDuration(t.TimeSpent)
+	case "time_estimate":
+		return c.matchDuration(t.TimeEstimate)
+	case "due":
+		var tm *time.Time
+		if t.DueDate != nil {
+			tm = t.DueDate.Time()
+		}
+		return c.matchTime(tm, cq.env.Now)
+	case "start":
+		return c.matchTime(unixMilliString(t.StartDate), cq.env.Now)
+	case "created":
+		return c.matchTime(unixMilliString(t.DateCreated), cq.env.Now)
+	case "updated":
+		return c.matchTime(unixMilliString(t.DateUpdated), cq.env.Now)
+	case "cf":
+		return cq.evalCustomField(c, t)
+	}
+
+	return false
+}
+
+func (cq *CompiledTaskQuery) evalCustomField(c *queryCmp, t *Task) bool {
+	re := cq.regexps[c]
+	var field *CustomField
+	for i := range t.CustomFields {
+		cf := &t.CustomFields[i]
+		if cf.ID == c.field.cf || cf.Name == c.field.cf {
+			field = cf
+			break
+		}
+	}
+	// A missing field has no value, like a present but empty one.
+	var value interface{}
+	if field != nil {
+		value = field.GetValue()
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return c.matchStrings(re, nil, nil)
+	case string:
+		return c.matchStrings(re, []string{v}, func(a, b string) bool { return a == b })
+	case float64:
+		return c.matchFloat(v, true)
+	case bool:
+		return c.matchStrings(re, []string{strconv.FormatBool(v)}, strings.EqualFold)
+	case time.Time:
+		return c.matchTime(&v, cq.env.Now)
+	case CurrencyValue:
+		return c.matchFloat(v.Value, true)
+	case EmojiValue:
+		return c.matchFloat(float64(v.Value), true)
+	case RatingValue:
+		return c.matchFloat(float64(v.Value), true)
+	case TimeTrackedValue:
+		return c.matchFloat(float64(v.Duration.Milliseconds()), true)
+	case DropDownValue:
+		if v.Value.ID == "" {
+			return c.matchStrings(re, nil, nil)
+		}
+		return c.matchStrings(re, []string{v.Value.Name, v.Value.ID}, strings.EqualFold)
+	case LabelsValue:
+		var keys []string
+		for _, l := range v.Values {
+			keys = append(keys, l.Label, l.ID)
+		}
+		return c.matchStrings(re, keys, strings.EqualFold)
+	case UsersValue:
+		var keys []string
+		for _, u := range v {
+			keys = append(keys, u.ID.String(), u.Username, u.Email)
+			if u.ID.String() == strconv.Itoa(cq.env.Me) {
+				keys = append(keys, "me")
+			}
+		}
+		return c.matchStrings(re, keys, strings.EqualFold)
+	}
+
+	return c.op == "is not null"
+}
+
+var priorityNames = map[string]string{"1": "urgent", "2": "high", "3": "normal", "4": "low"}
+
+func pointValue(p Point) (float64, bool) {
+	switch {
+	case p.IntVal != nil:
+		return float64(*p.IntVal), true
+	case p.FloatVal != nil:
+		return *p.FloatVal, true
+	}
+
+	return 0, false
+}
+
+func unixMilliString(s string) *time.Time {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil
+	}
+	t := time.UnixMilli(n)
+
+	return &t
+}
+
+// parseQueryTime resolves a date value relative to now.
+func parseQueryTime(v queryValue, now time.Time) (time.Time, error) {
+	s := v.text
+	switch strings.ToLower(s) {
+	case "now":
+		return now, nil
+	case "today":
+		y, m, d := now.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, now.Location()), nil
+	}
+
+	if d, err := parseRelativeDuration(s); err == nil {
+		return now.Add(d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.UnixMilli(n), nil
+	}
+
+	return time.Time{}, fmt.Errorf("query: invalid date %q", s)
+}
+
+// parseRelativeDuration parses durations such as 7d, -2w, 12h or 30m.
+func parseRelativeDuration(s string) (time.Duration, error) {
+	if len(s) < 2 {
+		return 0, fmt.Errorf("query: invalid duration %q", s)
+	}
+
+	n, err := strconv.ParseFloat(s[:len(s)-1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("query: invalid duration %q", s)
+	}
+
+	var unit time.Duration
+	switch s[len(s)-1] {
+	case 'm':
+		unit = time.Minute
+	case 'h':
+		unit = time.Hour
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("query: invalid duration %q", s)
+	}
+
+	return time.Duration(n * float64(unit)), nil
+}
+
+// parseQueryDuration parses a duration in milliseconds, as used by time_spent and time_estimate.
+func parseQueryDuration(s string) (int64, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+
+	d, err := parseRelativeDuration(s)
+	if err != nil {
+		return 0, err
+	}
+
+	return d.Milliseconds(), nil
+}
+
+// Query AST.
+
+type queryExpr interface {
+	String() string
+}
+
+type queryAnd struct{ left, right queryExpr }
+
+type queryOr struct{ left, right queryExpr }
+
+type queryNot struct{ expr queryExpr }
+
+type queryField struct {
+	name string
+	cf   string // custom field name or ID, when name is "cf"
+}
+
+type queryValue struct {
+	text   string
+	quoted bool
+}
+
+type queryCmp struct {
+	field  queryField
+	op     string
+	values []queryValue
+}
+
+func (e *queryAnd) String() string { return e.left.String() + " and " + e.right.String() }
+
+func (e *queryOr) String() string { return "(" + e.left.String() + " or " + e.right.String() + ")" }
+
+// String parenthesizes an and beneath not, which binds tighter; or is
+// always parenthesized.
+func (e *queryNot) String() string {
+	if _, ok := e.expr.(*queryAnd); ok {
+		return "not (" + e.expr.String() + ")"
+	}
+
+	return "not " + e.expr.String()
+}
+
+func (f queryField) String() string {
+	if f.name == "cf" {
+		return "cf." + strconv.Quote(f.cf)
+	}
+
+	return f.name
+}
+
+func (v queryValue) String() string {
+	if v.quoted {
+		return strconv.Quote(v.text)
+	}
+
+	return v.text
+}
+
+func (v queryValue) isKeyword(kw string) bool {
+	return !v.quoted && strings.EqualFold(v.text, kw)
+}
+
+func (c *queryCmp) String() string {
+	switch c.op {
+	case "is null", "is not null":
+		return c.field.String() + " " + c.op
+	case "in", "not in":
+		vs := make([]string, len(c.values))
+		for i, v := range c.values {
+			vs[i] = v.String()
+		}
+		return c.field.String() + " " + c.op + " (" + strings.Join(vs, ", ") + ")"
+	}
+
+	return c.field.String() + " " + c.op + " " + c.values[0].String()
+}
+
+func (c *queryCmp) isEqOrIn() bool {
+	return c.op == "=" || c.op == "in"
+}
+
+func (c *queryCmp) rawValues() []string {
+	vs := make([]string, len(c.values))
+	for i, v := range c.values {
+		vs[i] = v.text
+	}
+
+	return vs
+}
+
+// matchStrings evaluates c against a set of values, any of which may match.
+func (c *queryCmp) matchStrings(re *regexp.Regexp, have []string, eq func(have, want string) bool) bool {
+	contains := func(want string) bool {
+		for _, h := range have {
+			if eq(h, want) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch c.op {
+	case "is null":
+		return len(have) == 0
+	case "is not null":
+		return len(have) > 0
+	case "=", "in":
+		for _, v := range c.values {
+			if contains(v.text) {
+				return true
+			}
+		}
+		return false
+	case "!=", "not in":
+		for _, v := range c.values {
+			if contains(v.text) {
+				return false
+			}
+		}
+		return true
+	case "~", "!~":
+		matched := false
+		for _, h := range have {
+			if re.MatchString(h) {
+				matched = true
+			}
+		}
+		return matched == (c.op == "~")
+	}
+
+	return false
+}
+
+func (c *queryCmp) matchFloat(have float64, ok bool) bool {
+	switch c.op {
+	case "is null":
+		return !ok
+	case "is not null":
+		return ok
+	}
+	if !ok {
+		return false
+	}
+
+	for _, v := range c.values {
+		want, err := strconv.ParseFloat(v.text, 64)
+		if err != nil {
+			return false
+		}
+		if compareOrdered(c.op, have, want) {
+			return c.op != "not in"
+		}
+	}
+
+	return c.op == "not in"
+}
+
+// matchDuration treats a zero duration as unset, as the API reports no time
+// spent or estimate as 0.
+func (c *queryCmp) matchDuration(have int64) bool {
+	switch c.op {
+	case "is null":
+		return have == 0
+	case "is not null":
+		return have != 0
+	}
+
+	for _, v := range c.values {
+		want, err := parseQueryDuration(v.text)
+		if err != nil {
+			return false
+		}
+		if compareOrdered(c.op, have, want) {
+			return c.op != "not in"
+		}
+	}
+
+	return c.op == "not in"
+}
+
+func (c *queryCmp) matchTime(have *time.Time, now time.Time) bool {
+	switch c.op {
+	case "is null":
+		return have == nil
+	case "is not null":
+		return have != nil
+	}
+	if have == nil {
+		return false
+	}
+
+	for _, v := range c.values {
+		want, err := parseQueryTime(v, now)
+		if err != nil {
+			return false
+		}
+		if compareOrdered(c.op, have.UnixMilli(), want.UnixMilli()) {
+			return c.op != "not in"
+		}
+	}
+
+	return c.op == "not in"
+}
+
+func compareOrdered[T int64 | float64](op string, a, b T) bool {
+	switch op {
+	case "=", "in", "not in":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+
+	return false
+}
+
+// Lexer and parser.
+
+type queryTokenKind int
+
+const (
+	tokEOF queryTokenKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	pos  int
+}
+
+func lexTaskQuery(s string) ([]queryToken, error) {
+	var toks []queryToken
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			toks = append(toks, queryToken{tokLParen, "(", i})
+			i++
+		case r == ')':
+			toks = append(toks, queryToken{tokRParen, ")", i})
+			i++
+		case r == ',':
+			toks = append(toks, queryToken{tokComma, ",", i})
+			i++
+		case r == '"':
+			start := i
+			sb := strings.Builder{}
+			i++
+			for ; i < len(rs) && rs[i] != '"'; i++ {
+				if rs[i] == '\\' && i+1 < len(rs) {
+					i++
+				}
+				sb.WriteRune(rs[i])
+			}
+			if i >= len(rs) {
+				return nil, fmt.Errorf("query: unterminated string at position %d", start)
+			}
+			i++
+			toks = append(toks, queryToken{tokString, sb.String(), start})
+		case strings.ContainsRune("=!<>~", r):
+			start := i
+			op := string(r)
+			if i+1 < len(rs) && (rs[i+1] == '=' || (r == '!' && rs[i+1] == '~')) {
+				op += string(rs[i+1])
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("query: unexpected '!' at position %d", start)
+			}
+			i += len([]rune(op))
+			toks = append(toks, queryToken{tokOp, op, start})
+		default:
+			start := i
+			for i < len(rs) && !unicode.IsSpace(rs[i]) && !strings.ContainsRune("(),\"=!<>~", rs[i]) {
+				i++
+			}
+			toks = append(toks, queryToken{tokWord, string(rs[start:i]), start})
+		}
+	}
+
+	return append(toks, queryToken{tokEOF, "", len(rs)}), nil
+}
+
+type queryParser struct {
+	toks []queryToken
+	pos  int
+}
+
+func (p *queryParser) peek() queryToken { return p.toks[p.pos] }
+
+func (p *queryParser) next() queryToken {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *queryParser) isKeyword(kw string) bool {
+	t := p.peek()
+	return t.kind == tokWord && strings.EqualFold(t.text, kw)
+}
+
+func (p *queryParser) errorf(t queryToken, format string, args ...interface{}) error {
+	return fmt.Errorf("query: "+format+" at position %d", append(args, t.pos)...)
+}
+
+func (p *queryParser) parseOr() (queryExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &queryOr{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &queryAnd{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseUnary() (queryExpr, error) {
+	if p.isKeyword("not") {
+		p.next()
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &queryNot{e}, nil
+	}
+
+	if p.peek().kind == tokLParen {
+		p.next()
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokRParen {
+			return nil, p.errorf(t, "expected ')'")
+		}
+		return e, nil
+	}
+
+	return p.parseComparison()
+}
+
+var queryFields = map[string]bool{
+	"status": true, "assignee": true, "tag": true, "priority": true, "name": true,
+	"points": true, "time_spent": true, "time_estimate": true,
+	"due": true, "start": true, "created": true, "updated": true,
+	"archived": true, "list": true, "folder": true, "space": true,
+}
+
+// queryOperators are the comparison operators the lexer may produce which
+// the language supports.
+var queryOperators = map[string]bool{
+	"=": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true, "~": true, "!~": true,
+}
+
+// orderedQueryFields are the fields supporting <, <=, > and >=.
+var orderedQueryFields = map[string]bool{
+	"points": true, "time_spent": true, "time_estimate": true,
+	"due": true, "start": true, "created": true, "updated": true, "cf": true,
+}
+
+func (p *queryParser) parseField() (queryField, error) {
+	t := p.next()
+	if t.kind != tokWord {
+		return queryField{}, p.errorf(t, "expected field name")
+	}
+
+	name := strings.ToLower(t.text)
+	switch {
+	case name == "cf.":
+		s := p.next()
+		if s.kind != tokString {
+			return queryField{}, p.errorf(s, "expected quoted custom field name")
+		}
+		return queryField{name: "cf", cf: s.text}, nil
+	case strings.HasPrefix(name, "cf."):
+		return queryField{name: "cf", cf: t.text[3:]}, nil
+	case queryFields[name]:
+		return queryField{name: name}, nil
+	}
+
+	return queryField{}, p.errorf(t, "unknown field %q", t.text)
+}
+
+func (p *queryParser) parseValue() (queryValue, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString:
+		return queryValue{text: t.text, quoted: true}, nil
+	case tokWord:
+		return queryValue{text: t.text}, nil
+	}
+
+	return queryValue{}, p.errorf(t, "expected value")
+}
+
+func (p *queryParser) parseComparison() (queryExpr, error) {
+	field, err := p.parseField()
+	if err != nil {
+		return nil, err
+	}
+	c := &queryCmp{field: field}
+
+	switch t := p.peek(); {
+	case t.kind == tokOp:
+		if !queryOperators[t.text] {
+			return nil, p.errorf(t, "unknown operator %q", t.text)
+		}
+		p.next()
+		c.op = t.text
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		c.values = []queryValue{v}
+	case p.isKeyword("is"):
+		p.next()
+		c.op = "is null"
+		if p.isKeyword("not") {
+			p.next()
+			c.op = "is not null"
+		}
+		if !p.isKeyword("null") {
+			return nil, p.errorf(p.peek(), "expected null")
+		}
+		p.next()
+	case p.isKeyword("in"), p.isKeyword("not"):
+		c.op = "in"
+		if p.isKeyword("not") {
+			p.next()
+			c.op = "not in"
+			if !p.isKeyword("in") {
+				return nil, p.errorf(p.peek(), "expected in")
+			}
+		}
+		p.next()
+		if t := p.next(); t.kind != tokLParen {
+			return nil, p.errorf(t, "expected '('")
+		}
+		for {
+			v, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			c.values = append(c.values, v)
+			t := p.next()
+			if t.kind == tokRParen {
+				break
+			}
+			if t.kind != tokComma {
+				return nil, p.errorf(t, "expected ',' or ')'")
+			}
+		}
+	default:
+		return nil, p.errorf(t, "expected operator after %s", field)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,363 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var taskQueryNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+var taskQueryFields = []CustomField{
+	{ID: "cf-sev", Name: "Severity", Type: "number"},
+	{
+		ID:   "cf-env",
+		Name: "Environment",
+		Type: "drop_down",
+		TypeConfig: map[string]interface{}{
+			"options": []interface{}{
+				map[string]interface{}{"id": "opt-prod", "name": "production", "orderindex": float64(0)},
+				map[string]interface{}{"id": "opt-stg", "name": "staging", "orderindex": float64(1)},
+			},
+		},
+	},
+}
+
+func TestParseTaskQuery(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`status = open`, `status = open`},
+		{`status in ("in progress", review) and assignee = me`, `status in ("in progress", review) and assignee = me`},
+		{`cf."Severity" >= 3 or not name ~ "^WIP"`, `(cf."Severity" >= 3 or not name ~ "^WIP")`},
+		{`cf.Severity is not null and tag not in (a, b)`, `cf."Severity" is not null and tag not in (a, b)`},
+		{`(due < 7d or due is null) and points > 2`, `(due < 7d or due is null) and points > 2`},
+		{`not (status = a and tag = b)`, `not (status = a and tag = b)`},
+		{`not (status = a or tag = b) and not not tag = c`, `not (status = a or tag = b) and not not tag = c`},
+	}
+
+	for _, tt := range tests {
+		q, err := ParseTaskQuery(tt.in)
+		if err != nil {
+			t.Errorf("ParseTaskQuery(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if got := q.String(); got != tt.want {
+			t.Errorf("ParseTaskQuery(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTaskQuery_StringRoundTrip(t *testing.T) {
+	for _, in := range []string{
+		`not (status = a and tag = b)`,
+		`status = a or (tag = b and (tag = c or not (tag = d and tag = e)))`,
+		`not (not status = a and (tag = b or tag = c)) and name ~ "x y"`,
+		`cf."Sev \"1\"" in (1, 2) or due is not null and not points < 3`,
+	} {
+		q, err := ParseTaskQuery(in)
+		if err != nil {
+			t.Fatalf("ParseTaskQuery(%q) returned error: %v", in, err)
+		}
+		back, err := ParseTaskQuery(q.String())
+		if err != nil {
+			t.Errorf("ParseTaskQuery(%q) returned error: %v", q.String(), err)
+			continue
+		}
+		if !cmp.Equal(back.root, q.root, cmp.AllowUnexported(queryAnd{}, queryOr{}, queryNot{}, queryCmp{}, queryField{}, queryValue{})) {
+			t.Errorf("%q printed as %q, which parses differently", in, q.String())
+		}
+	}
+}
+
+func TestParseTaskQuery_errors(t *testing.T) {
+	for _, in := range []string{
+		``,
+		`color = red`,
+		`status =`,
+		`status in (a, b`,
+		`name = "unterminated`,
+		`status = a and`,
+		`status is empty`,
+		`(status = a`,
+		`status ! a`,
+		`status == a`,
+		`name ~= a`,
+		`points >< 3`,
+	} {
+		if _, err := ParseTaskQuery(in); err == nil {
+			t.Errorf("ParseTaskQuery(%q) returned no error", in)
+		}
+	}
+}
+
+func TestTaskQuery_Compile(t *testing.T) {
+	q, err := ParseTaskQuery(`status in ("in progress","review") and assignee = me and due < 7d and cf."Severity" >= 3 and cf.Environment = production and name ~ "(?i)crash" and (tag = a or tag = b)`)
+	if err != nil {
+		t.Fatalf("ParseTaskQuery returned error: %v", err)
+	}
+
+	cq, err := q.Compile(&TaskQueryEnv{Me: 183, Now: taskQueryNow, CustomFields: taskQueryFields})
+	if err != nil {
+		t.Fatalf("Compile returned error: %v", err)
+	}
+
+	want := GetTasksOptions{
+		Statuses:      []string{"in progress", "review"},
+		IncludeClosed: true,
+		Assignees:     []string{"183"},
+		DueDateLt:     NewDate(taskQueryNow.Add(7 * 24 * time.Hour)),
+		CustomFields: CustomFieldsInGetTasksRequest{
+			{FieldId: "cf-sev", Operator: GreaterThanOrEqualTo, Value: []string{"3"}},
+			{FieldId: "cf-env", Operator: Equals, Value: []string{"opt-prod"}},
+		},
+	}
+	if !cmp.Equal(cq.Options, want) {
+		t.Errorf("Compile options = %+v, want %+v", cq.Options, want)
+	}
+
+	wantExplain := `server: status in ("in progress", "review")
+server: assignee = me
+server: due < 7d
+server: cf."Severity" >= 3
+server: cf."Environment" = production
+local:  name ~ "(?i)crash"
+local:  (tag = a or tag = b)
+`
+	if got := cq.Explain(); got != wantExplain {
+		t.Errorf("Explain = %q, want %q", got, wantExplain)
+	}
+}
+
+func TestTaskQuery_CompileTeamLevel(t *testing.T) {
+	q, _ := ParseTaskQuery(`space in (1, 2) and list = 9 and folder != 3`)
+
+	cq, err := q.Compile(&TaskQueryEnv{TeamLevel: true})
+	if err != nil {
+		t.Fatalf("Compile returned error: %v", err)
+	}
+	if want := []string{"1", "2"}; !cmp.Equal(cq.Options.SpaceIds, want) {
+		t.Errorf("SpaceIds = %v, want %v", cq.Options.SpaceIds, want)
+	}
+	if want := []string{"9"}; !cmp.Equal(cq.Options.ListIds, want) {
+		t.Errorf("ListIds = %v, want %v", cq.Options.ListIds, want)
+	}
+	if len(cq.local) != 1 || cq.local[0].String() != "folder != 3" {
+		t.Errorf("Explain = %q, want folder evaluated locally", cq.Explain())
+	}
+
+	cq, _ = q.Compile(&TaskQueryEnv{})
+	if cq.Options.SpaceIds != nil || len(cq.local) != 3 {
+		t.Errorf("list-level Compile pushed team filters: %s", cq.Explain())
+	}
+
+	q, _ = ParseTaskQuery(`list in (9, Backlog) and folder = "Q3 Roadmap"`)
+	cq, _ = q.Compile(&TaskQueryEnv{TeamLevel: true})
+	if cq.Options.ListIds != nil || cq.Options.ProjectIds != nil || len(cq.local) != 2 {
+		t.Errorf("Compile pushed list or folder names: %s", cq.Explain())
+	}
+}
+
+func TestTaskQuery_CompileArchived(t *testing.T) {
+	tests := []struct {
+		query    string
+		archived bool
+		local    int
+	}{
+		{`archived = false`, false, 0},
+		{`archived = true`, true, 1},
+		{`archived in (true)`, true, 1},
+		{`status = a or not archived = true`, true, 1},
+		{`archived = false and (archived = true or status = a)`, true, 2},
+		{`status = a`, false, 0},
+	}
+
+	for _, tt := range tests {
+		q, err := ParseTaskQuery(tt.query)
+		if err != nil {
+			t.Fatalf("ParseTaskQuery(%q) returned error: %v", tt.query, err)
+		}
+		cq, err := q.Compile(nil)
+		if err != nil {
+			t.Fatalf("Compile(%q) returned error: %v", tt.query, err)
+		}
+		if cq.Options.Archived != tt.archived || len(cq.local) != tt.local {
+			t.Errorf("Compile(%q): Archived = %v, local %q; want %v with %d local", tt.query, cq.Options.Archived, cq.Explain(), tt.archived, tt.local)
+		}
+	}
+}
+
+func TestTaskQuery_CompileErrors(t *testing.T) {
+	for _, in := range []string{
+		`due < tomorrowish`,
+		`points > many`,
+		`status > open`,
+		`points ~ "1"`,
+		`name ~ "("`,
+		`archived = maybe`,
+		`archived in (true, maybe)`,
+		`assignee = me`,
+		`cf.Owner in (7, me)`,
+	} {
+		q, err := ParseTaskQuery(in)
+		if err != nil {
+			t.Errorf("ParseTaskQuery(%q) returned error: %v", in, err)
+			continue
+		}
+		if _, err := q.Compile(&TaskQueryEnv{Now: taskQueryNow}); err == nil {
+			t.Errorf("Compile(%q) returned no error", in)
+		}
+	}
+}
+
+func TestCompiledTaskQuery_Match(t *testing.T) {
+	three := int64(3)
+	task := Task{
+		Name:         "Crash on login",
+		Status:       TaskStatus{Status: "review"},
+		Assignees:    []User{{ID: 183, Username: "John Doe"}},
+		Tags:         []Tag{{Name: "backend"}},
+		Priority:     TaskPriority{Priority: "high"},
+		DueDate:      NewDate(taskQueryNow.Add(48 * time.Hour)),
+		DateCreated:  fmt.Sprint(taskQueryNow.Add(-30 * 24 * time.Hour).UnixMilli()),
+		Points:       Point{IntVal: &three},
+		TimeSpent:    int64(90 * time.Minute / time.Millisecond),
+		TimeEstimate: int64(2 * time.Hour / time.Millisecond),
+		CustomFields: []CustomField{
+			{ID: "cf-sev", Name: "Severity", Type: "number", Value: "4"},
+			{ID: "cf-env", Name: "Environment", Type: "drop_down", TypeConfig: taskQueryFields[1].TypeConfig, Value: float64(1)},
+			{ID: "cf-empty", Name: "Empty", Type: "short_text"},
+		},
+	}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{`name ~ "(?i)^crash"`, true},
+		{`name !~ "login"`, false},
+		{`status = REVIEW or status = done`, true},
+		{`not status in (review, done)`, false},
+		{`assignee = me`, true},
+		{`assignee = "John Doe"`, true},
+		{`assignee = 7`, false},
+		{`tag != frontend`, true},
+		{`priority = 2`, true},
+		{`priority in (urgent, low)`, false},
+		{`points >= 3 and points < 5`, true},
+		{`time_spent > 1h and time_spent < 80m`, false},
+		{`time_spent <= 90m`, true},
+		{`time_estimate is not null`, true},
+		{`time_estimate is null or time_spent is null`, false},
+		{`due < 7d or due is null`, true},
+		{`created > -7d or due > 3d`, false},
+		{`start is null`, true},
+		{`cf."Severity" > 3`, true},
+		{`cf.Environment = staging or cf.Environment = production`, true},
+		{`cf.Missing is null`, true},
+		{`cf.Missing is not null`, false},
+		{`cf.Missing != 4`, true},
+		{`cf.Missing not in (1, 2)`, true},
+		{`cf.Missing = 4`, false},
+		{`cf.Empty != 4`, true},
+		{`cf.Empty is null`, true},
+	}
+
+	for _, tt := range tests {
+		q, err := ParseTaskQuery(tt.query)
+		if err != nil {
+			t.Errorf("ParseTaskQuery(%q) returned error: %v", tt.query, err)
+			continue
+		}
+
+		// Wrapping the query in or keeps every clause local.
+		q.root = &queryOr{left: q.root, right: q.root}
+		cq, err := q.Compile(&TaskQueryEnv{Me: 183, Now: taskQueryNow})
+		if err != nil {
+			t.Errorf("Compile(%q) returned error: %v", tt.query, err)
+			continue
+		}
+		if got := cq.Match(&task); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestTaskQuery_CompileConcurrently(t *testing.T) {
+	q, _ := ParseTaskQuery(`name ~ "^a" or tag !~ "b$"`)
+	task := &Task{Name: "abc"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cq, err := q.Compile(nil)
+			if err != nil {
+				t.Errorf("Compile returned error: %v", err)
+				return
+			}
+			if !cq.Match(task) {
+				t.Error("Match = false, want true")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTasksService_QueryTasks(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	pages := 0
+	mux.HandleFunc("/list/123/task", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		page := r.URL.Query().Get("page")
+		if got, want := r.URL.Query().Get("statuses[]"), "open"; got != want {
+			t.Errorf("statuses[] = %q, want %q", got, want)
+		}
+		pages++
+
+		if page == "" {
+			// A full page forces a request for the next one.
+			fmt.Fprint(w, `{"tasks": [`)
+			for i := 0; i < tasksPageSize; i++ {
+				if i > 0 {
+					fmt.Fprint(w, ",")
+				}
+				fmt.Fprintf(w, `{"id": "t%d", "name": "task %d"}`, i, i)
+			}
+			fmt.Fprint(w, `]}`)
+			return
+		}
+		fmt.Fprint(w, `{"tasks": [{"id": "last", "name": "task 7"}]}`)
+	})
+
+	q, _ := ParseTaskQuery(`status = open and name ~ "^task 7$"`)
+	cq, err := q.Compile(nil)
+	if err != nil {
+		t.Fatalf("Compile returned error: %v", err)
+	}
+
+	tasks, _, err := client.Tasks.QueryTasks(context.Background(), "123", cq)
+	if err != nil {
+		t.Fatalf("Tasks.QueryTasks returned error: %v", err)
+	}
+
+	var ids []string
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	if want := []string{"t7", "last"}; !cmp.Equal(ids, want) {
+		t.Errorf("Tasks.QueryTasks returned %v, want %v", ids, want)
+	}
+	if pages != 2 {
+		t.Errorf("Tasks.QueryTasks fetched %d pages, want 2", pages)
+	}
+}
@@ -0,0 +1,197 @@
+package clickup
+
+import (
+	"context"
+)
+
+// TaskTree is the subtask hierarchy of a set of tasks, rebuilt from Task.Parent.
+type TaskTree struct {
+	// Roots are the tasks without a parent.
+	Roots []*TaskNode
+	// Orphans are the tasks whose parent is not in the fetched set.
+	Orphans []*TaskNode
+
+	nodes map[string]*TaskNode
+}
+
+// TaskNode is a task in a TaskTree. The Total fields are rolled up over
+// the task and all of its descendants.
+type TaskNode struct {
+	Task     *Task
+	Parent   *TaskNode
+	Children []*TaskNode
+
+	TotalTimeEstimate int64
+	TotalTimeSpent    int64
+	TotalPoints       float64
+}
+
+// NewTaskTree builds a TaskTree from a flat list of tasks. Subtasks nested
+// in Task.Subtasks are included as well. Children keep the order of tasks.
+func NewTaskTree(tasks []Task) *TaskTree {
+	tree := &TaskTree{nodes: map[string]*TaskNode{}}
+
+	var order []*TaskNode
+	var add func(tasks []Task)
+	add = func(tasks []Task) {
+		for i := range tasks {
+			t := &tasks[i]
+			if _, ok := tree.nodes[t.ID]; !ok {
+				n := &TaskNode{Task: t}
+				tree.nodes[t.ID] = n
+				order = append(order, n)
+			}
+			add(t.Subtasks)
+		}
+	}
+	add(tasks)
+
+	for _, n := range order {
+		switch p, ok := tree.nodes[n.Task.Parent]; {
+		case n.Task.Parent == "":
+			tree.Roots = append(tree.Roots, n)
+		case !ok || p == n:
+			tree.Orphans = append(tree.Orphans, n)
+		default:
+			n.Parent = p
+			p.Children = append(p.Children, n)
+		}
+	}
+
+	visited := map[*TaskNode]bool{}
+	for _, n := range tree.top() {
+		n.rollup(visited)
+	}
+
+	// Tasks in a parent cycle are unreachable from the top; report them as orphans.
+	for _, n := range order {
+		if visited[n] {
+			continue
+		}
+		n.Parent.removeChild(n)
+		n.Parent = nil
+		tree.Orphans = append(tree.Orphans, n)
+		n.rollup(visited)
+	}
+
+	return tree
+}
+
+// Node returns the node of the task with the given ID, or nil.
+func (tree *TaskTree) Node(taskID string) *TaskNode {
+	return tree.nodes[taskID]
+}
+
+// Len returns the number of tasks in the tree.
+func (tree *TaskTree) Len() int {
+	return len(tree.nodes)
+}
+
+// WalkDepthFirst calls fn for every node in pre-order, starting with the
+// roots and then the orphans. Walking stops at the first error fn returns.
+func (tree *TaskTree) WalkDepthFirst(fn func(n *TaskNode, depth int) error) error {
+	var walk func(n *TaskNode, depth int) error
+	walk = func(n *TaskNode, depth int) error {
+		if err := fn(n, depth); err != nil {
+			return err
+		}
+		for _, c := range n.Children {
+			if err := walk(c, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, n := range tree.top() {
+		if err := walk(n, 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WalkBreadthFirst calls fn for every node level by level, starting with the
+// roots and the orphans. Walking stops at the first error fn returns.
+func (tree *TaskTree) WalkBreadthFirst(fn func(n *TaskNode, depth int) error) error {
+	level := tree.top()
+	for depth := 0; len(level) > 0; depth++ {
+		var next []*TaskNode
+		for _, n := range level {
+			if err := fn(n, depth); err != nil {
+				return err
+			}
+			next = append(next, n.Children...)
+		}
+		level = next
+	}
+
+	return nil
+}
+
+func (tree *TaskTree) top() []*TaskNode {
+	return append(append([]*TaskNode{}, tree.Roots...), tree.Orphans...)
+}
+
+func (n *TaskNode) removeChild(c *TaskNode) {
+	for i, child := range n.Children {
+		if child == c {
+			n.Children = append(n.Children[:i], n.Children[i+1:]...)
+			return
+		}
+	}
+}
+
+func (n *TaskNode) rollup(visited map[*TaskNode]bool) {
+	if visited[n] {
+		return
+	}
+	visited[n] = true
+
+	n.TotalTimeEstimate = n.Task.TimeEstimate
+	n.TotalTimeSpent = n.Task.TimeSpent
+	n.TotalPoints, _ = pointValue(n.Task.Points)
+	for _, c := range n.Children {
+		c.rollup(visited)
+		n.TotalTimeEstimate += c.TotalTimeEstimate
+		n.TotalTimeSpent += c.TotalTimeSpent
+		n.TotalPoints += c.TotalPoints
+	}
+}
+
+// GetTaskTree fetches a task with its subtasks and builds their hierarchy.
+// If the task is itself a subtask, it is reported as an orphan.
+func (s *TasksService) GetTaskTree(ctx context.Context, taskID string, opts *GetTaskOptions) (*TaskTree, *Response, error) {
+	o := GetTaskOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	o.IncludeSubTasks = true
+
+	task, resp, err := s.GetTask(ctx, taskID, &o)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return NewTaskTree([]Task{*task}), resp, nil
+}
+
+// GetListTaskTree fetches every page of tasks in a list including subtasks
+// and builds their hierarchy.
+func (s *TasksService) GetListTaskTree(ctx context.Context, listID string, opts *GetTasksOptions) (*TaskTree, *Response, error) {
+	o := GetTasksOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	o.Subtasks = true
+
+	tasks, resp, err := fetchAllTasks(ctx, &o, func(ctx context.Context, opts *GetTasksOptions) ([]Task, *Response, error) {
+		return s.GetTasks(ctx, listID, opts)
+	})
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return NewTaskTree(tasks), resp, nil
+}
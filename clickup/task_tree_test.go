@@ -0,0 +1,160 @@
+package clickup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func taskTreeIDs(nodes []*TaskNode) []string {
+	var ids []string
+	for _, n := range nodes {
+		ids = append(ids, n.Task.ID)
+	}
+	return ids
+}
+
+func TestNewTaskTree(t *testing.T) {
+	one, two := int64(1), int64(2)
+	tree := NewTaskTree([]Task{
+		{ID: "a", TimeEstimate: 100, TimeSpent: 10, Points: Point{IntVal: &one}},
+		{ID: "a1", Parent: "a", TimeEstimate: 50, TimeSpent: 5, Points: Point{IntVal: &two}},
+		{ID: "a2", Parent: "a", TimeEstimate: 20},
+		{ID: "a1x", Parent: "a1", TimeEstimate: 7, TimeSpent: 3},
+		{ID: "b"},
+		{ID: "lost", Parent: "elsewhere", TimeEstimate: 9},
+	})
+
+	if got, want := taskTreeIDs(tree.Roots), []string{"a", "b"}; !cmp.Equal(got, want) {
+		t.Errorf("Roots = %v, want %v", got, want)
+	}
+	if got, want := taskTreeIDs(tree.Orphans), []string{"lost"}; !cmp.Equal(got, want) {
+		t.Errorf("Orphans = %v, want %v", got, want)
+	}
+	if got, want := taskTreeIDs(tree.Node("a").Children), []string{"a1", "a2"}; !cmp.Equal(got, want) {
+		t.Errorf("Children = %v, want %v", got, want)
+	}
+	if tree.Node("a1x").Parent != tree.Node("a1") {
+		t.Errorf("a1x parent = %v, want a1", tree.Node("a1x").Parent)
+	}
+
+	a := tree.Node("a")
+	if a.TotalTimeEstimate != 177 || a.TotalTimeSpent != 18 || a.TotalPoints != 3 {
+		t.Errorf("rollup of a = %d/%d/%v, want 177/18/3", a.TotalTimeEstimate, a.TotalTimeSpent, a.TotalPoints)
+	}
+	if a1 := tree.Node("a1"); a1.TotalTimeEstimate != 57 {
+		t.Errorf("rollup of a1 = %d, want 57", a1.TotalTimeEstimate)
+	}
+	if tree.Len() != 6 {
+		t.Errorf("Len = %d, want 6", tree.Len())
+	}
+}
+
+func TestNewTaskTree_cycle(t *testing.T) {
+	tree := NewTaskTree([]Task{
+		{ID: "x", Parent: "y", TimeEstimate: 1},
+		{ID: "y", Parent: "x", TimeEstimate: 2},
+	})
+
+	if got, want := taskTreeIDs(tree.Orphans), []string{"x"}; !cmp.Equal(got, want) {
+		t.Errorf("Orphans = %v, want %v", got, want)
+	}
+	if got := tree.Node("x").TotalTimeEstimate; got != 3 {
+		t.Errorf("rollup of x = %d, want 3", got)
+	}
+}
+
+func TestTaskTree_Walk(t *testing.T) {
+	tree := NewTaskTree([]Task{
+		{ID: "a"},
+		{ID: "b"},
+		{ID: "a1", Parent: "a"},
+		{ID: "a1x", Parent: "a1"},
+		{ID: "b1", Parent: "b"},
+	})
+
+	var got []string
+	tree.WalkDepthFirst(func(n *TaskNode, depth int) error {
+		got = append(got, fmt.Sprintf("%s:%d", n.Task.ID, depth))
+		return nil
+	})
+	if want := []string{"a:0", "a1:1", "a1x:2", "b:0", "b1:1"}; !cmp.Equal(got, want) {
+		t.Errorf("WalkDepthFirst visited %v, want %v", got, want)
+	}
+
+	got = nil
+	tree.WalkBreadthFirst(func(n *TaskNode, depth int) error {
+		got = append(got, fmt.Sprintf("%s:%d", n.Task.ID, depth))
+		return nil
+	})
+	if want := []string{"a:0", "b:0", "a1:1", "b1:1", "a1x:2"}; !cmp.Equal(got, want) {
+		t.Errorf("WalkBreadthFirst visited %v, want %v", got, want)
+	}
+
+	stop := errors.New("stop")
+	n := 0
+	err := tree.WalkDepthFirst(func(*TaskNode, int) error {
+		n++
+		if n == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || n != 2 {
+		t.Errorf("WalkDepthFirst returned %v after %d nodes, want stop after 2", err, n)
+	}
+}
+
+func TestTasksService_GetTaskTree(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/task/9hv/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{"include_subtasks": "true"})
+		fmt.Fprint(w, `{
+			"id": "9hv", "time_estimate": 10,
+			"subtasks": [
+				{"id": "s1", "parent": "9hv", "time_estimate": 5},
+				{"id": "s2", "parent": "s1", "time_estimate": 1}
+			]
+		}`)
+	})
+
+	tree, _, err := client.Tasks.GetTaskTree(context.Background(), "9hv", nil)
+	if err != nil {
+		t.Fatalf("Tasks.GetTaskTree returned error: %v", err)
+	}
+	if got, want := taskTreeIDs(tree.Node("s1").Children), []string{"s2"}; !cmp.Equal(got, want) {
+		t.Errorf("Children = %v, want %v", got, want)
+	}
+	if got := tree.Node("9hv").TotalTimeEstimate; got != 16 {
+		t.Errorf("rollup = %d, want 16", got)
+	}
+}
+
+func TestTasksService_GetListTaskTree(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/list/123/task", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{"subtasks": "true"})
+		fmt.Fprint(w, `{"tasks": [{"id": "p"}, {"id": "c", "parent": "p"}, {"id": "o", "parent": "gone"}]}`)
+	})
+
+	tree, _, err := client.Tasks.GetListTaskTree(context.Background(), "123", nil)
+	if err != nil {
+		t.Fatalf("Tasks.GetListTaskTree returned error: %v", err)
+	}
+	if got, want := taskTreeIDs(tree.Roots), []string{"p"}; !cmp.Equal(got, want) {
+		t.Errorf("Roots = %v, want %v", got, want)
+	}
+	if got, want := taskTreeIDs(tree.Orphans), []string{"o"}; !cmp.Equal(got, want) {
+		t.Errorf("Orphans = %v, want %v", got, want)
+	}
+}
@@ -56,6 +56,35 @@ type TaskUpdateRequest struct {
 	CheckRequiredCustomFields bool                       `json:"check_required_custom_fields,omitempty"`
 	CustomFields              []CustomFieldInTaskRequest `json:"custom_fields,omitempty"`
 	CustomItemId              int                        `json:"custom_item_id,omitempty"` // To create a task that doesn't use a custom task type, either don't include this field in the request body, or send 'null'. To create this task as a Milestone, send a value of 1. To use a custom task type, send the custom task type ID as defined in your Workspace, such as 2.
+	// Clear lists the attributes to remove from the task: "description",
+	// "priority", "time_estimate", "due_date" or "start_date".
+	Clear []string `json:"-"`
+}
+
+func (r TaskUpdateRequest) MarshalJSON() ([]byte, error) {
+	type request TaskUpdateRequest
+	b, err := json.Marshal(request(r))
+	if err != nil || len(r.Clear) == 0 {
+		return b, err
+	}
+
+	body := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &body); err != nil {
+		return nil, err
+	}
+	for _, attr := range r.Clear {
+		switch attr {
+		case "description":
+			// The API keeps the description when it is empty.
+			body[attr] = json.RawMessage(`" "`)
+		case "priority", "time_estimate", "due_date", "start_date":
+			body[attr] = json.RawMessage("null")
+		default:
+			return nil, fmt.Errorf("clickup: cannot clear task attribute %q", attr)
+		}
+	}
+
+	return json.Marshal(body)
 }
 
 type TaskAssigneeUpdateRequest struct {
@@ -66,6 +95,9 @@ type TaskAssigneeUpdateRequest struct {
 type CustomFieldInTaskRequest struct {
 	ID    string      `json:"id"`
 	Value interface{} `json:"value"`
+	// ValueOptions are sent with some values, such as {"time": true} for
+	// dates showing the time of day.
+	ValueOptions interface{} `json:"value_options,omitempty"`
 }
 
 type Task struct {
@@ -88,6 +120,7 @@ type Task struct {
 	Checklists          []Checklist            `json:"checklists,omitempty"`
 	Tags                []Tag                  `json:"tags,omitempty"`
 	Parent              string                 `json:"parent"`
+	Subtasks            []Task                 `json:"subtasks,omitempty"` // Only populated by GetTask with IncludeSubTasks.
 	Priority            TaskPriority           `json:"priority"`
 	DueDate             *Date                  `json:"due_date,omitempty"`
 	StartDate           string                 `json:"start_date,omitempty"`
@@ -228,6 +261,11 @@ type GetTasksOptions struct {
 	DateUpdatedGt *Date                         `url:"date_updated_gt,omitempty"`
 	DateUpdatedLt *Date                         `url:"date_updated_lt,omitempty"`
 	CustomFields  CustomFieldsInGetTasksRequest `url:"custom_fields,omitempty"`
+
+	// SpaceIds, ProjectIds and ListIds are only supported by GetFilteredTeamTasks.
+	SpaceIds   []string `url:"space_ids[],omitempty"`
+	ProjectIds []string `url:"project_ids[],omitempty"`
+	ListIds    []string `url:"list_ids[],omitempty"`
 }
 
 // CustomFieldsInGetTasksRequest is used to filter tasks using Custom Fields for GetTasks
@@ -375,6 +413,31 @@ func (s *TasksService) GetTasks(ctx context.Context, listID string, opts *GetTas
 	return gtr.Tasks, resp, nil
 }
 
+// tasksPageSize is the number of tasks returned per page by GetTasks and GetFilteredTeamTasks.
+const tasksPageSize = 100
+
+// fetchAllTasks calls fetch for every page of tasks until a page is not full.
+func fetchAllTasks(ctx context.Context, opts *GetTasksOptions, fetch func(context.Context, *GetTasksOptions) ([]Task, *Response, error)) ([]Task, *Response, error) {
+	o := GetTasksOptions{}
+	if opts != nil {
+		o = *opts
+	}
+
+	var all []Task
+	for page := 0; ; page++ {
+		o.Page = page
+		tasks, resp, err := fetch(ctx, &o)
+		if err != nil {
+			return nil, resp, err
+		}
+		all = append(all, tasks...)
+
+		if len(tasks) < tasksPageSize {
+			return all, resp, nil
+		}
+	}
+}
+
 func (s *TasksService) GetTask(ctx context.Context, taskID string, opts *GetTaskOptions) (*Task, *Response, error) {
 	u := fmt.Sprintf("task/%v/", taskID)
 	u, err := addOptions(u, opts)
@@ -191,3 +191,18 @@ func TestUrlEncodeCustomFieldsInGetTasksRequest(t *testing.T) {
 		t.Errorf("addOptions returned %+v, want %+v", options, want)
 	}
 }
+
+func TestUrlEncodeDatesInGetTasksRequest(t *testing.T) {
+	opts := GetTasksOptions{
+		DateUpdatedGt: NewDateWithUnixTime(1704067200000),
+		DueDateLt:     NullDate(),
+	}
+	got, err := addOptions("https://www.example.org/", opts)
+	if err != nil {
+		t.Errorf("expected no error but got error: %v", err)
+	}
+
+	if want := "https://www.example.org/?date_updated_gt=1704067200000"; got != want {
+		t.Errorf("addOptions returned %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,429 @@
+package clickup
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CloudEventsSpecVersion is the CloudEvents version of CloudEvent.
+const CloudEventsSpecVersion = "1.0"
+
+// DefaultCloudEventTypePrefix is prepended to the ClickUp event name to form
+// the type of a CloudEvent unless TypePrefix is set, as in
+// "com.clickup.webhook.taskCreated".
+const DefaultCloudEventTypePrefix = "com.clickup.webhook."
+
+// CloudEvent content types for HTTP.
+const (
+	CloudEventContentType      = "application/cloudevents+json"
+	CloudEventBatchContentType = "application/cloudevents-batch+json"
+)
+
+// cloudEventSourceBase is where sources point to, as the URLs of the team,
+// space, folder or list in the ClickUp app.
+const cloudEventSourceBase = "https://app.clickup.com"
+
+// CloudEvent is a CloudEvents 1.0 event.
+type CloudEvent struct {
+	SpecVersion     string
+	ID              string
+	Source          string
+	Type            string
+	Subject         string
+	Time            *time.Time
+	DataContentType string
+	Data            json.RawMessage
+	// Extensions holds other context attributes by name. NewWebhookCloudEvent
+	// sets clickupwebhookid.
+	Extensions map[string]string
+}
+
+var cloudEventAttributes = map[string]bool{
+	"specversion": true, "id": true, "source": true, "type": true, "subject": true,
+	"time": true, "datacontenttype": true, "data": true, "data_base64": true,
+}
+
+// MarshalJSON encodes the event in the structured JSON format.
+func (ce CloudEvent) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{}
+	for k, v := range ce.Extensions {
+		m[k] = v
+	}
+	m["specversion"] = ce.SpecVersion
+	m["id"] = ce.ID
+	m["source"] = ce.Source
+	m["type"] = ce.Type
+	if ce.Subject != "" {
+		m["subject"] = ce.Subject
+	}
+	if ce.Time != nil {
+		m["time"] = ce.Time.Format(time.RFC3339Nano)
+	}
+	if ce.DataContentType != "" {
+		m["datacontenttype"] = ce.DataContentType
+	}
+	if len(ce.Data) > 0 {
+		if isJSONContentType(ce.DataContentType) && json.Valid(ce.Data) {
+			m["data"] = ce.Data
+		} else {
+			m["data_base64"] = base64.StdEncoding.EncodeToString(ce.Data)
+		}
+	}
+
+	return json.Marshal(m)
+}
+
+func (ce *CloudEvent) UnmarshalJSON(b []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+
+	*ce = CloudEvent{}
+	for k, raw := range m {
+		var s string
+		if cloudEventAttributes[k] && k != "data" {
+			if err := json.Unmarshal(raw, &s); err != nil {
+				return fmt.Errorf("cloudevent attribute %s: %w", k, err)
+			}
+		}
+
+		switch k {
+		case "specversion":
+			ce.SpecVersion = s
+		case "id":
+			ce.ID = s
+		case "source":
+			ce.Source = s
+		case "type":
+			ce.Type = s
+		case "subject":
+			ce.Subject = s
+		case "time":
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return fmt.Errorf("cloudevent attribute time: %w", err)
+			}
+			ce.Time = &t
+		case "datacontenttype":
+			ce.DataContentType = s
+		case "data":
+			ce.Data = raw
+		case "data_base64":
+			data, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return fmt.Errorf("cloudevent attribute data_base64: %w", err)
+			}
+			ce.Data = data
+		default:
+			if ce.Extensions == nil {
+				ce.Extensions = map[string]string{}
+			}
+			if err := json.Unmarshal(raw, &s); err != nil {
+				// Extensions may be numbers or booleans in JSON.
+				s = string(raw)
+			}
+			ce.Extensions[k] = s
+		}
+	}
+
+	return ce.validate()
+}
+
+func (ce *CloudEvent) validate() error {
+	switch {
+	case ce.SpecVersion != CloudEventsSpecVersion:
+		return fmt.Errorf("unsupported cloudevents specversion %q", ce.SpecVersion)
+	case ce.ID == "" || ce.Source == "" || ce.Type == "":
+		return errors.New("cloudevent without id, source or type")
+	}
+
+	return nil
+}
+
+type CloudEventOptions struct {
+	// TeamID is the workspace the deliveries come from. Deliveries do not
+	// carry it, so without it sources have no team.
+	TeamID string
+	// TypePrefix is prepended to the event name; the default is
+	// DefaultCloudEventTypePrefix. It must end in ".", so that
+	// CloudEvent.WebhookEvent can find the event name in the type.
+	TypePrefix string
+}
+
+// NewWebhookCloudEvent converts a webhook delivery to a CloudEvent. Its
+// data is the delivery as received, so typed events can be recovered with
+// WebhookEvent and ParseWebhookEvent.
+//
+// The type is the event name after TypePrefix. The source is the app URL of
+// the most specific location the delivery names, list, folder, space or
+// team, and the subject is the task ID. The ID is WebhookIdempotencyKey, so
+// redeliveries can be deduplicated, and the time is that of the latest
+// history item.
+func NewWebhookCloudEvent(e *WebhookEvent, opts *CloudEventOptions) (*CloudEvent, error) {
+	o := CloudEventOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.TypePrefix == "" {
+		o.TypePrefix = DefaultCloudEventTypePrefix
+	}
+	if !strings.HasSuffix(o.TypePrefix, ".") {
+		return nil, fmt.Errorf("cloudevent type prefix %q does not end in \".\"", o.TypePrefix)
+	}
+	if e.Event == "" {
+		return nil, errors.New("webhook delivery without an event")
+	}
+
+	data := e.Raw
+	if len(data) == 0 {
+		var err error
+		if data, err = json.Marshal(e); err != nil {
+			return nil, err
+		}
+	}
+
+	ce := &CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              WebhookIdempotencyKey(e),
+		Source:          webhookCloudEventSource(o.TeamID, newWebhookEventLocation(e)),
+		Type:            o.TypePrefix + e.Event,
+		Subject:         e.TaskID,
+		DataContentType: "application/json",
+		Data:            data,
+	}
+	if e.WebhookID != "" {
+		ce.Extensions = map[string]string{"clickupwebhookid": e.WebhookID}
+	}
+
+	var items []struct {
+		Date string `json:"date"`
+	}
+	if len(e.HistoryItems) > 0 {
+		json.Unmarshal(e.HistoryItems, &items)
+	}
+	for _, h := range items {
+		if t := unixMilliString(h.Date); t != nil && (ce.Time == nil || t.After(*ce.Time)) {
+			utc := t.UTC()
+			ce.Time = &utc
+		}
+	}
+
+	return ce, nil
+}
+
+func webhookCloudEventSource(teamID string, loc webhookEventLocation) string {
+	source := cloudEventSourceBase
+	if teamID != "" {
+		source += "/" + teamID
+	}
+	switch {
+	case loc.list != "":
+		source += "/v/li/" + loc.list
+	case loc.folder != "":
+		source += "/v/f/" + loc.folder
+	case loc.space != "":
+		source += "/v/s/" + loc.space
+	}
+
+	return source
+}
+
+// WebhookEvent converts the event back to the webhook delivery it was made
+// from by NewWebhookCloudEvent.
+func (ce *CloudEvent) WebhookEvent() (*WebhookEvent, error) {
+	if ce.DataContentType != "" && !isJSONContentType(ce.DataContentType) {
+		return nil, fmt.Errorf("cloudevent %s has %s data, not a webhook delivery", ce.ID, ce.DataContentType)
+	}
+
+	e := &WebhookEvent{}
+	if err := json.Unmarshal(ce.Data, e); err != nil {
+		return nil, fmt.Errorf("decoding webhook delivery from cloudevent %s: %w", ce.ID, err)
+	}
+	e.Raw = ce.Data
+
+	// Event names have no dots, and type prefixes end in one.
+	event := ce.Type[strings.LastIndex(ce.Type, ".")+1:]
+	if e.Event == "" {
+		e.Event = event
+	}
+	if e.Event != event {
+		return nil, fmt.Errorf("cloudevent %s of type %s holds a %s delivery", ce.ID, ce.Type, e.Event)
+	}
+
+	return e, nil
+}
+
+// EncodeStructured returns the headers and body of the event in structured
+// mode, with the event as JSON in the body.
+func (ce *CloudEvent) EncodeStructured() (http.Header, []byte, error) {
+	body, err := json.Marshal(ce)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	h := http.Header{}
+	h.Set("Content-Type", CloudEventContentType+"; charset=utf-8")
+
+	return h, body, nil
+}
+
+// EncodeBinary returns the headers and body of the event in binary mode,
+// with the attributes in ce- headers and the data as the body.
+func (ce *CloudEvent) EncodeBinary() (http.Header, []byte) {
+	h := http.Header{}
+	set := func(name, value string) {
+		if value != "" {
+			h.Set("ce-"+name, encodeCloudEventHeader(value))
+		}
+	}
+	set("specversion", ce.SpecVersion)
+	set("id", ce.ID)
+	set("source", ce.Source)
+	set("type", ce.Type)
+	set("subject", ce.Subject)
+	if ce.Time != nil {
+		set("time", ce.Time.Format(time.RFC3339Nano))
+	}
+	names := make([]string, 0, len(ce.Extensions))
+	for name := range ce.Extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		set(name, ce.Extensions[name])
+	}
+	if ce.DataContentType != "" {
+		h.Set("Content-Type", ce.DataContentType)
+	}
+
+	return h, ce.Data
+}
+
+// DecodeCloudEvent decodes an event from HTTP headers and body in either
+// mode. Batches are not supported.
+func DecodeCloudEvent(h http.Header, body []byte) (*CloudEvent, error) {
+	mediaType, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	switch {
+	case mediaType == CloudEventContentType:
+		ce := &CloudEvent{}
+		if err := json.Unmarshal(body, ce); err != nil {
+			return nil, err
+		}
+		return ce, nil
+	case mediaType == CloudEventBatchContentType:
+		return nil, errors.New("cloudevent batches are not supported")
+	case h.Get("ce-specversion") == "":
+		return nil, errors.New("not a cloudevent")
+	}
+
+	ce := &CloudEvent{DataContentType: h.Get("Content-Type"), Data: body}
+	for name, values := range h {
+		name = strings.ToLower(name)
+		if !strings.HasPrefix(name, "ce-") || len(values) == 0 {
+			continue
+		}
+		value, err := url.PathUnescape(values[0])
+		if err != nil {
+			return nil, fmt.Errorf("cloudevent header %s: %w", name, err)
+		}
+
+		switch attr := strings.TrimPrefix(name, "ce-"); attr {
+		case "specversion":
+			ce.SpecVersion = value
+		case "id":
+			ce.ID = value
+		case "source":
+			ce.Source = value
+		case "type":
+			ce.Type = value
+		case "subject":
+			ce.Subject = value
+		case "time":
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return nil, fmt.Errorf("cloudevent header %s: %w", name, err)
+			}
+			ce.Time = &t
+		default:
+			if ce.Extensions == nil {
+				ce.Extensions = map[string]string{}
+			}
+			ce.Extensions[attr] = value
+		}
+	}
+
+	return ce, ce.validate()
+}
+
+// NewCloudEventRequest returns a POST request of the event to url, in
+// binary mode if binary is set and structured mode otherwise.
+func NewCloudEventRequest(ctx context.Context, url string, ce *CloudEvent, binary bool) (*http.Request, error) {
+	var h http.Header
+	var body []byte
+	if binary {
+		h, body = ce.EncodeBinary()
+	} else {
+		var err error
+		if h, body, err = ce.EncodeStructured(); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range h {
+		req.Header[k] = v
+	}
+
+	return req, nil
+}
+
+// ReadCloudEvent reads an event from a request in either mode.
+func ReadCloudEvent(r *http.Request) (*CloudEvent, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeCloudEvent(r.Header, body)
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || mediaType == "text/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// encodeCloudEventHeader percent-encodes a header value as the HTTP binding
+// requires: spaces, double quotes, percent signs and anything outside
+// printable ASCII.
+func encodeCloudEventHeader(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c > '~' || c == '"' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
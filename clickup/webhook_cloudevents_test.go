@@ -0,0 +1,156 @@
+package clickup
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const cloudEventDelivery = `{"event":"taskStatusUpdated","task_id":"9hz","webhook_id":"wh1","history_items":[` +
+	`{"id":"1","date":"1704067200000","field":"status","parent_id":"5","before":{"status":"to do"},"after":{"status":"done"}},` +
+	`{"id":"2","date":"1704067260000","field":"status","parent_id":"5"}]}`
+
+func TestNewWebhookCloudEvent(t *testing.T) {
+	e := &WebhookEvent{}
+	json.Unmarshal([]byte(cloudEventDelivery), e)
+	e.Raw = json.RawMessage(cloudEventDelivery)
+
+	ce, err := NewWebhookCloudEvent(e, &CloudEventOptions{TeamID: "1"})
+	if err != nil {
+		t.Fatalf("NewWebhookCloudEvent returned error: %v", err)
+	}
+
+	when := time.UnixMilli(1704067260000).UTC()
+	want := &CloudEvent{
+		SpecVersion:     "1.0",
+		ID:              "wh1:1,2",
+		Source:          "https://app.clickup.com/1/v/li/5",
+		Type:            "com.clickup.webhook.taskStatusUpdated",
+		Subject:         "9hz",
+		Time:            &when,
+		DataContentType: "application/json",
+		Data:            json.RawMessage(cloudEventDelivery),
+		Extensions:      map[string]string{"clickupwebhookid": "wh1"},
+	}
+	if !cmp.Equal(ce, want) {
+		t.Errorf("NewWebhookCloudEvent = %+v, want %+v", ce, want)
+	}
+
+	for _, tt := range []struct {
+		body, teamID, source string
+	}{
+		{`{"event":"folderCreated","folder_id":"4"}`, "1", "https://app.clickup.com/1/v/f/4"},
+		{`{"event":"spaceUpdated","space_id":"3"}`, "1", "https://app.clickup.com/1/v/s/3"},
+		{`{"event":"goalCreated","goal_id":"g1"}`, "1", "https://app.clickup.com/1"},
+		{`{"event":"listDeleted","list_id":"5"}`, "", "https://app.clickup.com/v/li/5"},
+	} {
+		e := &WebhookEvent{}
+		json.Unmarshal([]byte(tt.body), e)
+		ce, err := NewWebhookCloudEvent(e, &CloudEventOptions{TeamID: tt.teamID, TypePrefix: "clickup."})
+		if err != nil {
+			t.Fatalf("NewWebhookCloudEvent returned error: %v", err)
+		}
+		if ce.Source != tt.source || ce.Type != "clickup."+e.Event || ce.Subject != "" {
+			t.Errorf("%s: source %q, type %q, subject %q, want source %q", e.Event, ce.Source, ce.Type, ce.Subject, tt.source)
+		}
+	}
+}
+
+func TestCloudEvent_roundTrip(t *testing.T) {
+	e := &WebhookEvent{}
+	json.Unmarshal([]byte(cloudEventDelivery), e)
+	e.Raw = json.RawMessage(cloudEventDelivery)
+	ce, _ := NewWebhookCloudEvent(e, &CloudEventOptions{TeamID: "1"})
+	ce.Extensions["note"] = `50% "done"`
+
+	for _, binary := range []bool{false, true} {
+		req, err := NewCloudEventRequest(context.Background(), "http://example.com/bus", ce, binary)
+		if err != nil {
+			t.Fatalf("NewCloudEventRequest returned error: %v", err)
+		}
+		if binary && req.Header.Get("ce-note") != "50%25%20%22done%22" {
+			t.Errorf("ce-note header = %q", req.Header.Get("ce-note"))
+		}
+		if !binary && req.Header.Get("Content-Type") != "application/cloudevents+json; charset=utf-8" {
+			t.Errorf("Content-Type = %q", req.Header.Get("Content-Type"))
+		}
+
+		got, err := ReadCloudEvent(req)
+		if err != nil {
+			t.Fatalf("binary %v: ReadCloudEvent returned error: %v", binary, err)
+		}
+		if !cmp.Equal(got, ce) {
+			t.Errorf("binary %v: ReadCloudEvent = %+v, want %+v", binary, got, ce)
+		}
+
+		back, err := got.WebhookEvent()
+		if err != nil {
+			t.Fatalf("WebhookEvent returned error: %v", err)
+		}
+		parsed, err := ParseWebhookEvent(back)
+		if err != nil {
+			t.Fatalf("ParseWebhookEvent returned error: %v", err)
+		}
+		if s := parsed.(TaskStatusUpdatedEvent); s.TaskID != "9hz" || s.After.Status != "done" {
+			t.Errorf("binary %v: parsed %+v", binary, s)
+		}
+	}
+}
+
+func TestNewWebhookCloudEvent_typePrefix(t *testing.T) {
+	e := &WebhookEvent{}
+	json.Unmarshal([]byte(cloudEventDelivery), e)
+
+	if _, err := NewWebhookCloudEvent(e, &CloudEventOptions{TypePrefix: "clickup-"}); err == nil {
+		t.Error("NewWebhookCloudEvent returned no error for a prefix without a trailing dot")
+	}
+
+	ce, err := NewWebhookCloudEvent(e, &CloudEventOptions{TypePrefix: "acme.clickup."})
+	if err != nil {
+		t.Fatalf("NewWebhookCloudEvent returned error: %v", err)
+	}
+	if ce.Type != "acme.clickup.taskStatusUpdated" {
+		t.Errorf("Type = %q, want acme.clickup.taskStatusUpdated", ce.Type)
+	}
+	back, err := ce.WebhookEvent()
+	if err != nil {
+		t.Fatalf("WebhookEvent returned error: %v", err)
+	}
+	if back.Event != WebhookEventTaskStatusUpdated {
+		t.Errorf("WebhookEvent().Event = %q, want %q", back.Event, WebhookEventTaskStatusUpdated)
+	}
+}
+
+func TestDecodeCloudEvent(t *testing.T) {
+	structured := http.Header{"Content-Type": {"application/cloudevents+json"}}
+	for _, tt := range []struct {
+		name   string
+		header http.Header
+		body   string
+		ok     bool
+	}{
+		{"base64 data", structured, `{"specversion":"1.0","id":"1","source":"/s","type":"t","data_base64":"eyJhIjoxfQ==","count":3}`, true},
+		{"wrong version", structured, `{"specversion":"0.3","id":"1","source":"/s","type":"t"}`, false},
+		{"missing id", structured, `{"specversion":"1.0","source":"/s","type":"t"}`, false},
+		{"batch", http.Header{"Content-Type": {"application/cloudevents-batch+json"}}, `[]`, false},
+		{"plain JSON", http.Header{"Content-Type": {"application/json"}}, `{}`, false},
+	} {
+		ce, err := DecodeCloudEvent(tt.header, []byte(tt.body))
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: DecodeCloudEvent returned error %v", tt.name, err)
+			continue
+		}
+		if tt.ok && (string(ce.Data) != `{"a":1}` || ce.Extensions["count"] != "3") {
+			t.Errorf("%s: DecodeCloudEvent = %+v", tt.name, ce)
+		}
+	}
+
+	ce := &CloudEvent{SpecVersion: "1.0", ID: "1", Source: "/s", Type: "com.clickup.webhook.taskCreated", Data: json.RawMessage(`{"event":"taskDeleted"}`)}
+	if _, err := ce.WebhookEvent(); err == nil {
+		t.Error("WebhookEvent returned no error for a mismatched type")
+	}
+}
@@ -0,0 +1,705 @@
+package clickup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Webhook events, for WebhookRequest.Events and WebhookReceiver.Handle.
+const (
+	WebhookEventAll = "*"
+
+	WebhookEventTaskCreated             = "taskCreated"
+	WebhookEventTaskUpdated             = "taskUpdated"
+	WebhookEventTaskDeleted             = "taskDeleted"
+	WebhookEventTaskPriorityUpdated     = "taskPriorityUpdated"
+	WebhookEventTaskStatusUpdated       = "taskStatusUpdated"
+	WebhookEventTaskAssigneeUpdated     = "taskAssigneeUpdated"
+	WebhookEventTaskDueDateUpdated      = "taskDueDateUpdated"
+	WebhookEventTaskTagUpdated          = "taskTagUpdated"
+	WebhookEventTaskMoved               = "taskMoved"
+	WebhookEventTaskCommentPosted       = "taskCommentPosted"
+	WebhookEventTaskCommentUpdated      = "taskCommentUpdated"
+	WebhookEventTaskTimeEstimateUpdated = "taskTimeEstimateUpdated"
+	WebhookEventTaskTimeTrackedUpdated  = "taskTimeTrackedUpdated"
+	WebhookEventListCreated             = "listCreated"
+	WebhookEventListUpdated             = "listUpdated"
+	WebhookEventListDeleted             = "listDeleted"
+	WebhookEventFolderCreated           = "folderCreated"
+	WebhookEventFolderUpdated           = "folderUpdated"
+	WebhookEventFolderDeleted           = "folderDeleted"
+	WebhookEventSpaceCreated            = "spaceCreated"
+	WebhookEventSpaceUpdated            = "spaceUpdated"
+	WebhookEventSpaceDeleted            = "spaceDeleted"
+	WebhookEventGoalCreated             = "goalCreated"
+	WebhookEventGoalUpdated             = "goalUpdated"
+	WebhookEventGoalDeleted             = "goalDeleted"
+	WebhookEventKeyResultCreated        = "keyResultCreated"
+	WebhookEventKeyResultUpdated        = "keyResultUpdated"
+	WebhookEventKeyResultDeleted        = "keyResultDeleted"
+)
+
+// WebhookHistoryItem describes one change in a webhook delivery. Before and
+// After hold the old and new values of Field, whose shape depends on the
+// field.
+type WebhookHistoryItem struct {
+	ID       string          `json:"id"`
+	Type     int             `json:"type"`
+	Date     string          `json:"date"`
+	Field    string          `json:"field"`
+	ParentID string          `json:"parent_id"`
+	Data     json.RawMessage `json:"data,omitempty"`
+	User     User            `json:"user"`
+	Before   json.RawMessage `json:"before,omitempty"`
+	After    json.RawMessage `json:"after,omitempty"`
+	Comment  json.RawMessage `json:"comment,omitempty"`
+}
+
+// DecodeValues decodes Before and After into before and after. Either may be
+// nil to skip it; null values leave them unchanged.
+func (h *WebhookHistoryItem) DecodeValues(before, after interface{}) error {
+	for _, v := range []struct {
+		raw json.RawMessage
+		dst interface{}
+	}{{h.Before, before}, {h.After, after}} {
+		if v.dst == nil || len(v.raw) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(v.raw, v.dst); err != nil {
+			return fmt.Errorf("decoding %s history item %s: %w", h.Field, h.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// WebhookPayload is a delivery with its history items decoded. Every typed
+// event embeds it.
+type WebhookPayload struct {
+	WebhookEvent
+	History []WebhookHistoryItem
+}
+
+// HistoryItem returns the first history item changing field, or nil.
+func (p *WebhookPayload) HistoryItem(field string) *WebhookHistoryItem {
+	for i := range p.History {
+		if p.History[i].Field == field {
+			return &p.History[i]
+		}
+	}
+
+	return nil
+}
+
+// WebhookStatus is a task status in a history item.
+type WebhookStatus struct {
+	Status     string      `json:"status"`
+	Color      string      `json:"color"`
+	Type       string      `json:"type"`
+	Orderindex json.Number `json:"orderindex"`
+}
+
+// WebhookPriority is a task priority in a history item.
+type WebhookPriority struct {
+	ID         string      `json:"id"`
+	Priority   string      `json:"priority"`
+	Color      string      `json:"color"`
+	Orderindex json.Number `json:"orderindex"`
+}
+
+// WebhookLocation is the list a task was in or moved to, with its folder and
+// space.
+type WebhookLocation struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Category struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Hidden bool   `json:"hidden"`
+	} `json:"category"`
+	Project struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"project"`
+}
+
+// WebhookComment is a comment in a history item.
+type WebhookComment struct {
+	ID          json.Number        `json:"id"`
+	Date        json.Number        `json:"date"`
+	Parent      string             `json:"parent"`
+	Type        int                `json:"type"`
+	Comment     []CommentInComment `json:"comment"`
+	TextContent string             `json:"text_content"`
+	UserID      json.Number        `json:"userid"`
+	Resolved    bool               `json:"resolved"`
+}
+
+// WebhookTimeEntry is a tracked time entry in a history item. Times are Unix
+// milliseconds and Time is the duration in milliseconds.
+type WebhookTimeEntry struct {
+	ID    string      `json:"id"`
+	Start json.Number `json:"start"`
+	End   json.Number `json:"end"`
+	Time  json.Number `json:"time"`
+}
+
+type TaskCreatedEvent struct{ WebhookPayload }
+
+// TaskUpdatedEvent carries a history item per changed field.
+type TaskUpdatedEvent struct{ WebhookPayload }
+
+type TaskDeletedEvent struct{ WebhookPayload }
+
+// TaskPriorityUpdatedEvent has a nil Before or After when the task had or
+// has no priority.
+type TaskPriorityUpdatedEvent struct {
+	WebhookPayload
+	Before, After *WebhookPriority
+}
+
+type TaskStatusUpdatedEvent struct {
+	WebhookPayload
+	Before, After *WebhookStatus
+}
+
+type TaskAssigneeUpdatedEvent struct {
+	WebhookPayload
+	Added, Removed []User
+}
+
+// TaskDueDateUpdatedEvent has a nil Before or After when the task had or has
+// no due date.
+type TaskDueDateUpdatedEvent struct {
+	WebhookPayload
+	Before, After *time.Time
+}
+
+type TaskTagUpdatedEvent struct {
+	WebhookPayload
+	Added, Removed []Tag
+}
+
+type TaskMovedEvent struct {
+	WebhookPayload
+	Before, After *WebhookLocation
+}
+
+type TaskCommentPostedEvent struct {
+	WebhookPayload
+	Comment *WebhookComment
+}
+
+type TaskCommentUpdatedEvent struct {
+	WebhookPayload
+	Comment *WebhookComment
+}
+
+// TaskTimeEstimateUpdatedEvent has a nil Before or After when the task had
+// or has no estimate.
+type TaskTimeEstimateUpdatedEvent struct {
+	WebhookPayload
+	Before, After *time.Duration
+}
+
+// TaskTimeTrackedUpdatedEvent has a nil Before when an entry was added and a
+// nil After when one was removed.
+type TaskTimeTrackedUpdatedEvent struct {
+	WebhookPayload
+	Before, After *WebhookTimeEntry
+}
+
+// WebhookFieldChange is a history item of a list, folder, space, goal or key
+// result event. Before and After are decoded as by json.Unmarshal into an
+// interface{}, so numbers are float64 and objects map[string]interface{}.
+type WebhookFieldChange struct {
+	ID            string
+	Field         string
+	Date          *time.Time
+	User          User
+	Before, After interface{}
+}
+
+type ListCreatedEvent struct {
+	WebhookPayload
+	ListID  string
+	Changes []WebhookFieldChange
+}
+
+type ListUpdatedEvent struct {
+	WebhookPayload
+	ListID  string
+	Changes []WebhookFieldChange
+}
+
+type ListDeletedEvent struct {
+	WebhookPayload
+	ListID  string
+	Changes []WebhookFieldChange
+}
+
+type FolderCreatedEvent struct {
+	WebhookPayload
+	FolderID string
+	Changes  []WebhookFieldChange
+}
+
+type FolderUpdatedEvent struct {
+	WebhookPayload
+	FolderID string
+	Changes  []WebhookFieldChange
+}
+
+type FolderDeletedEvent struct {
+	WebhookPayload
+	FolderID string
+	Changes  []WebhookFieldChange
+}
+
+type SpaceCreatedEvent struct {
+	WebhookPayload
+	SpaceID string
+	Changes []WebhookFieldChange
+}
+
+type SpaceUpdatedEvent struct {
+	WebhookPayload
+	SpaceID string
+	Changes []WebhookFieldChange
+}
+
+type SpaceDeletedEvent struct {
+	WebhookPayload
+	SpaceID string
+	Changes []WebhookFieldChange
+}
+
+type GoalCreatedEvent struct {
+	WebhookPayload
+	GoalID  string
+	Changes []WebhookFieldChange
+}
+
+type GoalUpdatedEvent struct {
+	WebhookPayload
+	GoalID  string
+	Changes []WebhookFieldChange
+}
+
+type GoalDeletedEvent struct {
+	WebhookPayload
+	GoalID  string
+	Changes []WebhookFieldChange
+}
+
+type KeyResultCreatedEvent struct {
+	WebhookPayload
+	GoalID, KeyResultID string
+	Changes             []WebhookFieldChange
+}
+
+type KeyResultUpdatedEvent struct {
+	WebhookPayload
+	GoalID, KeyResultID string
+	Changes             []WebhookFieldChange
+}
+
+type KeyResultDeletedEvent struct {
+	WebhookPayload
+	GoalID, KeyResultID string
+	Changes             []WebhookFieldChange
+}
+
+var webhookEventDecoders = map[string]func(p WebhookPayload) (interface{}, error){
+	WebhookEventTaskCreated: func(p WebhookPayload) (interface{}, error) { return TaskCreatedEvent{p}, nil },
+	WebhookEventTaskUpdated: func(p WebhookPayload) (interface{}, error) { return TaskUpdatedEvent{p}, nil },
+	WebhookEventTaskDeleted: func(p WebhookPayload) (interface{}, error) { return TaskDeletedEvent{p}, nil },
+	WebhookEventTaskPriorityUpdated: func(p WebhookPayload) (interface{}, error) {
+		e := TaskPriorityUpdatedEvent{WebhookPayload: p}
+		return e, decodeHistoryValues(&p, "priority", &e.Before, &e.After)
+	},
+	WebhookEventTaskStatusUpdated: func(p WebhookPayload) (interface{}, error) {
+		e := TaskStatusUpdatedEvent{WebhookPayload: p}
+		return e, decodeHistoryValues(&p, "status", &e.Before, &e.After)
+	},
+	WebhookEventTaskAssigneeUpdated: func(p WebhookPayload) (interface{}, error) {
+		e := TaskAssigneeUpdatedEvent{WebhookPayload: p}
+		for _, h := range p.History {
+			var u *User
+			var err error
+			switch h.Field {
+			case "assignee_add":
+				err = h.DecodeValues(nil, &u)
+				if u != nil {
+					e.Added = append(e.Added, *u)
+				}
+			case "assignee_rem":
+				err = h.DecodeValues(&u, nil)
+				if u != nil {
+					e.Removed = append(e.Removed, *u)
+				}
+			}
+			if err != nil {
+				return e, err
+			}
+		}
+		return e, nil
+	},
+	WebhookEventTaskDueDateUpdated: func(p WebhookPayload) (interface{}, error) {
+		e := TaskDueDateUpdatedEvent{WebhookPayload: p}
+		var before, after json.Number
+		err := decodeHistoryValues(&p, "due_date", &before, &after)
+		e.Before, e.After = unixMilliString(before.String()), unixMilliString(after.String())
+		return e, err
+	},
+	WebhookEventTaskTagUpdated: func(p WebhookPayload) (interface{}, error) {
+		e := TaskTagUpdatedEvent{WebhookPayload: p}
+		for _, h := range p.History {
+			var tags []Tag
+			var err error
+			switch h.Field {
+			case "tag":
+				err = h.DecodeValues(nil, &tags)
+				e.Added = append(e.Added, tags...)
+			case "tag_removed":
+				err = h.DecodeValues(&tags, nil)
+				e.Removed = append(e.Removed, tags...)
+			}
+			if err != nil {
+				return e, err
+			}
+		}
+		return e, nil
+	},
+	WebhookEventTaskMoved: func(p WebhookPayload) (interface{}, error) {
+		e := TaskMovedEvent{WebhookPayload: p}
+		return e, decodeHistoryValues(&p, "section_moved", &e.Before, &e.After)
+	},
+	WebhookEventTaskCommentPosted: func(p WebhookPayload) (interface{}, error) {
+		comment, err := decodeHistoryComment(&p)
+		return TaskCommentPostedEvent{p, comment}, err
+	},
+	WebhookEventTaskCommentUpdated: func(p WebhookPayload) (interface{}, error) {
+		comment, err := decodeHistoryComment(&p)
+		return TaskCommentUpdatedEvent{p, comment}, err
+	},
+	WebhookEventTaskTimeEstimateUpdated: func(p WebhookPayload) (interface{}, error) {
+		e := TaskTimeEstimateUpdatedEvent{WebhookPayload: p}
+		var before, after json.Number
+		err := decodeHistoryValues(&p, "time_estimate", &before, &after)
+		e.Before, e.After = millisDuration(before), millisDuration(after)
+		return e, err
+	},
+	WebhookEventTaskTimeTrackedUpdated: func(p WebhookPayload) (interface{}, error) {
+		e := TaskTimeTrackedUpdatedEvent{WebhookPayload: p}
+		return e, decodeHistoryValues(&p, "time_spent", &e.Before, &e.After)
+	},
+	WebhookEventListCreated: func(p WebhookPayload) (interface{}, error) {
+		changes, err := decodeWebhookChanges(&p)
+		return ListCreatedEvent{p, p.ListID.String(), changes}, err
+	},
+	WebhookEventListUpdated: func(p WebhookPayload) (interface{}, error) {
+		changes, err := decodeWebhookChanges(&p)
+		return ListUpdatedEvent{p, p.ListID.String(), changes}, err
+	},
+	WebhookEventListDeleted: func(p WebhookPayload) (interface{}, error) {
+		changes, err := decodeWebhookChanges(&p)
+		return ListDeletedEvent{p, p.ListID.String(), changes}, err
+	},
+	WebhookEventFolderCreated: func(p WebhookPayload) (interface{}, error) {
+		changes, err := decodeWebhookChanges(&p)
+		return FolderCreatedEvent{p, p.FolderID.String(), changes}, err
+	},
+	WebhookEventFolderUpdated: func(p WebhookPayload) (interface{}, error) {
+		changes, err := decodeWebhookChanges(&p)
+		return FolderUpdatedEvent{p, p.FolderID.String(), changes}, err
+	},
+	WebhookEventFolderDeleted: func(p WebhookPayload) (interface{}, error) {
+		changes, err := decodeWebhookChanges(&p)
+		return FolderDeletedEvent{p, p.FolderID.String(), changes}, err
+	},
+	WebhookEventSpaceCreated: func(p WebhookPayload) (interface{}, error) {
+		changes, err := decodeWebhookChanges(&p)
+		return SpaceCreatedEvent{p, p.SpaceID.String(), changes}, err
+	},
+	WebhookEventSpaceUpdated: func(p WebhookPayload) (interface{}, error) {
+		changes, err := decodeWebhookChanges(&p)
+		return SpaceUpdatedEvent{p, p.SpaceID.String(), changes}, err
+	},
+	WebhookEventSpaceDeleted: func(p WebhookPayload) (interface{}, error) {
+		changes, err := decodeWebhookChanges(&p)
+		return SpaceDeletedEvent{p, p.SpaceID.String(), changes}, err
+	},
+	WebhookEventGoalCreated: func(p WebhookPayload) (interface{}, error) {
+		changes, err := decodeWebhookChanges(&p)
+		return GoalCreatedEvent{p, p.GoalID, changes}, err
+	},
+	WebhookEventGoalUpdated: func(p WebhookPayload) (interface{}, error) {
+		changes, err := decodeWebhookChanges(&p)
+		return GoalUpdatedEvent{p, p.GoalID, changes}, err
+	},
+	WebhookEventGoalDeleted: func(p WebhookPayload) (interface{}, error) {
+		changes, err := decodeWebhookChanges(&p)
+		return GoalDeletedEvent{p, p.GoalID, changes}, err
+	},
+	WebhookEventKeyResultCreated: func(p WebhookPayload) (interface{}, error) {
+		changes, err := decodeWebhookChanges(&p)
+		return KeyResultCreatedEvent{p, p.GoalID, p.KeyResultID, changes}, err
+	},
+	WebhookEventKeyResultUpdated: func(p WebhookPayload) (interface{}, error) {
+		changes, err := decodeWebhookChanges(&p)
+		return KeyResultUpdatedEvent{p, p.GoalID, p.KeyResultID, changes}, err
+	},
+	WebhookEventKeyResultDeleted: func(p WebhookPayload) (interface{}, error) {
+		changes, err := decodeWebhookChanges(&p)
+		return KeyResultDeletedEvent{p, p.GoalID, p.KeyResultID, changes}, err
+	},
+}
+
+func decodeHistoryValues(p *WebhookPayload, field string, before, after interface{}) error {
+	h := p.HistoryItem(field)
+	if h == nil {
+		return nil
+	}
+
+	return h.DecodeValues(before, after)
+}
+
+func decodeWebhookChanges(p *WebhookPayload) ([]WebhookFieldChange, error) {
+	var changes []WebhookFieldChange
+	for _, h := range p.History {
+		c := WebhookFieldChange{ID: h.ID, Field: h.Field, Date: unixMilliString(h.Date), User: h.User}
+		if err := h.DecodeValues(&c.Before, &c.After); err != nil {
+			return changes, err
+		}
+		changes = append(changes, c)
+	}
+
+	return changes, nil
+}
+
+func decodeHistoryComment(p *WebhookPayload) (*WebhookComment, error) {
+	h := p.HistoryItem("comment")
+	if h == nil || len(h.Comment) == 0 {
+		return nil, nil
+	}
+
+	var c *WebhookComment
+	if err := json.Unmarshal(h.Comment, &c); err != nil {
+		return nil, fmt.Errorf("decoding comment history item %s: %w", h.ID, err)
+	}
+
+	return c, nil
+}
+
+func millisDuration(n json.Number) *time.Duration {
+	ms, err := n.Int64()
+	if err != nil {
+		return nil
+	}
+	d := time.Duration(ms) * time.Millisecond
+
+	return &d
+}
+
+// WebhookDecodeError reports a delivery that cannot be decoded into its typed
+// event. Retrying the delivery cannot fix it, so WebhookReceiver acknowledges
+// it and WebhookProcessor moves it to the dead letters right away.
+type WebhookDecodeError struct {
+	Event string
+	Err   error
+}
+
+func (e *WebhookDecodeError) Error() string {
+	return fmt.Sprintf("clickup: decoding %s webhook event: %v", e.Event, e.Err)
+}
+
+func (e *WebhookDecodeError) Unwrap() error {
+	return e.Err
+}
+
+// ParseWebhookEvent decodes a delivery into its typed event, such as
+// TaskStatusUpdatedEvent. Unknown events are returned as a WebhookPayload.
+// Decoding failures are reported as a *WebhookDecodeError.
+func ParseWebhookEvent(event *WebhookEvent) (interface{}, error) {
+	p := WebhookPayload{WebhookEvent: *event}
+	if len(event.HistoryItems) > 0 && string(event.HistoryItems) != "null" {
+		if err := json.Unmarshal(event.HistoryItems, &p.History); err != nil {
+			return nil, &WebhookDecodeError{Event: event.Event, Err: fmt.Errorf("decoding history items: %w", err)}
+		}
+	}
+
+	decode, ok := webhookEventDecoders[event.Event]
+	if !ok {
+		return p, nil
+	}
+
+	typed, err := decode(p)
+	if err != nil {
+		return nil, &WebhookDecodeError{Event: event.Event, Err: err}
+	}
+
+	return typed, nil
+}
+
+// WebhookDispatcher routes deliveries to typed handlers. Register its Handle
+// method on a WebhookReceiver:
+//
+//	d := clickup.NewWebhookDispatcher()
+//	d.OnTaskStatusUpdated(func(ctx context.Context, e clickup.TaskStatusUpdatedEvent) error {
+//		...
+//	})
+//	receiver.Handle(clickup.WebhookEventAll, d.Handle)
+type WebhookDispatcher struct {
+	handlers map[string]func(ctx context.Context, event interface{}) error
+	fallback func(ctx context.Context, event string, raw json.RawMessage) error
+}
+
+func NewWebhookDispatcher() *WebhookDispatcher {
+	return &WebhookDispatcher{handlers: map[string]func(ctx context.Context, event interface{}) error{}}
+}
+
+// Handle decodes a delivery and calls the handler registered for its event.
+// Unknown events, events without a handler and events that cannot be decoded
+// go to the fallback handler if there is one. Without a fallback, decoding
+// failures are returned as a *WebhookDecodeError.
+func (d *WebhookDispatcher) Handle(ctx context.Context, event *WebhookEvent) error {
+	h, ok := d.handlers[event.Event]
+	if !ok {
+		if d.fallback == nil {
+			return nil
+		}
+		return d.fallback(ctx, event.Event, event.Raw)
+	}
+
+	typed, err := ParseWebhookEvent(event)
+	if err != nil {
+		if d.fallback != nil {
+			return d.fallback(ctx, event.Event, event.Raw)
+		}
+		return err
+	}
+
+	return h(ctx, typed)
+}
+
+// OnOther registers the handler for events without a typed handler, and for
+// events that cannot be decoded. It gets the event name and the delivery as
+// received.
+func (d *WebhookDispatcher) OnOther(h func(ctx context.Context, event string, raw json.RawMessage) error) {
+	d.fallback = h
+}
+
+func (d *WebhookDispatcher) OnTaskCreated(h func(ctx context.Context, e TaskCreatedEvent) error) {
+	d.handlers[WebhookEventTaskCreated] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(TaskCreatedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnTaskUpdated(h func(ctx context.Context, e TaskUpdatedEvent) error) {
+	d.handlers[WebhookEventTaskUpdated] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(TaskUpdatedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnTaskDeleted(h func(ctx context.Context, e TaskDeletedEvent) error) {
+	d.handlers[WebhookEventTaskDeleted] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(TaskDeletedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnTaskPriorityUpdated(h func(ctx context.Context, e TaskPriorityUpdatedEvent) error) {
+	d.handlers[WebhookEventTaskPriorityUpdated] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(TaskPriorityUpdatedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnTaskStatusUpdated(h func(ctx context.Context, e TaskStatusUpdatedEvent) error) {
+	d.handlers[WebhookEventTaskStatusUpdated] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(TaskStatusUpdatedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnTaskAssigneeUpdated(h func(ctx context.Context, e TaskAssigneeUpdatedEvent) error) {
+	d.handlers[WebhookEventTaskAssigneeUpdated] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(TaskAssigneeUpdatedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnTaskDueDateUpdated(h func(ctx context.Context, e TaskDueDateUpdatedEvent) error) {
+	d.handlers[WebhookEventTaskDueDateUpdated] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(TaskDueDateUpdatedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnTaskTagUpdated(h func(ctx context.Context, e TaskTagUpdatedEvent) error) {
+	d.handlers[WebhookEventTaskTagUpdated] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(TaskTagUpdatedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnTaskMoved(h func(ctx context.Context, e TaskMovedEvent) error) {
+	d.handlers[WebhookEventTaskMoved] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(TaskMovedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnTaskCommentPosted(h func(ctx context.Context, e TaskCommentPostedEvent) error) {
+	d.handlers[WebhookEventTaskCommentPosted] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(TaskCommentPostedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnTaskCommentUpdated(h func(ctx context.Context, e TaskCommentUpdatedEvent) error) {
+	d.handlers[WebhookEventTaskCommentUpdated] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(TaskCommentUpdatedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnTaskTimeEstimateUpdated(h func(ctx context.Context, e TaskTimeEstimateUpdatedEvent) error) {
+	d.handlers[WebhookEventTaskTimeEstimateUpdated] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(TaskTimeEstimateUpdatedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnTaskTimeTrackedUpdated(h func(ctx context.Context, e TaskTimeTrackedUpdatedEvent) error) {
+	d.handlers[WebhookEventTaskTimeTrackedUpdated] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(TaskTimeTrackedUpdatedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnListCreated(h func(ctx context.Context, e ListCreatedEvent) error) {
+	d.handlers[WebhookEventListCreated] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(ListCreatedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnListUpdated(h func(ctx context.Context, e ListUpdatedEvent) error) {
+	d.handlers[WebhookEventListUpdated] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(ListUpdatedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnListDeleted(h func(ctx context.Context, e ListDeletedEvent) error) {
+	d.handlers[WebhookEventListDeleted] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(ListDeletedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnFolderCreated(h func(ctx context.Context, e FolderCreatedEvent) error) {
+	d.handlers[WebhookEventFolderCreated] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(FolderCreatedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnFolderUpdated(h func(ctx context.Context, e FolderUpdatedEvent) error) {
+	d.handlers[WebhookEventFolderUpdated] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(FolderUpdatedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnFolderDeleted(h func(ctx context.Context, e FolderDeletedEvent) error) {
+	d.handlers[WebhookEventFolderDeleted] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(FolderDeletedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnSpaceCreated(h func(ctx context.Context, e SpaceCreatedEvent) error) {
+	d.handlers[WebhookEventSpaceCreated] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(SpaceCreatedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnSpaceUpdated(h func(ctx context.Context, e SpaceUpdatedEvent) error) {
+	d.handlers[WebhookEventSpaceUpdated] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(SpaceUpdatedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnSpaceDeleted(h func(ctx context.Context, e SpaceDeletedEvent) error) {
+	d.handlers[WebhookEventSpaceDeleted] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(SpaceDeletedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnGoalCreated(h func(ctx context.Context, e GoalCreatedEvent) error) {
+	d.handlers[WebhookEventGoalCreated] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(GoalCreatedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnGoalUpdated(h func(ctx context.Context, e GoalUpdatedEvent) error) {
+	d.handlers[WebhookEventGoalUpdated] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(GoalUpdatedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnGoalDeleted(h func(ctx context.Context, e GoalDeletedEvent) error) {
+	d.handlers[WebhookEventGoalDeleted] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(GoalDeletedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnKeyResultCreated(h func(ctx context.Context, e KeyResultCreatedEvent) error) {
+	d.handlers[WebhookEventKeyResultCreated] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(KeyResultCreatedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnKeyResultUpdated(h func(ctx context.Context, e KeyResultUpdatedEvent) error) {
+	d.handlers[WebhookEventKeyResultUpdated] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(KeyResultUpdatedEvent)) }
+}
+
+func (d *WebhookDispatcher) OnKeyResultDeleted(h func(ctx context.Context, e KeyResultDeletedEvent) error) {
+	d.handlers[WebhookEventKeyResultDeleted] = func(ctx context.Context, e interface{}) error { return h(ctx, e.(KeyResultDeletedEvent)) }
+}
@@ -0,0 +1,226 @@
+package clickup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func parseTestWebhookEvent(t *testing.T, body string) interface{} {
+	t.Helper()
+	event := &WebhookEvent{}
+	if err := json.Unmarshal([]byte(body), event); err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+	event.Raw = json.RawMessage(body)
+
+	typed, err := ParseWebhookEvent(event)
+	if err != nil {
+		t.Fatalf("ParseWebhookEvent returned error: %v", err)
+	}
+
+	return typed
+}
+
+func TestParseWebhookEvent_taskStatusUpdated(t *testing.T) {
+	typed := parseTestWebhookEvent(t, `{
+		"event": "taskStatusUpdated",
+		"history_items": [{
+			"id": "2800787326197999969", "type": 1, "date": "1642736394447", "field": "status", "parent_id": "162641062",
+			"data": {"status_type": "custom"},
+			"user": {"id": 183, "username": "John"},
+			"before": {"status": "to do", "color": "#d3d3d3", "orderindex": 0, "type": "open"},
+			"after": {"status": "in progress", "color": "#4194f6", "orderindex": 1, "type": "custom"}
+		}],
+		"task_id": "1vj37mc",
+		"webhook_id": "7fa3ec74"
+	}`)
+
+	e, ok := typed.(TaskStatusUpdatedEvent)
+	if !ok {
+		t.Fatalf("ParseWebhookEvent returned %T, want TaskStatusUpdatedEvent", typed)
+	}
+	if e.TaskID != "1vj37mc" || e.WebhookID != "7fa3ec74" || len(e.History) != 1 || e.History[0].User.ID != 183 {
+		t.Errorf("payload = %+v", e.WebhookPayload)
+	}
+	if want := (&WebhookStatus{Status: "to do", Color: "#d3d3d3", Type: "open", Orderindex: "0"}); !cmp.Equal(e.Before, want) {
+		t.Errorf("Before = %+v, want %+v", e.Before, want)
+	}
+	if want := (&WebhookStatus{Status: "in progress", Color: "#4194f6", Type: "custom", Orderindex: "1"}); !cmp.Equal(e.After, want) {
+		t.Errorf("After = %+v, want %+v", e.After, want)
+	}
+}
+
+func TestParseWebhookEvent_values(t *testing.T) {
+	due := time.UnixMilli(1642996800000)
+	estimate := 90 * time.Minute
+
+	for _, tt := range []struct {
+		body string
+		want interface{}
+	}{
+		{`{"event": "taskDueDateUpdated", "history_items": [{"field": "due_date", "before": null, "after": "1642996800000"}]}`,
+			TaskDueDateUpdatedEvent{After: &due}},
+		{`{"event": "taskTimeEstimateUpdated", "history_items": [{"field": "time_estimate", "before": "5400000", "after": null}]}`,
+			TaskTimeEstimateUpdatedEvent{Before: &estimate}},
+		{`{"event": "taskPriorityUpdated", "history_items": [{"field": "priority", "before": null, "after": {"id": "1", "priority": "urgent", "color": "#f50000", "orderindex": "1"}}]}`,
+			TaskPriorityUpdatedEvent{After: &WebhookPriority{ID: "1", Priority: "urgent", Color: "#f50000", Orderindex: "1"}}},
+		{`{"event": "taskAssigneeUpdated", "history_items": [
+			{"field": "assignee_add", "before": null, "after": {"id": 183, "username": "John"}},
+			{"field": "assignee_rem", "before": {"id": 7, "username": "Ann"}, "after": null}]}`,
+			TaskAssigneeUpdatedEvent{Added: []User{{ID: 183, Username: "John"}}, Removed: []User{{ID: 7, Username: "Ann"}}}},
+		{`{"event": "taskTagUpdated", "history_items": [
+			{"field": "tag", "after": [{"name": "bug", "tag_fg": "#fff", "tag_bg": "#f00"}]},
+			{"field": "tag_removed", "before": [{"name": "idea"}]}]}`,
+			TaskTagUpdatedEvent{Added: []Tag{{Name: "bug", TagFg: "#fff", TagBg: "#f00"}}, Removed: []Tag{{Name: "idea"}}}},
+		{`{"event": "taskTimeTrackedUpdated", "history_items": [{"field": "time_spent", "after": {"id": "t1", "start": "1642736000000", "end": "1642737000000", "time": "1000000"}}]}`,
+			TaskTimeTrackedUpdatedEvent{After: &WebhookTimeEntry{ID: "t1", Start: "1642736000000", End: "1642737000000", Time: "1000000"}}},
+		{`{"event": "taskCommentPosted", "history_items": [{"field": "comment", "comment": {"id": "648893191", "date": "1642694264183", "comment": [{"text": "hi"}], "text_content": "hi", "userid": "183"}}]}`,
+			TaskCommentPostedEvent{Comment: &WebhookComment{ID: "648893191", Date: "1642694264183", Comment: []CommentInComment{{Text: "hi"}}, TextContent: "hi", UserID: "183"}}},
+	} {
+		typed := parseTestWebhookEvent(t, tt.body)
+
+		// Compare only the typed values.
+		if !cmp.Equal(typed, tt.want, cmpopts.IgnoreTypes(WebhookPayload{})) {
+			t.Errorf("ParseWebhookEvent(%s) = %+v, want %+v", tt.body, typed, tt.want)
+		}
+	}
+}
+
+func TestParseWebhookEvent_taskMoved(t *testing.T) {
+	typed := parseTestWebhookEvent(t, `{"event": "taskMoved", "task_id": "9hz", "history_items": [{
+		"field": "section_moved",
+		"before": {"id": "162641234", "name": "Old list", "category": {"id": "96771950", "name": "Folder", "hidden": false}, "project": {"id": "7002367", "name": "Space"}},
+		"after": {"id": "162641062", "name": "New list", "category": {"id": "96771950", "name": "Folder", "hidden": false}, "project": {"id": "7002367", "name": "Space"}}
+	}]}`)
+
+	e := typed.(TaskMovedEvent)
+	if e.Before.ID != "162641234" || e.After.Name != "New list" || e.After.Category.ID != "96771950" || e.After.Project.Name != "Space" {
+		t.Errorf("TaskMovedEvent = %+v, %+v", e.Before, e.After)
+	}
+}
+
+func TestParseWebhookEvent_resources(t *testing.T) {
+	date := time.UnixMilli(1642740510345)
+	user := User{ID: 183, Username: "John"}
+
+	for _, tt := range []struct {
+		body string
+		want interface{}
+	}{
+		{`{"event": "listUpdated", "list_id": "162641285", "history_items": [{"id": "h1", "date": "1642740510345", "field": "name", "user": {"id": 183, "username": "John"}, "before": "Old", "after": "New"}]}`,
+			ListUpdatedEvent{ListID: "162641285", Changes: []WebhookFieldChange{{ID: "h1", Field: "name", Date: &date, User: user, Before: "Old", After: "New"}}}},
+		{`{"event": "listDeleted", "list_id": "162641285"}`,
+			ListDeletedEvent{ListID: "162641285"}},
+		{`{"event": "folderCreated", "folder_id": "96772212", "history_items": [{"id": "h2", "field": "category", "after": {"id": "96772212", "name": "Folder"}}]}`,
+			FolderCreatedEvent{FolderID: "96772212", Changes: []WebhookFieldChange{{ID: "h2", Field: "category", After: map[string]interface{}{"id": "96772212", "name": "Folder"}}}}},
+		{`{"event": "spaceUpdated", "space_id": "7002367", "history_items": [{"field": "private", "before": false, "after": true}]}`,
+			SpaceUpdatedEvent{SpaceID: "7002367", Changes: []WebhookFieldChange{{Field: "private", Before: false, After: true}}}},
+		{`{"event": "goalUpdated", "goal_id": "a23e5a3d", "history_items": [{"field": "percent_completed", "before": 10, "after": 50}]}`,
+			GoalUpdatedEvent{GoalID: "a23e5a3d", Changes: []WebhookFieldChange{{Field: "percent_completed", Before: 10.0, After: 50.0}}}},
+		{`{"event": "keyResultCreated", "goal_id": "a23e5a3d", "key_result_id": "47608e42"}`,
+			KeyResultCreatedEvent{GoalID: "a23e5a3d", KeyResultID: "47608e42"}},
+	} {
+		typed := parseTestWebhookEvent(t, tt.body)
+
+		if !cmp.Equal(typed, tt.want, cmpopts.IgnoreTypes(WebhookPayload{})) {
+			t.Errorf("ParseWebhookEvent(%s) = %+v, want %+v", tt.body, typed, tt.want)
+		}
+	}
+}
+
+func TestParseWebhookEvent_unknown(t *testing.T) {
+	typed := parseTestWebhookEvent(t, `{"event": "taskLinked", "task_id": "9hz", "history_items": [{"id": "1", "field": "linked_task"}]}`)
+
+	p, ok := typed.(WebhookPayload)
+	if !ok {
+		t.Fatalf("ParseWebhookEvent returned %T, want WebhookPayload", typed)
+	}
+	if p.Event != "taskLinked" || p.HistoryItem("linked_task") == nil {
+		t.Errorf("WebhookPayload = %+v", p)
+	}
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	d := NewWebhookDispatcher()
+
+	var got []string
+	d.OnTaskStatusUpdated(func(ctx context.Context, e TaskStatusUpdatedEvent) error {
+		got = append(got, "status:"+e.TaskID+":"+e.After.Status)
+		return nil
+	})
+	d.OnGoalDeleted(func(ctx context.Context, e GoalDeletedEvent) error {
+		got = append(got, "goal:"+e.GoalID)
+		return nil
+	})
+	d.OnOther(func(ctx context.Context, event string, raw json.RawMessage) error {
+		got = append(got, "other:"+event+":"+string(raw))
+		return nil
+	})
+
+	wr := NewWebhookReceiver("s3cret")
+	wr.Handle(WebhookEventAll, d.Handle)
+
+	for _, body := range []string{
+		`{"event":"taskStatusUpdated","task_id":"9hz","history_items":[{"field":"status","after":{"status":"done"}}]}`,
+		`{"event":"goalDeleted","goal_id":"g1"}`,
+		`{"event":"taskCreated","task_id":"9hz"}`,
+		`{"event":"somethingNew","id":1}`,
+	} {
+		if rec := deliverWebhook(wr, "s3cret", body); rec.Code != http.StatusOK {
+			t.Errorf("delivering %s: status = %d, want 200", body, rec.Code)
+		}
+	}
+
+	want := []string{
+		"status:9hz:done",
+		"goal:g1",
+		`other:taskCreated:{"event":"taskCreated","task_id":"9hz"}`,
+		`other:somethingNew:{"event":"somethingNew","id":1}`,
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("dispatched %q, want %q", got, want)
+	}
+}
+
+func TestWebhookDispatcher_decodeError(t *testing.T) {
+	d := NewWebhookDispatcher()
+	d.OnTaskStatusUpdated(func(ctx context.Context, e TaskStatusUpdatedEvent) error {
+		t.Error("handler called for an undecodable event")
+		return nil
+	})
+
+	err := d.Handle(context.Background(), &WebhookEvent{
+		Event:        WebhookEventTaskStatusUpdated,
+		HistoryItems: json.RawMessage(`[{"field":"status","after":"done"}]`),
+	})
+	var decodeErr *WebhookDecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Errorf("Handle returned %v, want a WebhookDecodeError", err)
+	}
+
+	wr := NewWebhookReceiver("s3cret")
+	wr.Handle(WebhookEventAll, d.Handle)
+	body := `{"event":"taskStatusUpdated","task_id":"9hz","history_items":[{"field":"status","after":"done"}]}`
+	if rec := deliverWebhook(wr, "s3cret", body); rec.Code != http.StatusOK {
+		t.Errorf("delivering %s: status = %d, want 200", body, rec.Code)
+	}
+
+	var other string
+	d.OnOther(func(ctx context.Context, event string, raw json.RawMessage) error {
+		other = string(raw)
+		return nil
+	})
+	if rec := deliverWebhook(wr, "s3cret", body); rec.Code != http.StatusOK {
+		t.Errorf("delivering %s: status = %d, want 200", body, rec.Code)
+	}
+	if other != body {
+		t.Errorf("OnOther got %s, want %s", other, body)
+	}
+}
@@ -0,0 +1,329 @@
+package clickup
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Webhook health statuses.
+const (
+	WebhookStatusActive    = "active"
+	WebhookStatusFailing   = "failing"
+	WebhookStatusSuspended = "suspended"
+)
+
+// WebhookHealthChange reports a change of the health of a webhook.
+type WebhookHealthChange struct {
+	Webhook Webhook
+	// New is set when the webhook is seen for the first time; the previous
+	// values are then empty.
+	New               bool
+	PreviousStatus    string
+	PreviousFailCount int
+}
+
+// WebhookDrift reports a declared webhook whose registration differs.
+type WebhookDrift struct {
+	Declared WebhookRequest
+	// Webhook is the registered webhook for the declared endpoint and
+	// location, or nil when it is missing.
+	Webhook *Webhook
+	// Fields names what differs: "events" or "location".
+	Fields []string
+}
+
+type WebhookMonitorOptions struct {
+	// Interval between checks; the default is one minute.
+	Interval time.Duration
+	// Reactivate sets suspended webhooks back to active, waiting MinBackoff
+	// after the first attempt and doubling up to MaxBackoff while the webhook
+	// stays suspended. The defaults are one minute and one hour.
+	Reactivate bool
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Declared is the expected configuration. Each is matched to a
+	// registered webhook by endpoint, and by location when several share it.
+	Declared []WebhookRequest
+
+	OnHealthChange func(change WebhookHealthChange)
+	// OnReactivate is called after each reactivation attempt.
+	OnReactivate func(webhook Webhook, err error)
+	// OnDrift is called when the drift found differs from the last check,
+	// including when it is resolved and drifts is empty.
+	OnDrift func(drifts []WebhookDrift)
+	// OnError is called by Run when a check fails.
+	OnError func(err error)
+}
+
+// WebhookMonitor watches the webhooks of a team.
+type WebhookMonitor struct {
+	client *Client
+	teamID int
+	opts   WebhookMonitorOptions
+	now    func() time.Time
+
+	mu        sync.Mutex
+	health    map[string]webhookHealth
+	attempts  map[string]webhookReactivation
+	lastDrift string
+}
+
+type webhookReactivation struct {
+	count int
+	next  time.Time
+}
+
+func NewWebhookMonitor(client *Client, teamID int, opts *WebhookMonitorOptions) *WebhookMonitor {
+	m := &WebhookMonitor{
+		client:   client,
+		teamID:   teamID,
+		now:      time.Now,
+		health:   map[string]webhookHealth{},
+		attempts: map[string]webhookReactivation{},
+	}
+	if opts != nil {
+		m.opts = *opts
+	}
+	if m.opts.Interval <= 0 {
+		m.opts.Interval = time.Minute
+	}
+	if m.opts.MinBackoff <= 0 {
+		m.opts.MinBackoff = time.Minute
+	}
+	if m.opts.MaxBackoff < m.opts.MinBackoff {
+		m.opts.MaxBackoff = max(time.Hour, m.opts.MinBackoff)
+	}
+
+	return m
+}
+
+// Run checks the webhooks every Interval until ctx is done.
+func (m *WebhookMonitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.Check(ctx); err != nil && ctx.Err() == nil && m.opts.OnError != nil {
+			m.opts.OnError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check fetches the webhooks of the team once, reports health changes and
+// drift, and reactivates suspended webhooks that are due. The callbacks are
+// called after the state of the monitor is updated, without holding its
+// lock, so they may call Check themselves.
+func (m *WebhookMonitor) Check(ctx context.Context) error {
+	webhooks, _, err := m.client.Webhooks.GetWebhook(ctx, m.teamID)
+	if err != nil {
+		return err
+	}
+
+	changes, due, drifts, drifted := m.update(webhooks)
+
+	if m.opts.OnHealthChange != nil {
+		for _, c := range changes {
+			m.opts.OnHealthChange(c)
+		}
+	}
+	for _, w := range due {
+		m.reactivate(ctx, w)
+	}
+	if drifted && m.opts.OnDrift != nil {
+		m.opts.OnDrift(drifts)
+	}
+
+	return nil
+}
+
+// update records the health of webhooks and returns the health changes, the
+// suspended webhooks due for reactivation, and the drift if it changed.
+func (m *WebhookMonitor) update(webhooks []Webhook) (changes []WebhookHealthChange, due []Webhook, drifts []WebhookDrift, drifted bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	seen := map[string]bool{}
+	for _, w := range webhooks {
+		seen[w.ID] = true
+
+		prev, known := m.health[w.ID]
+		m.health[w.ID] = w.Health
+		if !known || prev != w.Health {
+			changes = append(changes, WebhookHealthChange{
+				Webhook:           w,
+				New:               !known,
+				PreviousStatus:    prev.Status,
+				PreviousFailCount: prev.FailCount,
+			})
+		}
+
+		if w.Health.Status != WebhookStatusSuspended {
+			delete(m.attempts, w.ID)
+			continue
+		}
+		if m.opts.Reactivate && m.reactivationDue(w.ID, now) {
+			due = append(due, w)
+		}
+	}
+	for id := range m.health {
+		if !seen[id] {
+			delete(m.health, id)
+			delete(m.attempts, id)
+		}
+	}
+
+	if m.opts.Declared != nil {
+		drifts = WebhookDrifts(m.opts.Declared, webhooks)
+		if key := webhookDriftKey(drifts); key != m.lastDrift {
+			m.lastDrift = key
+			drifted = true
+		}
+	}
+
+	return changes, due, drifts, drifted
+}
+
+// reactivationDue reports whether the webhook id is due for a reactivation
+// attempt at now, and if so schedules the next one.
+func (m *WebhookMonitor) reactivationDue(id string, now time.Time) bool {
+	a := m.attempts[id]
+	if now.Before(a.next) {
+		return false
+	}
+
+	backoff := m.opts.MinBackoff
+	for i := 0; i < a.count && backoff < m.opts.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	m.attempts[id] = webhookReactivation{count: a.count + 1, next: now.Add(min(backoff, m.opts.MaxBackoff))}
+
+	return true
+}
+
+func (m *WebhookMonitor) reactivate(ctx context.Context, w Webhook) {
+	_, _, err := m.client.Webhooks.UpdateWebhook(ctx, w.ID, &WebhookRequest{
+		Endpoint: w.Endpoint,
+		Events:   w.Events,
+		Status:   WebhookStatusActive,
+	})
+	if m.opts.OnReactivate != nil {
+		m.opts.OnReactivate(w, err)
+	}
+}
+
+// WebhookDrifts compares declared webhooks with the registered ones. Each
+// declared webhook is matched to a registered one with the same endpoint,
+// preferring one with the same location.
+func WebhookDrifts(declared []WebhookRequest, registered []Webhook) []WebhookDrift {
+	var drifts []WebhookDrift
+	used := map[string]bool{}
+
+	for _, d := range declared {
+		w := matchWebhook(d, registered, used)
+		if w == nil {
+			drifts = append(drifts, WebhookDrift{Declared: d})
+			continue
+		}
+		used[w.ID] = true
+
+		var fields []string
+		if !webhookEventsMatch(d.Events, w.Events) {
+			fields = append(fields, "events")
+		}
+		if webhookRequestLocation(d) != webhookLocation(*w) {
+			fields = append(fields, "location")
+		}
+		if fields != nil {
+			drifts = append(drifts, WebhookDrift{Declared: d, Webhook: w, Fields: fields})
+		}
+	}
+
+	return drifts
+}
+
+// matchWebhook finds the registered webhook for d, by endpoint and then by
+// location.
+func matchWebhook(d WebhookRequest, registered []Webhook, used map[string]bool) *Webhook {
+	var candidate *Webhook
+	for i := range registered {
+		w := &registered[i]
+		if used[w.ID] || w.Endpoint != d.Endpoint {
+			continue
+		}
+		if webhookLocation(*w) == webhookRequestLocation(d) {
+			return w
+		}
+		if candidate == nil {
+			candidate = w
+		}
+	}
+
+	return candidate
+}
+
+// webhookLocation describes the location filter of a webhook, such as
+// "list:123", or "" for the whole team.
+func webhookLocation(w Webhook) string {
+	return webhookLocationKey(w.TaskID, w.ListID, w.FolderID, strconv.Itoa(w.SpaceID))
+}
+
+func webhookRequestLocation(r WebhookRequest) string {
+	return webhookLocationKey(r.TaskID, r.ListID, r.FolderID, r.SpaceID)
+}
+
+func webhookLocationKey(taskID string, listID int, folderID, spaceID string) string {
+	switch {
+	case taskID != "":
+		return "task:" + taskID
+	case listID != 0:
+		return "list:" + strconv.Itoa(listID)
+	case folderID != "" && folderID != "0":
+		return "folder:" + folderID
+	case spaceID != "" && spaceID != "0":
+		return "space:" + spaceID
+	}
+
+	return ""
+}
+
+// webhookEventsMatch reports whether a webhook registered for the registered
+// events satisfies the declared ones. Declaring "*" matches any events, as
+// ClickUp may list the events a "*" webhook subscribes to.
+func webhookEventsMatch(declared, registered []string) bool {
+	if len(declared) == 1 && declared[0] == WebhookEventAll {
+		return true
+	}
+
+	return strings.Join(sortedWebhookEvents(declared), ",") == strings.Join(sortedWebhookEvents(registered), ",")
+}
+
+func sortedWebhookEvents(events []string) []string {
+	sorted := append([]string{}, events...)
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+func webhookDriftKey(drifts []WebhookDrift) string {
+	var b strings.Builder
+	for _, d := range drifts {
+		b.WriteString(d.Declared.Endpoint + "|" + webhookRequestLocation(d.Declared) + "|")
+		if d.Webhook != nil {
+			b.WriteString(d.Webhook.ID)
+		}
+		b.WriteString("|" + strings.Join(d.Fields, ",") + "\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,218 @@
+package clickup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWebhookMonitor_Check(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	status, failCount := "active", 0
+	mux.HandleFunc("/team/1/webhook", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprintf(w, `{"webhooks": [{"id": "w1", "endpoint": "https://example.com/hook", "events": ["taskCreated"], "health": {"status": %q, "fail_count": %d}}]}`, status, failCount)
+	})
+	var reactivations []WebhookRequest
+	mux.HandleFunc("/webhook/w1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		var req WebhookRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		reactivations = append(reactivations, req)
+		fmt.Fprint(w, `{"id": "w1"}`)
+	})
+
+	var changes []string
+	m := NewWebhookMonitor(client, 1, &WebhookMonitorOptions{
+		Reactivate: true,
+		MinBackoff: time.Minute,
+		MaxBackoff: 3 * time.Minute,
+		OnHealthChange: func(c WebhookHealthChange) {
+			changes = append(changes, fmt.Sprintf("%s %v %s/%d -> %s/%d", c.Webhook.ID, c.New, c.PreviousStatus, c.PreviousFailCount, c.Webhook.Health.Status, c.Webhook.Health.FailCount))
+		},
+	})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	check := func() {
+		t.Helper()
+		if err := m.Check(ctx); err != nil {
+			t.Fatalf("Check returned error: %v", err)
+		}
+	}
+
+	check()
+	check()
+	failCount = 3
+	status = "failing"
+	check()
+	status = "suspended"
+	check()
+
+	wantChanges := []string{
+		"w1 true /0 -> active/0",
+		"w1 false active/0 -> failing/3",
+		"w1 false failing/3 -> suspended/3",
+	}
+	if !cmp.Equal(changes, wantChanges) {
+		t.Errorf("health changes = %q, want %q", changes, wantChanges)
+	}
+
+	// Reactivation backs off 1m, 2m, then stays at the 3m maximum.
+	want := []WebhookRequest{{Endpoint: "https://example.com/hook", Events: []string{"taskCreated"}, Status: "active"}}
+	if !cmp.Equal(reactivations, want) {
+		t.Fatalf("reactivations = %+v, want %+v", reactivations, want)
+	}
+	for _, step := range []struct {
+		wait  time.Duration
+		total int
+	}{
+		{59 * time.Second, 1}, {time.Second, 2},
+		{time.Minute, 2}, {time.Minute, 3},
+		{3 * time.Minute, 4}, {3 * time.Minute, 5},
+	} {
+		now = now.Add(step.wait)
+		check()
+		if len(reactivations) != step.total {
+			t.Errorf("after %v: %d reactivations, want %d", now.Sub(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)), len(reactivations), step.total)
+		}
+	}
+
+	// Recovery resets the backoff.
+	status = "active"
+	check()
+	status = "suspended"
+	check()
+	if len(reactivations) != 6 {
+		t.Errorf("after recovery: %d reactivations, want 6", len(reactivations))
+	}
+}
+
+func TestWebhookMonitor_drift(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	events := `["taskCreated"]`
+	mux.HandleFunc("/team/1/webhook", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"webhooks": [
+			{"id": "w1", "endpoint": "https://example.com/a", "events": %s, "list_id": 5, "health": {"status": "active"}},
+			{"id": "w2", "endpoint": "https://example.com/b", "events": ["taskCreated", "listCreated"], "health": {"status": "active"}}
+		]}`, events)
+	})
+
+	// w2 lists its events but is declared with "*", which is not drift.
+	var reports [][]WebhookDrift
+	declared := []WebhookRequest{
+		{Endpoint: "https://example.com/a", Events: []string{"taskCreated"}, ListID: 5},
+		{Endpoint: "https://example.com/b", Events: []string{"*"}, SpaceID: "9"},
+		{Endpoint: "https://example.com/c", Events: []string{"*"}},
+	}
+	m := NewWebhookMonitor(client, 1, &WebhookMonitorOptions{
+		Declared: declared,
+		OnDrift:  func(d []WebhookDrift) { reports = append(reports, d) },
+	})
+
+	ctx := context.Background()
+	m.Check(ctx)
+	m.Check(ctx)
+	events = `["taskCreated", "taskDeleted"]`
+	m.Check(ctx)
+
+	if len(reports) != 2 {
+		t.Fatalf("got %d drift reports, want 2", len(reports))
+	}
+
+	summary := func(drifts []WebhookDrift) []string {
+		var s []string
+		for _, d := range drifts {
+			id := "-"
+			if d.Webhook != nil {
+				id = d.Webhook.ID
+			}
+			s = append(s, fmt.Sprintf("%s %s %v", d.Declared.Endpoint, id, d.Fields))
+		}
+		return s
+	}
+	want := []string{"https://example.com/b w2 [location]", "https://example.com/c - []"}
+	if got := summary(reports[0]); !cmp.Equal(got, want) {
+		t.Errorf("first drift = %q, want %q", got, want)
+	}
+	want = append([]string{"https://example.com/a w1 [events]"}, want...)
+	if got := summary(reports[1]); !cmp.Equal(got, want) {
+		t.Errorf("second drift = %q, want %q", got, want)
+	}
+}
+
+func TestWebhookMonitor_reentrantCallbacks(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/team/1/webhook", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"webhooks": [{"id": "w1", "endpoint": "https://example.com/a", "events": ["*"], "health": {"status": "suspended"}}]}`)
+	})
+	mux.HandleFunc("/webhook/w1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": "w1"}`)
+	})
+
+	ctx := context.Background()
+	var m *WebhookMonitor
+	calls := 0
+	recheck := func() {
+		if calls++; calls > 10 {
+			return
+		}
+		if err := m.Check(ctx); err != nil {
+			t.Errorf("Check from a callback returned error: %v", err)
+		}
+	}
+	m = NewWebhookMonitor(client, 1, &WebhookMonitorOptions{
+		Reactivate:     true,
+		Declared:       []WebhookRequest{{Endpoint: "https://example.com/b"}},
+		OnHealthChange: func(WebhookHealthChange) { recheck() },
+		OnReactivate:   func(Webhook, error) { recheck() },
+		OnDrift:        func([]WebhookDrift) { recheck() },
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- m.Check(ctx) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Check returned error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Check deadlocked when a callback called Check")
+	}
+	// Each callback fires once: the nested checks see no new changes and
+	// the reactivation is backing off.
+	if calls != 3 {
+		t.Errorf("callbacks called %d times, want 3", calls)
+	}
+}
+
+func TestWebhookMonitor_Run(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/team/1/webhook", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"err": "down"}`, http.StatusInternalServerError)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := NewWebhookMonitor(client, 1, &WebhookMonitorOptions{
+		Interval: time.Millisecond,
+		OnError:  func(err error) { cancel() },
+	})
+
+	if err := m.Run(ctx); err != context.Canceled {
+		t.Errorf("Run returned %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,614 @@
+package clickup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QueuedWebhookEvent is a delivery waiting in a WebhookQueue.
+type QueuedWebhookEvent struct {
+	// Key identifies the delivery; see WebhookIdempotencyKey.
+	Key        string          `json:"key"`
+	Body       json.RawMessage `json:"body"`
+	ReceivedAt time.Time       `json:"received_at"`
+	// Attempts counts the failed attempts so far.
+	Attempts  int       `json:"attempts,omitempty"`
+	NotBefore time.Time `json:"not_before,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// WebhookQueue durably stores deliveries until they are processed.
+type WebhookQueue interface {
+	// Enqueue stores an event durably. Events whose key is queued or was
+	// recently processed are ignored.
+	Enqueue(ctx context.Context, e *QueuedWebhookEvent) error
+	// Dequeue returns the next due event, waiting until there is one. The
+	// event is not returned again until it is retried or the queue is
+	// reopened.
+	Dequeue(ctx context.Context) (*QueuedWebhookEvent, error)
+	// Ack removes a processed event.
+	Ack(ctx context.Context, key string) error
+	// Retry records a failed attempt and makes the event due at notBefore.
+	Retry(ctx context.Context, key string, notBefore time.Time, lastErr string) error
+}
+
+// WebhookDeadLetterStore keeps events that failed every attempt.
+type WebhookDeadLetterStore interface {
+	PutDeadLetter(ctx context.Context, e *QueuedWebhookEvent) error
+}
+
+// WebhookIdempotencyKey identifies a delivery by its webhook and history
+// item IDs, so a delivery retried by ClickUp gets the same key. Deliveries
+// without history items are identified by a hash of the body.
+func WebhookIdempotencyKey(e *WebhookEvent) string {
+	var items []struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(e.HistoryItems, &items)
+
+	var ids []string
+	for _, it := range items {
+		if it.ID != "" {
+			ids = append(ids, it.ID)
+		}
+	}
+	if len(ids) == 0 {
+		sum := sha256.Sum256(e.Raw)
+		return e.WebhookID + ":" + e.Event + ":" + hex.EncodeToString(sum[:])
+	}
+	sort.Strings(ids)
+
+	return e.WebhookID + ":" + strings.Join(ids, ",")
+}
+
+// WebhookQueueHandler returns a handler for a WebhookReceiver that stores
+// deliveries in q. The receiver only acknowledges a delivery once it is
+// stored; a WebhookProcessor then handles it.
+func WebhookQueueHandler(q WebhookQueue) WebhookHandlerFunc {
+	return func(ctx context.Context, event *WebhookEvent) error {
+		return q.Enqueue(ctx, &QueuedWebhookEvent{
+			Key:        WebhookIdempotencyKey(event),
+			Body:       event.Raw,
+			ReceivedAt: time.Now(),
+		})
+	}
+}
+
+// webhookQueueDoneKeys is how many processed keys a FileWebhookQueue
+// remembers to drop duplicate deliveries, and how many records its log holds
+// at least before it is compacted.
+const webhookQueueDoneKeys = 10000
+
+type webhookQueueRecord struct {
+	Op        string              `json:"op"`
+	Event     *QueuedWebhookEvent `json:"event,omitempty"`
+	Key       string              `json:"key,omitempty"`
+	NotBefore *time.Time          `json:"not_before,omitempty"`
+	Error     string              `json:"error,omitempty"`
+}
+
+// FileWebhookQueue is a WebhookQueue kept in an append-only log file. Every
+// change is synced to disk before it returns. The log is compacted when the
+// queue is opened, and when most of its records are obsolete; a failed
+// compaction does not fail the change that triggered it, is reported by
+// CompactErr and is retried on the next change.
+type FileWebhookQueue struct {
+	path       string
+	now        func() time.Time
+	minCompact int
+
+	mu        sync.Mutex
+	f         *os.File
+	pending   []*QueuedWebhookEvent
+	leased    map[string]bool
+	done      map[string]bool
+	doneOrder []string
+	records   int
+	// compactErr is the error of the last compaction after a write.
+	compactErr error
+	// changed is closed, and replaced, when an event may have become due.
+	changed chan struct{}
+}
+
+// OpenFileWebhookQueue opens the queue at path, creating it if needed, and
+// restores the events that were not acknowledged.
+func OpenFileWebhookQueue(path string) (*FileWebhookQueue, error) {
+	q := &FileWebhookQueue{
+		path:       path,
+		now:        time.Now,
+		minCompact: webhookQueueDoneKeys,
+		leased:     map[string]bool{},
+		done:       map[string]bool{},
+		changed:    make(chan struct{}),
+	}
+
+	if err := q.replay(); err != nil {
+		return nil, err
+	}
+	if err := q.compact(); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+func (q *FileWebhookQueue) replay() error {
+	b, err := os.ReadFile(q.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	lines := bytes.Split(b, []byte("\n"))
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var r webhookQueueRecord
+		if err := json.Unmarshal(line, &r); err != nil {
+			if i == len(lines)-1 {
+				// A write cut short by a crash; it was never acknowledged.
+				break
+			}
+			return fmt.Errorf("reading webhook queue %s line %d: %w", q.path, i+1, err)
+		}
+		q.apply(&r)
+	}
+
+	return nil
+}
+
+// apply updates the in-memory state for a record.
+func (q *FileWebhookQueue) apply(r *webhookQueueRecord) {
+	switch r.Op {
+	case "enqueue":
+		q.pending = append(q.pending, r.Event)
+	case "ack":
+		for i, e := range q.pending {
+			if e.Key == r.Key {
+				q.pending = append(q.pending[:i], q.pending[i+1:]...)
+				break
+			}
+		}
+		delete(q.leased, r.Key)
+		q.markDone(r.Key)
+	case "retry":
+		if e := q.find(r.Key); e != nil {
+			e.Attempts++
+			e.LastError = r.Error
+			if r.NotBefore != nil {
+				e.NotBefore = *r.NotBefore
+			}
+		}
+		delete(q.leased, r.Key)
+	}
+}
+
+func (q *FileWebhookQueue) markDone(key string) {
+	if q.done[key] {
+		return
+	}
+	q.done[key] = true
+	q.doneOrder = append(q.doneOrder, key)
+	if len(q.doneOrder) > webhookQueueDoneKeys {
+		delete(q.done, q.doneOrder[0])
+		q.doneOrder = q.doneOrder[1:]
+	}
+}
+
+func (q *FileWebhookQueue) find(key string) *QueuedWebhookEvent {
+	for _, e := range q.pending {
+		if e.Key == key {
+			return e
+		}
+	}
+
+	return nil
+}
+
+// compact rewrites the log with only the remembered keys and pending events.
+func (q *FileWebhookQueue) compact() error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, key := range q.doneOrder {
+		if err := enc.Encode(&webhookQueueRecord{Op: "ack", Key: key}); err != nil {
+			return err
+		}
+	}
+	for _, e := range q.pending {
+		if err := enc.Encode(&webhookQueueRecord{Op: "enqueue", Event: e}); err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(q.path), filepath.Base(q.path)+".*")
+	if err != nil {
+		return err
+	}
+	fail := func(err error) error {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		return fail(err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fail(err)
+	}
+	if err := os.Rename(tmp.Name(), q.path); err != nil {
+		return fail(err)
+	}
+
+	// Keep appending to the new log through the file just written, so no
+	// failure can leave q.f pointing at the replaced one.
+	if q.f != nil {
+		q.f.Close()
+	}
+	q.f = tmp
+	q.records = len(q.doneOrder) + len(q.pending)
+	return nil
+}
+
+// CompactErr returns the error of the last compaction, or nil if it
+// succeeded. Compaction is retried on every change until it succeeds.
+func (q *FileWebhookQueue) CompactErr() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.compactErr
+}
+
+// Close closes the log file.
+func (q *FileWebhookQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.f.Close()
+}
+
+// Len returns the number of events not yet acknowledged.
+func (q *FileWebhookQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.pending)
+}
+
+// write appends a record and syncs it to disk. q.mu must be held.
+func (q *FileWebhookQueue) write(r *webhookQueueRecord) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	if _, err := q.f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	if err := q.f.Sync(); err != nil {
+		return err
+	}
+
+	q.apply(r)
+	q.records++
+	if q.records > q.minCompact && q.records > 2*(len(q.pending)+len(q.doneOrder)) {
+		// The record is committed either way.
+		q.compactErr = q.compact()
+	}
+
+	return nil
+}
+
+func (q *FileWebhookQueue) Enqueue(ctx context.Context, e *QueuedWebhookEvent) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.done[e.Key] || q.find(e.Key) != nil {
+		return nil
+	}
+	if err := q.write(&webhookQueueRecord{Op: "enqueue", Event: e}); err != nil {
+		return err
+	}
+	q.signal()
+
+	return nil
+}
+
+func (q *FileWebhookQueue) Dequeue(ctx context.Context) (*QueuedWebhookEvent, error) {
+	for {
+		q.mu.Lock()
+		now := q.now()
+		changed := q.changed
+		var next time.Time
+		for _, e := range q.pending {
+			if q.leased[e.Key] {
+				continue
+			}
+			if !e.NotBefore.After(now) {
+				q.leased[e.Key] = true
+				q.mu.Unlock()
+				c := *e
+				return &c, nil
+			}
+			if next.IsZero() || e.NotBefore.Before(next) {
+				next = e.NotBefore
+			}
+		}
+		q.mu.Unlock()
+
+		var timer *time.Timer
+		var due <-chan time.Time
+		if !next.IsZero() {
+			timer = time.NewTimer(next.Sub(now))
+			due = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-changed:
+		case <-due:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (q *FileWebhookQueue) Ack(ctx context.Context, key string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.write(&webhookQueueRecord{Op: "ack", Key: key}); err != nil {
+		// Let the event be dequeued again rather than hold it forever.
+		delete(q.leased, key)
+		q.signal()
+		return err
+	}
+
+	return nil
+}
+
+func (q *FileWebhookQueue) Retry(ctx context.Context, key string, notBefore time.Time, lastErr string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.write(&webhookQueueRecord{Op: "retry", Key: key, NotBefore: &notBefore, Error: lastErr}); err != nil {
+		delete(q.leased, key)
+		q.signal()
+		return err
+	}
+	q.signal()
+
+	return nil
+}
+
+// signal wakes every waiting Dequeue. q.mu must be held.
+func (q *FileWebhookQueue) signal() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+// FileWebhookDeadLetters is a WebhookDeadLetterStore appending events to a
+// file as JSON lines.
+type FileWebhookDeadLetters struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileWebhookDeadLetters(path string) *FileWebhookDeadLetters {
+	return &FileWebhookDeadLetters{path: path}
+}
+
+func (d *FileWebhookDeadLetters) PutDeadLetter(ctx context.Context, e *QueuedWebhookEvent) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(d.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// DeadLetters reads the events in the store.
+func (d *FileWebhookDeadLetters) DeadLetters() ([]QueuedWebhookEvent, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	f, err := os.Open(d.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []QueuedWebhookEvent
+	dec := json.NewDecoder(f)
+	for {
+		var e QueuedWebhookEvent
+		if err := dec.Decode(&e); err == io.EOF {
+			return events, nil
+		} else if err != nil {
+			return events, err
+		}
+		events = append(events, e)
+	}
+}
+
+type WebhookProcessorOptions struct {
+	// Workers is the number of events processed concurrently; the default
+	// is one.
+	Workers int
+	// MaxAttempts is how often an event is tried before it becomes a dead
+	// letter; the default is five.
+	MaxAttempts int
+	// Backoff returns the delay after a failed attempt; attempts starts at
+	// one. The default doubles from one second up to five minutes.
+	Backoff func(attempts int) time.Duration
+	// DeadLetters receives events that failed every attempt. Without it
+	// they are dropped.
+	DeadLetters WebhookDeadLetterStore
+	// OnError is called for each failed attempt and queue error.
+	OnError func(e *QueuedWebhookEvent, err error)
+}
+
+// WebhookProcessor handles the events of a WebhookQueue with a pool of
+// workers, retrying failed events. Handlers must tolerate seeing an event
+// more than once, since an event is retried when processing is interrupted.
+type WebhookProcessor struct {
+	queue   WebhookQueue
+	handler WebhookHandlerFunc
+	opts    WebhookProcessorOptions
+	now     func() time.Time
+}
+
+func NewWebhookProcessor(queue WebhookQueue, handler WebhookHandlerFunc, opts *WebhookProcessorOptions) *WebhookProcessor {
+	p := &WebhookProcessor{queue: queue, handler: handler, now: time.Now}
+	if opts != nil {
+		p.opts = *opts
+	}
+	if p.opts.Workers <= 0 {
+		p.opts.Workers = 1
+	}
+	if p.opts.MaxAttempts <= 0 {
+		p.opts.MaxAttempts = 5
+	}
+	if p.opts.Backoff == nil {
+		p.opts.Backoff = defaultWebhookBackoff
+	}
+
+	return p
+}
+
+// defaultWebhookBackoff doubles from one second up to five minutes.
+func defaultWebhookBackoff(attempts int) time.Duration {
+	d := time.Second
+	for i := 1; i < attempts && d < 5*time.Minute; i++ {
+		d *= 2
+	}
+
+	return min(d, 5*time.Minute)
+}
+
+// Run processes events until ctx is done.
+func (p *WebhookProcessor) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for i := 0; i < p.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				e, err := p.queue.Dequeue(ctx)
+				if ctx.Err() != nil {
+					return
+				}
+				if err != nil {
+					p.report(nil, err)
+					select {
+					case <-ctx.Done():
+					case <-time.After(time.Second):
+					}
+					continue
+				}
+				p.process(ctx, e)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+func (p *WebhookProcessor) process(ctx context.Context, e *QueuedWebhookEvent) {
+	event := &WebhookEvent{}
+	if err := json.Unmarshal(e.Body, event); err != nil {
+		// A body that cannot be decoded never will be.
+		p.fail(ctx, e, err, true)
+		return
+	}
+	event.Raw = e.Body
+
+	if err := p.handler(ctx, event); err != nil {
+		if ctx.Err() != nil {
+			// Interrupted; the event is retried when the queue is reopened.
+			return
+		}
+		var decodeErr *WebhookDecodeError
+		p.fail(ctx, e, err, errors.As(err, &decodeErr))
+		return
+	}
+
+	if err := p.queue.Ack(ctx, e.Key); err != nil {
+		p.report(e, err)
+	}
+}
+
+// fail retries e, or moves it to the dead letters after the last attempt or
+// when the failure is permanent.
+func (p *WebhookProcessor) fail(ctx context.Context, e *QueuedWebhookEvent, err error, permanent bool) {
+	p.report(e, err)
+
+	e.Attempts++
+	e.LastError = err.Error()
+	if !permanent && e.Attempts < p.opts.MaxAttempts {
+		if err := p.queue.Retry(ctx, e.Key, p.now().Add(p.opts.Backoff(e.Attempts)), e.LastError); err != nil {
+			p.report(e, err)
+		}
+		return
+	}
+
+	if p.opts.DeadLetters != nil {
+		if err := p.opts.DeadLetters.PutDeadLetter(ctx, e); err != nil {
+			// Keep the event rather than lose it.
+			p.report(e, err)
+			if err := p.queue.Retry(ctx, e.Key, p.now().Add(p.opts.Backoff(e.Attempts)), e.LastError); err != nil {
+				p.report(e, err)
+			}
+			return
+		}
+	}
+	if err := p.queue.Ack(ctx, e.Key); err != nil {
+		p.report(e, err)
+	}
+}
+
+func (p *WebhookProcessor) report(e *QueuedWebhookEvent, err error) {
+	if p.opts.OnError != nil {
+		p.opts.OnError(e, err)
+	}
+}
@@ -0,0 +1,403 @@
+package clickup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func queuedEvent(key, body string) *QueuedWebhookEvent {
+	return &QueuedWebhookEvent{Key: key, Body: json.RawMessage(body)}
+}
+
+func TestWebhookIdempotencyKey(t *testing.T) {
+	a := &WebhookEvent{WebhookID: "wh1", Event: "taskUpdated", HistoryItems: json.RawMessage(`[{"id": "2"}, {"id": "1"}]`), Raw: json.RawMessage(`{"a":1}`)}
+	if got, want := WebhookIdempotencyKey(a), "wh1:1,2"; got != want {
+		t.Errorf("WebhookIdempotencyKey = %q, want %q", got, want)
+	}
+
+	b := &WebhookEvent{WebhookID: "wh1", Event: "goalCreated", Raw: json.RawMessage(`{"goal_id":"g1"}`)}
+	c := &WebhookEvent{WebhookID: "wh1", Event: "goalCreated", Raw: json.RawMessage(`{"goal_id":"g2"}`)}
+	if WebhookIdempotencyKey(b) == WebhookIdempotencyKey(c) {
+		t.Error("WebhookIdempotencyKey is the same for different deliveries without history items")
+	}
+	if WebhookIdempotencyKey(b) != WebhookIdempotencyKey(b) {
+		t.Error("WebhookIdempotencyKey is not stable")
+	}
+}
+
+func TestFileWebhookQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.log")
+	ctx := context.Background()
+
+	q, err := OpenFileWebhookQueue(path)
+	if err != nil {
+		t.Fatalf("OpenFileWebhookQueue returned error: %v", err)
+	}
+	for _, key := range []string{"a", "b", "a", "c"} {
+		if err := q.Enqueue(ctx, queuedEvent(key, `{"event":"taskCreated"}`)); err != nil {
+			t.Fatalf("Enqueue returned error: %v", err)
+		}
+	}
+	if q.Len() != 3 {
+		t.Errorf("Len = %d, want 3 after a duplicate", q.Len())
+	}
+
+	e, err := q.Dequeue(ctx)
+	if err != nil || e.Key != "a" {
+		t.Fatalf("Dequeue = %+v, %v, want a", e, err)
+	}
+	q.Ack(ctx, "a")
+	e, _ = q.Dequeue(ctx)
+	q.Retry(ctx, e.Key, time.Now().Add(time.Hour), "boom")
+	q.Close()
+
+	// Simulate a crash in the middle of a write.
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	f.WriteString(`{"op":"enqueue","event":{"key":"d"`)
+	f.Close()
+
+	q, err = OpenFileWebhookQueue(path)
+	if err != nil {
+		t.Fatalf("reopening returned error: %v", err)
+	}
+	defer q.Close()
+
+	if q.Len() != 2 {
+		t.Errorf("Len after reopening = %d, want 2", q.Len())
+	}
+	q.Enqueue(ctx, queuedEvent("a", `{}`))
+	if q.Len() != 2 {
+		t.Error("an acknowledged key was queued again after reopening")
+	}
+
+	// b waits for its retry, so c comes first.
+	e, err = q.Dequeue(ctx)
+	if err != nil || e.Key != "c" {
+		t.Fatalf("Dequeue after reopening = %+v, %v, want c", e, err)
+	}
+
+	short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if e, err := q.Dequeue(short); err != context.DeadlineExceeded {
+		t.Errorf("Dequeue = %+v, %v, want to wait for b", e, err)
+	}
+
+	q.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	e, err = q.Dequeue(ctx)
+	if err != nil || e.Key != "b" || e.Attempts != 1 || e.LastError != "boom" {
+		t.Errorf("Dequeue = %+v, %v, want b after one attempt", e, err)
+	}
+}
+
+func TestFileWebhookQueue_DequeueWaits(t *testing.T) {
+	q, err := OpenFileWebhookQueue(filepath.Join(t.TempDir(), "queue.log"))
+	if err != nil {
+		t.Fatalf("OpenFileWebhookQueue returned error: %v", err)
+	}
+	defer q.Close()
+
+	ctx := context.Background()
+	got := make(chan string)
+	go func() {
+		e, _ := q.Dequeue(ctx)
+		got <- e.Key
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	q.Enqueue(ctx, queuedEvent("a", `{}`))
+
+	select {
+	case key := <-got:
+		if key != "a" {
+			t.Errorf("Dequeue = %q, want a", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Dequeue did not wake up for a new event")
+	}
+}
+
+func TestFileWebhookQueue_DequeueIdle(t *testing.T) {
+	q, err := OpenFileWebhookQueue(filepath.Join(t.TempDir(), "queue.log"))
+	if err != nil {
+		t.Fatalf("OpenFileWebhookQueue returned error: %v", err)
+	}
+	defer q.Close()
+
+	ctx := context.Background()
+	q.Enqueue(ctx, queuedEvent("a", `{}`))
+	q.Dequeue(ctx)
+
+	// Each pass over the queue reads the clock once.
+	var mu sync.Mutex
+	scans := 0
+	q.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		scans++
+		return time.Now()
+	}
+
+	short, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if e, err := q.Dequeue(short); err != context.DeadlineExceeded {
+		t.Fatalf("Dequeue = %+v, %v, want to wait", e, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if scans > 2 {
+		t.Errorf("idle Dequeue scanned the queue %d times, want it to block", scans)
+	}
+}
+
+func TestFileWebhookQueue_DequeueWakesAll(t *testing.T) {
+	q, err := OpenFileWebhookQueue(filepath.Join(t.TempDir(), "queue.log"))
+	if err != nil {
+		t.Fatalf("OpenFileWebhookQueue returned error: %v", err)
+	}
+	defer q.Close()
+
+	ctx := context.Background()
+	got := make(chan string, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			e, _ := q.Dequeue(ctx)
+			got <- e.Key
+		}()
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	q.mu.Lock()
+	q.write(&webhookQueueRecord{Op: "enqueue", Event: queuedEvent("a", `{}`)})
+	q.write(&webhookQueueRecord{Op: "enqueue", Event: queuedEvent("b", `{}`)})
+	q.signal()
+	q.mu.Unlock()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-got:
+		case <-time.After(time.Second):
+			t.Fatal("a waiting Dequeue did not wake up for a new event")
+		}
+	}
+}
+
+func TestFileWebhookQueue_compact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.log")
+	q, err := OpenFileWebhookQueue(path)
+	if err != nil {
+		t.Fatalf("OpenFileWebhookQueue returned error: %v", err)
+	}
+	q.minCompact = 10
+
+	// An event that keeps failing is never acknowledged, yet its retries
+	// must not grow the log without bound.
+	ctx := context.Background()
+	q.Enqueue(ctx, queuedEvent("a", `{}`))
+	for i := 0; i < 50; i++ {
+		q.Retry(ctx, "a", time.Time{}, "boom")
+	}
+	q.Close()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile returned error: %v", err)
+	}
+	if n := bytes.Count(b, []byte("\n")); n > 10 {
+		t.Errorf("log has %d records after compaction, want at most 10", n)
+	}
+
+	q, err = OpenFileWebhookQueue(path)
+	if err != nil {
+		t.Fatalf("reopening returned error: %v", err)
+	}
+	defer q.Close()
+	e, err := q.Dequeue(ctx)
+	if err != nil || e.Key != "a" || e.Attempts != 50 {
+		t.Errorf("Dequeue after reopening = %+v, %v, want a after 50 attempts", e, err)
+	}
+}
+
+func TestFileWebhookQueue_compactFails(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "queue")
+	os.Mkdir(dir, 0o700)
+	path := filepath.Join(dir, "queue.log")
+	q, err := OpenFileWebhookQueue(path)
+	if err != nil {
+		t.Fatalf("OpenFileWebhookQueue returned error: %v", err)
+	}
+	defer q.Close()
+	q.minCompact = 2
+
+	// Without the directory the log can still be appended to, but not
+	// compacted.
+	os.RemoveAll(dir)
+	ctx := context.Background()
+	if err := q.Enqueue(ctx, queuedEvent("a", `{}`)); err != nil {
+		t.Fatalf("Enqueue returned error: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := q.Retry(ctx, "a", time.Time{}, "boom"); err != nil {
+			t.Fatalf("Retry returned error: %v", err)
+		}
+	}
+	if q.CompactErr() == nil {
+		t.Error("CompactErr = nil, want the failed compaction")
+	}
+
+	os.Mkdir(dir, 0o700)
+	if err := q.Retry(ctx, "a", time.Time{}, "boom"); err != nil {
+		t.Fatalf("Retry returned error: %v", err)
+	}
+	if err := q.CompactErr(); err != nil {
+		t.Errorf("CompactErr = %v after the directory came back, want nil", err)
+	}
+	q.Close()
+
+	q, err = OpenFileWebhookQueue(path)
+	if err != nil {
+		t.Fatalf("reopening returned error: %v", err)
+	}
+	defer q.Close()
+	e, err := q.Dequeue(ctx)
+	if err != nil || e.Key != "a" || e.Attempts != 6 {
+		t.Errorf("Dequeue after reopening = %+v, %v, want a after 6 attempts", e, err)
+	}
+}
+
+func TestFileWebhookQueue_AckFails(t *testing.T) {
+	q, err := OpenFileWebhookQueue(filepath.Join(t.TempDir(), "queue.log"))
+	if err != nil {
+		t.Fatalf("OpenFileWebhookQueue returned error: %v", err)
+	}
+	ctx := context.Background()
+	q.Enqueue(ctx, queuedEvent("a", `{}`))
+	q.Dequeue(ctx)
+
+	q.Close()
+	if err := q.Ack(ctx, "a"); err == nil {
+		t.Fatal("Ack on a closed log returned no error")
+	}
+
+	// The lease is released, so the event is handled again.
+	short, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if e, err := q.Dequeue(short); err != nil || e.Key != "a" {
+		t.Errorf("Dequeue after a failed Ack = %+v, %v, want a", e, err)
+	}
+}
+
+func TestWebhookProcessor(t *testing.T) {
+	dir := t.TempDir()
+	q, err := OpenFileWebhookQueue(filepath.Join(dir, "queue.log"))
+	if err != nil {
+		t.Fatalf("OpenFileWebhookQueue returned error: %v", err)
+	}
+	defer q.Close()
+	dead := NewFileWebhookDeadLetters(filepath.Join(dir, "dead.jsonl"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	calls := map[string]int{}
+	handler := func(ctx context.Context, e *WebhookEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[e.TaskID]++
+		switch {
+		case e.TaskID == "flaky" && calls[e.TaskID] < 3:
+			return errors.New("try again")
+		case e.TaskID == "broken":
+			return errors.New("always fails")
+		case e.TaskID == "undecodable":
+			return &WebhookDecodeError{Event: e.Event, Err: errors.New("bad history")}
+		}
+		return nil
+	}
+
+	for _, body := range []string{
+		`{"event":"taskCreated","task_id":"ok"}`,
+		`{"event":"taskCreated","task_id":"flaky"}`,
+		`{"event":"taskCreated","task_id":"broken"}`,
+		`{"event":"taskCreated","task_id":"undecodable"}`,
+		`["not an event"]`,
+	} {
+		if err := q.Enqueue(ctx, queuedEvent(body, body)); err != nil {
+			t.Fatalf("Enqueue returned error: %v", err)
+		}
+	}
+
+	p := NewWebhookProcessor(q, handler, &WebhookProcessorOptions{
+		Workers:     2,
+		MaxAttempts: 3,
+		Backoff:     func(int) time.Duration { return time.Millisecond },
+		DeadLetters: dead,
+	})
+
+	done := make(chan error)
+	go func() { done <- p.Run(ctx) }()
+	for deadline := time.Now().Add(5 * time.Second); q.Len() > 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("queue has %d events left, want 0", q.Len())
+		}
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run returned %v, want context.Canceled", err)
+	}
+	if calls["ok"] != 1 || calls["flaky"] != 3 || calls["broken"] != 3 || calls["undecodable"] != 1 {
+		t.Errorf("calls = %v", calls)
+	}
+
+	letters, err := dead.DeadLetters()
+	if err != nil {
+		t.Fatalf("DeadLetters returned error: %v", err)
+	}
+	keys := map[string]int{}
+	for _, l := range letters {
+		keys[l.Key] = l.Attempts
+	}
+	if len(letters) != 3 || keys[`{"event":"taskCreated","task_id":"broken"}`] != 3 ||
+		keys[`{"event":"taskCreated","task_id":"undecodable"}`] != 1 || keys[`["not an event"]`] != 1 {
+		t.Errorf("dead letters = %+v", letters)
+	}
+}
+
+func TestWebhookQueueHandler(t *testing.T) {
+	q, err := OpenFileWebhookQueue(filepath.Join(t.TempDir(), "queue.log"))
+	if err != nil {
+		t.Fatalf("OpenFileWebhookQueue returned error: %v", err)
+	}
+
+	wr := NewWebhookReceiver("s3cret")
+	wr.Handle(WebhookEventAll, WebhookQueueHandler(q))
+
+	body := `{"event":"taskCreated","webhook_id":"wh1","history_items":[{"id":"1"}]}`
+	for i := 0; i < 2; i++ {
+		if rec := deliverWebhook(wr, "s3cret", body); rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want 200", rec.Code)
+		}
+	}
+	if q.Len() != 1 {
+		t.Errorf("Len = %d, want 1 after a redelivery", q.Len())
+	}
+
+	e, _ := q.Dequeue(context.Background())
+	if string(e.Body) != body || e.Key != "wh1:1" {
+		t.Errorf("queued %+v", e)
+	}
+
+	// A delivery that cannot be stored is not acknowledged.
+	q.Close()
+	if rec := deliverWebhook(wr, "s3cret", `{"event":"taskCreated","webhook_id":"wh1","history_items":[{"id":"2"}]}`); rec.Code != http.StatusInternalServerError {
+		t.Errorf("status with a closed queue = %d, want 500", rec.Code)
+	}
+}
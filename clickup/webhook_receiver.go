@@ -0,0 +1,176 @@
+package clickup
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DefaultWebhookMaxBodyBytes is the largest delivery a WebhookReceiver
+// accepts unless MaxBodyBytes is set.
+const DefaultWebhookMaxBodyBytes = 1 << 20
+
+// WebhookSignatureHeader carries the hex HMAC-SHA256 of the body, keyed
+// with the webhook secret.
+const WebhookSignatureHeader = "X-Signature"
+
+// WebhookEvent is a webhook delivery from ClickUp. Which IDs are set depends
+// on the event.
+type WebhookEvent struct {
+	Event        string          `json:"event"`
+	WebhookID    string          `json:"webhook_id"`
+	TaskID       string          `json:"task_id,omitempty"`
+	ListID       json.Number     `json:"list_id,omitempty"`
+	FolderID     json.Number     `json:"folder_id,omitempty"`
+	SpaceID      json.Number     `json:"space_id,omitempty"`
+	GoalID       string          `json:"goal_id,omitempty"`
+	KeyResultID  string          `json:"key_result_id,omitempty"`
+	HistoryItems json.RawMessage `json:"history_items,omitempty"`
+	// Raw is the body as delivered.
+	Raw json.RawMessage `json:"-"`
+}
+
+// WebhookHandlerFunc handles a verified delivery. Returning an error makes
+// the receiver respond 500 so ClickUp retries the delivery, except for a
+// *WebhookDecodeError, which no retry can fix.
+type WebhookHandlerFunc func(ctx context.Context, event *WebhookEvent) error
+
+// WebhookReceiver is an http.Handler for ClickUp webhook deliveries. It
+// verifies the signature of each delivery and passes it to the handlers
+// registered for its event.
+//
+// Deliveries are acknowledged with 200 even when no handler is registered or
+// a handler cannot decode them, so unhandled events do not count against the
+// health of the webhook. Bad
+// signatures get 401, oversized bodies 413 and malformed payloads 400.
+type WebhookReceiver struct {
+	// MaxBodyBytes limits the size of a delivery; 0 means
+	// DefaultWebhookMaxBodyBytes.
+	MaxBodyBytes int64
+
+	mu       sync.RWMutex
+	secrets  []string
+	handlers map[string][]WebhookHandlerFunc
+}
+
+// NewWebhookReceiver returns a receiver accepting deliveries signed with any
+// of secrets.
+func NewWebhookReceiver(secrets ...string) *WebhookReceiver {
+	return &WebhookReceiver{secrets: secrets, handlers: map[string][]WebhookHandlerFunc{}}
+}
+
+// SetSecrets replaces the accepted secrets. To rotate a secret without
+// dropping deliveries, accept both the old and new secrets until the webhook
+// is updated, then remove the old one.
+func (wr *WebhookReceiver) SetSecrets(secrets ...string) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	wr.secrets = append([]string{}, secrets...)
+}
+
+// Handle registers h for an event such as "taskCreated", or for every event
+// with "*". Handlers run in the order they were registered.
+func (wr *WebhookReceiver) Handle(event string, h WebhookHandlerFunc) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	wr.handlers[event] = append(wr.handlers[event], h)
+}
+
+func (wr *WebhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := wr.MaxBodyBytes
+	if limit <= 0 {
+		limit = DefaultWebhookMaxBodyBytes
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "reading payload failed", http.StatusBadRequest)
+		return
+	}
+
+	wr.mu.RLock()
+	secrets := wr.secrets
+	wr.mu.RUnlock()
+
+	if !verifyWebhookSignature(secrets, body, r.Header.Get(WebhookSignatureHeader)) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	event := &WebhookEvent{}
+	if err := json.Unmarshal(body, event); err != nil || event.Event == "" {
+		http.Error(w, "malformed payload", http.StatusBadRequest)
+		return
+	}
+	event.Raw = body
+
+	wr.mu.RLock()
+	handlers := append(append([]WebhookHandlerFunc{}, wr.handlers[event.Event]...), wr.handlers["*"]...)
+	wr.mu.RUnlock()
+
+	for _, h := range handlers {
+		if err := h(r.Context(), event); err != nil {
+			var decodeErr *WebhookDecodeError
+			if errors.As(err, &decodeErr) {
+				continue
+			}
+			http.Error(w, "handling event failed", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// SignWebhookPayload returns the signature ClickUp sends for body.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature reports whether signature is the signature of body
+// for secret. The comparison takes constant time.
+func VerifyWebhookSignature(secret string, body []byte, signature string) bool {
+	return verifyWebhookSignature([]string{secret}, body, signature)
+}
+
+func verifyWebhookSignature(secrets []string, body []byte, signature string) bool {
+	got, err := hex.DecodeString(strings.TrimSpace(signature))
+	if err != nil || len(got) == 0 {
+		return false
+	}
+
+	ok := false
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		if hmac.Equal(mac.Sum(nil), got) {
+			ok = true
+		}
+	}
+
+	return ok
+}
@@ -0,0 +1,136 @@
+package clickup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func deliverWebhook(wr http.Handler, secret, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/webhook", strings.NewReader(body))
+	if secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(secret, []byte(body)))
+	}
+	rec := httptest.NewRecorder()
+	wr.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestWebhookReceiver(t *testing.T) {
+	wr := NewWebhookReceiver("s3cret")
+
+	var got []string
+	wr.Handle("taskCreated", func(ctx context.Context, event *WebhookEvent) error {
+		got = append(got, "created:"+event.TaskID+":"+event.WebhookID)
+		return nil
+	})
+	wr.Handle("*", func(ctx context.Context, event *WebhookEvent) error {
+		got = append(got, "any:"+event.Event+":"+event.ListID.String())
+		return nil
+	})
+
+	body := `{"event":"taskCreated","task_id":"9hz","webhook_id":"wh1","history_items":[]}`
+	if rec := deliverWebhook(wr, "s3cret", body); rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+	if rec := deliverWebhook(wr, "s3cret", `{"event":"listUpdated","list_id":"162","webhook_id":"wh1"}`); rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+
+	want := []string{"created:9hz:wh1", "any:taskCreated:", "any:listUpdated:162"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("handled %v, want %v", got, want)
+	}
+}
+
+func TestWebhookReceiver_rejects(t *testing.T) {
+	wr := NewWebhookReceiver("s3cret")
+	wr.MaxBodyBytes = 64
+	called := false
+	wr.Handle("*", func(ctx context.Context, event *WebhookEvent) error {
+		called = true
+		return nil
+	})
+
+	body := `{"event":"taskCreated","task_id":"9hz"}`
+	for name, tt := range map[string]struct {
+		secret, body string
+		want         int
+	}{
+		"unsigned":      {"", body, http.StatusUnauthorized},
+		"wrong secret":  {"other", body, http.StatusUnauthorized},
+		"too large":     {"s3cret", `{"event":"taskCreated","task_id":"` + strings.Repeat("x", 64) + `"}`, http.StatusRequestEntityTooLarge},
+		"malformed":     {"s3cret", `{"event":`, http.StatusBadRequest},
+		"missing event": {"s3cret", `{"task_id":"9hz"}`, http.StatusBadRequest},
+	} {
+		if rec := deliverWebhook(wr, tt.secret, tt.body); rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", name, rec.Code, tt.want)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/webhook", nil)
+	rec := httptest.NewRecorder()
+	wr.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: status = %d, want 405", rec.Code)
+	}
+
+	if called {
+		t.Error("handler called for a rejected delivery")
+	}
+}
+
+func TestWebhookReceiver_handlerError(t *testing.T) {
+	wr := NewWebhookReceiver("s3cret")
+	wr.Handle("taskDeleted", func(ctx context.Context, event *WebhookEvent) error {
+		return errors.New("database down")
+	})
+
+	if rec := deliverWebhook(wr, "s3cret", `{"event":"taskDeleted","task_id":"9hz"}`); rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+	if rec := deliverWebhook(wr, "s3cret", `{"event":"taskMoved","task_id":"9hz"}`); rec.Code != http.StatusOK {
+		t.Errorf("unhandled event: status = %d, want 200", rec.Code)
+	}
+}
+
+func TestWebhookReceiver_SetSecrets(t *testing.T) {
+	wr := NewWebhookReceiver("old")
+	body := `{"event":"taskCreated","task_id":"9hz"}`
+
+	wr.SetSecrets("old", "new")
+	for _, secret := range []string{"old", "new"} {
+		if rec := deliverWebhook(wr, secret, body); rec.Code != http.StatusOK {
+			t.Errorf("during rotation, %s secret: status = %d, want 200", secret, rec.Code)
+		}
+	}
+
+	wr.SetSecrets("new")
+	if rec := deliverWebhook(wr, "old", body); rec.Code != http.StatusUnauthorized {
+		t.Errorf("after rotation, old secret: status = %d, want 401", rec.Code)
+	}
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"event":"taskCreated"}`)
+	sig := SignWebhookPayload("s3cret", body)
+
+	if !VerifyWebhookSignature("s3cret", body, sig) {
+		t.Error("VerifyWebhookSignature rejected a valid signature")
+	}
+	if !VerifyWebhookSignature("s3cret", body, strings.ToUpper(sig)) {
+		t.Error("VerifyWebhookSignature rejected an upper case signature")
+	}
+	for _, bad := range []string{"", "zz", sig[:len(sig)-2]} {
+		if VerifyWebhookSignature("s3cret", body, bad) {
+			t.Errorf("VerifyWebhookSignature accepted %q", bad)
+		}
+	}
+	if VerifyWebhookSignature("", body, SignWebhookPayload("", body)) {
+		t.Error("VerifyWebhookSignature accepted an empty secret")
+	}
+}
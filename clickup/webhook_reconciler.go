@@ -0,0 +1,175 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+)
+
+// Webhook reconciliation actions.
+const (
+	WebhookActionCreate = "create"
+	WebhookActionUpdate = "update"
+	WebhookActionDelete = "delete"
+)
+
+// WebhookChange is a change planned by ReconcileWebhooks.
+type WebhookChange struct {
+	Action string
+	// Desired is the declared webhook; nil for deletes.
+	Desired *WebhookRequest
+	// Webhook is the registered webhook; nil for creates.
+	Webhook *Webhook
+	// Fields names what an update changes: "events" or "status".
+	Fields []string
+	// Done is set once the change has been applied.
+	Done bool
+}
+
+// WebhookPlan lists the changes that make the webhooks of a team match the
+// declared ones.
+type WebhookPlan struct {
+	TeamID  int
+	Changes []WebhookChange
+}
+
+type WebhookReconcileOptions struct {
+	// PlanOnly returns the plan without changing anything.
+	PlanOnly bool
+	// Manages reports whether a registered webhook that is not declared may
+	// be deleted. By default only webhooks with a declared endpoint are, so
+	// webhooks of other services in the team are left alone.
+	Manages func(w Webhook) bool
+	// OnSecret receives the secret of each created webhook, to be stored for
+	// verifying deliveries. An error stops the reconciliation; the create is
+	// still marked Done, as the webhook exists.
+	OnSecret func(desired WebhookRequest, webhookID, secret string) error
+}
+
+// ReconcileWebhooks makes the webhooks of a team match the desired ones.
+// Desired and registered webhooks are matched by endpoint and location
+// (task, list, folder or space). Missing webhooks are created, webhooks whose
+// events or status differ are updated, and managed webhooks that are not
+// desired are deleted.
+//
+// Creates are applied before updates and deletes. On error the returned plan
+// shows which changes were done.
+func (s *WebhooksService) ReconcileWebhooks(ctx context.Context, teamID int, desired []WebhookRequest, opts *WebhookReconcileOptions) (*WebhookPlan, error) {
+	o := WebhookReconcileOptions{}
+	if opts != nil {
+		o = *opts
+	}
+
+	registered, _, err := s.GetWebhook(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := planWebhooks(teamID, desired, registered, o.Manages)
+	if err != nil || o.PlanOnly {
+		return plan, err
+	}
+
+	for i := range plan.Changes {
+		if err := s.applyWebhookChange(ctx, teamID, &plan.Changes[i], o.OnSecret); err != nil {
+			return plan, err
+		}
+	}
+
+	return plan, nil
+}
+
+func planWebhooks(teamID int, desired []WebhookRequest, registered []Webhook, manages func(Webhook) bool) (*WebhookPlan, error) {
+	plan := &WebhookPlan{TeamID: teamID}
+
+	endpoints := map[string]bool{}
+	keys := map[string]bool{}
+	for _, d := range desired {
+		key := d.Endpoint + " " + webhookRequestLocation(d)
+		if keys[key] {
+			return nil, fmt.Errorf("webhook for %s declared twice", key)
+		}
+		keys[key] = true
+		endpoints[d.Endpoint] = true
+	}
+	if manages == nil {
+		manages = func(w Webhook) bool { return endpoints[w.Endpoint] }
+	}
+
+	matched := map[string]bool{}
+	var updates []WebhookChange
+	for i := range desired {
+		d := &desired[i]
+
+		var w *Webhook
+		for j := range registered {
+			r := &registered[j]
+			if !matched[r.ID] && r.Endpoint == d.Endpoint && webhookLocation(*r) == webhookRequestLocation(*d) {
+				w = r
+				break
+			}
+		}
+		if w == nil {
+			plan.Changes = append(plan.Changes, WebhookChange{Action: WebhookActionCreate, Desired: d})
+			continue
+		}
+		matched[w.ID] = true
+
+		var fields []string
+		if !webhookEventsMatch(d.Events, w.Events) {
+			fields = append(fields, "events")
+		}
+		if d.Status != "" && d.Status != w.Health.Status {
+			fields = append(fields, "status")
+		}
+		if fields != nil {
+			updates = append(updates, WebhookChange{Action: WebhookActionUpdate, Desired: d, Webhook: w, Fields: fields})
+		}
+	}
+	plan.Changes = append(plan.Changes, updates...)
+
+	for i := range registered {
+		w := &registered[i]
+		if !matched[w.ID] && manages(*w) {
+			plan.Changes = append(plan.Changes, WebhookChange{Action: WebhookActionDelete, Webhook: w})
+		}
+	}
+
+	return plan, nil
+}
+
+func (s *WebhooksService) applyWebhookChange(ctx context.Context, teamID int, c *WebhookChange, onSecret func(WebhookRequest, string, string) error) error {
+	switch c.Action {
+	case WebhookActionCreate:
+		wr, _, err := s.CreateWebhook(ctx, teamID, c.Desired)
+		if err != nil {
+			return err
+		}
+		c.Webhook = &wr.Webhook
+		if c.Webhook.ID == "" {
+			c.Webhook.ID = wr.ID
+		}
+		c.Done = true
+		if onSecret != nil {
+			if err := onSecret(*c.Desired, c.Webhook.ID, wr.Webhook.Secret); err != nil {
+				return fmt.Errorf("storing secret of created webhook %s: %w", c.Webhook.ID, err)
+			}
+		}
+	case WebhookActionUpdate:
+		_, _, err := s.UpdateWebhook(ctx, c.Webhook.ID, &WebhookRequest{
+			Endpoint: c.Desired.Endpoint,
+			Events:   c.Desired.Events,
+			Status:   c.Desired.Status,
+		})
+		if err != nil {
+			return err
+		}
+		c.Done = true
+	case WebhookActionDelete:
+		if _, err := s.DeleteWebhook(ctx, c.Webhook.ID); err != nil {
+			return err
+		}
+		c.Done = true
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package clickup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func setupWebhookReconcile(mux *http.ServeMux) *[]string {
+	var calls []string
+	mux.HandleFunc("/team/1/webhook", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			var req WebhookRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			calls = append(calls, fmt.Sprintf("create %s %v", req.Endpoint, req.Events))
+			fmt.Fprint(w, `{"id": "w9", "webhook": {"id": "w9", "endpoint": "https://c.example.com", "secret": "s9"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"webhooks": [
+			{"id": "w1", "endpoint": "https://a.example.com", "events": ["taskCreated"], "list_id": 5, "health": {"status": "active"}},
+			{"id": "w2", "endpoint": "https://b.example.com", "events": ["*"], "space_id": 9, "health": {"status": "active"}},
+			{"id": "w3", "endpoint": "https://b.example.com", "events": ["*"], "list_id": 7, "health": {"status": "active"}},
+			{"id": "w4", "endpoint": "https://other.example.com", "events": ["*"], "health": {"status": "active"}}
+		]}`)
+	})
+	mux.HandleFunc("/webhook/", func(w http.ResponseWriter, r *http.Request) {
+		var req WebhookRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		calls = append(calls, fmt.Sprintf("%s %s %v %s", r.Method, r.URL.Path, req.Events, req.Status))
+		fmt.Fprint(w, `{}`)
+	})
+
+	return &calls
+}
+
+var desiredWebhooks = []WebhookRequest{
+	{Endpoint: "https://a.example.com", Events: []string{"taskDeleted", "taskCreated"}, ListID: 5},
+	{Endpoint: "https://b.example.com", Events: []string{"*"}, SpaceID: "9"},
+	{Endpoint: "https://c.example.com", Events: []string{"taskMoved"}, TaskID: "9hz"},
+}
+
+func webhookPlanSummary(plan *WebhookPlan) []string {
+	var s []string
+	for _, c := range plan.Changes {
+		id, endpoint := "-", "-"
+		if c.Webhook != nil {
+			id = c.Webhook.ID
+		}
+		if c.Desired != nil {
+			endpoint = c.Desired.Endpoint
+		}
+		s = append(s, fmt.Sprintf("%s %s %s %v %v", c.Action, id, endpoint, c.Fields, c.Done))
+	}
+	return s
+}
+
+func TestWebhooksService_ReconcileWebhooks_planOnly(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+	calls := setupWebhookReconcile(mux)
+
+	plan, err := client.Webhooks.ReconcileWebhooks(context.Background(), 1, desiredWebhooks, &WebhookReconcileOptions{PlanOnly: true})
+	if err != nil {
+		t.Fatalf("Webhooks.ReconcileWebhooks returned error: %v", err)
+	}
+
+	want := []string{
+		"create - https://c.example.com [] false",
+		"update w1 https://a.example.com [events] false",
+		"delete w3 - [] false",
+	}
+	if got := webhookPlanSummary(plan); !cmp.Equal(got, want) {
+		t.Errorf("plan = %q, want %q", got, want)
+	}
+	if len(*calls) != 0 {
+		t.Errorf("plan-only mode made changes: %q", *calls)
+	}
+}
+
+func TestWebhooksService_ReconcileWebhooks(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+	calls := setupWebhookReconcile(mux)
+
+	var secrets []string
+	plan, err := client.Webhooks.ReconcileWebhooks(context.Background(), 1, desiredWebhooks, &WebhookReconcileOptions{
+		OnSecret: func(d WebhookRequest, id, secret string) error {
+			secrets = append(secrets, d.Endpoint+" "+id+" "+secret)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Webhooks.ReconcileWebhooks returned error: %v", err)
+	}
+
+	wantCalls := []string{
+		"create https://c.example.com [taskMoved]",
+		"PUT /webhook/w1 [taskDeleted taskCreated] ",
+		"DELETE /webhook/w3 [] ",
+	}
+	if !cmp.Equal(*calls, wantCalls) {
+		t.Errorf("requests = %q, want %q", *calls, wantCalls)
+	}
+	if want := []string{"https://c.example.com w9 s9"}; !cmp.Equal(secrets, want) {
+		t.Errorf("secrets = %q, want %q", secrets, want)
+	}
+	for _, c := range plan.Changes {
+		if !c.Done {
+			t.Errorf("change %+v not done", c)
+		}
+	}
+}
+
+func TestWebhooksService_ReconcileWebhooks_secretError(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+	calls := setupWebhookReconcile(mux)
+
+	plan, err := client.Webhooks.ReconcileWebhooks(context.Background(), 1, desiredWebhooks, &WebhookReconcileOptions{
+		OnSecret: func(d WebhookRequest, id, secret string) error { return errors.New("vault down") },
+	})
+	if err == nil || !strings.Contains(err.Error(), "w9") {
+		t.Fatalf("Webhooks.ReconcileWebhooks returned error %v, want one naming the created webhook", err)
+	}
+
+	// The webhook was created, so the create is done and a retry must not
+	// create it again; the rest of the plan was not applied.
+	want := []string{
+		"create w9 https://c.example.com [] true",
+		"update w1 https://a.example.com [events] false",
+		"delete w3 - [] false",
+	}
+	if got := webhookPlanSummary(plan); !cmp.Equal(got, want) {
+		t.Errorf("plan = %q, want %q", got, want)
+	}
+	if len(*calls) != 1 {
+		t.Errorf("requests = %q, want only the create", *calls)
+	}
+}
+
+func TestWebhooksService_ReconcileWebhooks_manages(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+	setupWebhookReconcile(mux)
+
+	plan, err := client.Webhooks.ReconcileWebhooks(context.Background(), 1, nil, &WebhookReconcileOptions{
+		PlanOnly: true,
+		Manages:  func(w Webhook) bool { return w.ID == "w4" },
+	})
+	if err != nil {
+		t.Fatalf("Webhooks.ReconcileWebhooks returned error: %v", err)
+	}
+	if got, want := webhookPlanSummary(plan), []string{"delete w4 - [] false"}; !cmp.Equal(got, want) {
+		t.Errorf("plan = %q, want %q", got, want)
+	}
+}
+
+func TestWebhooksService_ReconcileWebhooks_duplicate(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+	setupWebhookReconcile(mux)
+
+	desired := []WebhookRequest{desiredWebhooks[0], desiredWebhooks[0]}
+	if _, err := client.Webhooks.ReconcileWebhooks(context.Background(), 1, desired, nil); err == nil {
+		t.Error("Webhooks.ReconcileWebhooks returned no error for a duplicate declaration")
+	}
+}
@@ -0,0 +1,406 @@
+package clickup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultWebhookRelayQueueSize is how many deliveries a WebhookRelay holds
+// for each destination unless QueueSize is set.
+const DefaultWebhookRelayQueueSize = 1000
+
+// WebhookDestination is an internal consumer of deliveries relayed by a
+// WebhookRelay. A destination receives the deliveries that match all of its
+// filters.
+type WebhookDestination struct {
+	// Name identifies the destination in metrics; the default is URL.
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url"`
+	// Secret signs the relayed deliveries in WebhookSignatureHeader, so the
+	// destination can verify them like deliveries from ClickUp. Without it
+	// they are sent unsigned.
+	Secret string `json:"secret,omitempty"`
+	// Events to relay; empty or WebhookEventAll relays every event.
+	Events []string `json:"events,omitempty"`
+	// TaskID, ListID, FolderID and SpaceID limit the deliveries to a
+	// location.
+	TaskID   string `json:"task_id,omitempty"`
+	ListID   string `json:"list_id,omitempty"`
+	FolderID string `json:"folder_id,omitempty"`
+	SpaceID  string `json:"space_id,omitempty"`
+}
+
+func (d *WebhookDestination) wantsEvent(event string) bool {
+	if len(d.Events) == 0 {
+		return true
+	}
+	for _, e := range d.Events {
+		if e == WebhookEventAll || e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// webhookEventLocation is where a delivery happened, as far as it is known.
+type webhookEventLocation struct {
+	task, list, folder, space string
+}
+
+func newWebhookEventLocation(e *WebhookEvent) webhookEventLocation {
+	loc := webhookEventLocation{
+		task:   e.TaskID,
+		list:   e.ListID.String(),
+		folder: e.FolderID.String(),
+		space:  e.SpaceID.String(),
+	}
+	if loc.list == "" && e.TaskID != "" && len(e.HistoryItems) > 0 {
+		// Task history items have the list of the task as their parent.
+		var items []struct {
+			ParentID string `json:"parent_id"`
+		}
+		json.Unmarshal(e.HistoryItems, &items)
+		for _, h := range items {
+			if h.ParentID != "" {
+				loc.list = h.ParentID
+				break
+			}
+		}
+	}
+
+	return loc
+}
+
+// needsLookup reports whether matching d needs a part of the location of a
+// task event that the delivery does not carry.
+func (d *WebhookDestination) needsLookup(loc webhookEventLocation) bool {
+	return loc.task != "" &&
+		(d.ListID != "" && loc.list == "" || d.FolderID != "" && loc.folder == "" || d.SpaceID != "" && loc.space == "")
+}
+
+func (d *WebhookDestination) matches(loc webhookEventLocation) bool {
+	for _, f := range []struct{ want, got string }{
+		{d.TaskID, loc.task},
+		{d.ListID, loc.list},
+		{d.FolderID, loc.folder},
+		{d.SpaceID, loc.space},
+	} {
+		if f.want != "" && f.want != f.got {
+			return false
+		}
+	}
+
+	return true
+}
+
+// WebhookDestinationMetrics counts the deliveries of one destination.
+type WebhookDestinationMetrics struct {
+	// Matched deliveries were queued for the destination.
+	Matched int64
+	// Delivered deliveries were accepted with a 2xx response.
+	Delivered int64
+	// Retries counts the failed attempts that were retried.
+	Retries int64
+	// Failed deliveries were given up after a permanent error or
+	// MaxAttempts attempts.
+	Failed int64
+	// Dropped deliveries were refused because the queue was full; ClickUp
+	// retries them.
+	Dropped int64
+	// Pending deliveries are queued or being delivered.
+	Pending int
+
+	LastError     string
+	LastLatency   time.Duration
+	LastDelivered time.Time
+}
+
+// WebhookRelayMetrics counts the deliveries of a WebhookRelay.
+type WebhookRelayMetrics struct {
+	// Received counts the verified deliveries from ClickUp.
+	Received     int64
+	Destinations map[string]WebhookDestinationMetrics
+}
+
+type WebhookRelayOptions struct {
+	// QueueSize is how many deliveries are held for each destination; the
+	// default is DefaultWebhookRelayQueueSize.
+	QueueSize int
+	// MaxAttempts is how often a delivery is tried; the default is five.
+	MaxAttempts int
+	// Backoff returns the delay after a failed attempt; attempts starts at
+	// one. The default doubles from one second up to five minutes.
+	Backoff func(attempts int) time.Duration
+	// HTTPClient sends the deliveries; nil means http.DefaultClient.
+	HTTPClient *http.Client
+	// Client looks up the list, folder and space of task events for
+	// destinations that filter on them, as task deliveries only carry the
+	// task and its list. Without it, or when the lookup fails, such
+	// destinations do not get the task event; the others still do.
+	Client *Client
+	// OnFailure is called when a delivery to a destination is given up,
+	// refused because its queue is full, or skipped because the location of
+	// its task could not be looked up.
+	OnFailure func(destination string, event *WebhookEvent, err error)
+}
+
+// WebhookRelay receives ClickUp webhook deliveries once and forwards them to
+// several internal destinations, since a workspace allows only a limited
+// number of webhooks. Serve it over HTTP and run its workers:
+//
+//	relay := clickup.NewWebhookRelay(webhook.Secret, destinations, nil)
+//	go relay.Run(ctx)
+//	http.Handle("/webhook", relay)
+//
+// Deliveries are verified like by a WebhookReceiver and acknowledged once
+// they are queued. Each destination is delivered to in order by its own
+// worker, retrying failed deliveries with backoff; responses other than 2xx
+// are retried, except 4xx responses other than 408 and 429. A delivery is
+// refused with an error response, for ClickUp to retry it, when the queue of
+// a destination it matches is full; it is then queued for none of them.
+// Queued deliveries are held in memory and lost when the relay stops.
+type WebhookRelay struct {
+	receiver *WebhookReceiver
+	dests    []*relayDestination
+	opts     WebhookRelayOptions
+
+	mu       sync.Mutex
+	received int64
+	now      func() time.Time
+	// queueing serializes relay, so that room found in the queues is still
+	// there when the delivery is queued.
+	queueing sync.Mutex
+}
+
+type relayDestination struct {
+	WebhookDestination
+	queue chan *WebhookEvent
+
+	mu      sync.Mutex
+	metrics WebhookDestinationMetrics
+}
+
+func NewWebhookRelay(secret string, destinations []WebhookDestination, opts *WebhookRelayOptions) *WebhookRelay {
+	r := &WebhookRelay{receiver: NewWebhookReceiver(secret), now: time.Now}
+	if opts != nil {
+		r.opts = *opts
+	}
+	if r.opts.QueueSize <= 0 {
+		r.opts.QueueSize = DefaultWebhookRelayQueueSize
+	}
+	if r.opts.MaxAttempts <= 0 {
+		r.opts.MaxAttempts = 5
+	}
+	if r.opts.Backoff == nil {
+		r.opts.Backoff = defaultWebhookBackoff
+	}
+	if r.opts.HTTPClient == nil {
+		r.opts.HTTPClient = http.DefaultClient
+	}
+
+	for _, d := range destinations {
+		if d.Name == "" {
+			d.Name = d.URL
+		}
+		r.dests = append(r.dests, &relayDestination{WebhookDestination: d, queue: make(chan *WebhookEvent, r.opts.QueueSize)})
+	}
+	r.receiver.Handle(WebhookEventAll, r.relay)
+
+	return r
+}
+
+// SetSecrets replaces the secrets deliveries are verified with, such as
+// when the webhook is recreated or several webhooks share the relay.
+func (r *WebhookRelay) SetSecrets(secrets ...string) {
+	r.receiver.SetSecrets(secrets...)
+}
+
+func (r *WebhookRelay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.receiver.ServeHTTP(w, req)
+}
+
+// relay queues a verified delivery for the destinations it matches.
+func (r *WebhookRelay) relay(ctx context.Context, e *WebhookEvent) error {
+	r.mu.Lock()
+	r.received++
+	r.mu.Unlock()
+
+	loc := newWebhookEventLocation(e)
+	var (
+		matched   []*relayDestination
+		looked    bool
+		lookupErr error
+	)
+	for _, d := range r.dests {
+		if !d.wantsEvent(e.Event) {
+			continue
+		}
+		if d.needsLookup(loc) && r.opts.Client != nil {
+			if !looked {
+				t, _, err := r.opts.Client.Tasks.GetTask(ctx, e.TaskID, nil)
+				if err != nil {
+					lookupErr = fmt.Errorf("looking up the location of task %s: %w", e.TaskID, err)
+				} else {
+					loc.list, loc.folder, loc.space = t.List.ID, t.Folder.ID, t.Space.ID
+				}
+				looked = true
+			}
+			if lookupErr != nil {
+				// The destination cannot be matched, which must not keep the
+				// delivery from the others.
+				d.update(func(m *WebhookDestinationMetrics) { m.LastError = lookupErr.Error() })
+				r.fail(d, e, lookupErr)
+				continue
+			}
+		}
+		if d.matches(loc) {
+			matched = append(matched, d)
+		}
+	}
+
+	r.queueing.Lock()
+	defer r.queueing.Unlock()
+
+	// Queue for all matched destinations or none, so that a retry by
+	// ClickUp does not reach any of them twice.
+	for _, d := range matched {
+		if len(d.queue) == cap(d.queue) {
+			d.update(func(m *WebhookDestinationMetrics) { m.Dropped++ })
+			err := errors.New("queue is full")
+			r.fail(d, e, err)
+			return fmt.Errorf("relaying to %s: %w", d.Name, err)
+		}
+	}
+	for _, d := range matched {
+		d.queue <- e
+		d.update(func(m *WebhookDestinationMetrics) { m.Matched++ })
+	}
+
+	return nil
+}
+
+// Run delivers queued deliveries until ctx is done.
+func (r *WebhookRelay) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, d := range r.dests {
+		wg.Add(1)
+		go func(d *relayDestination) {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case e := <-d.queue:
+					r.deliver(ctx, d, e)
+				}
+			}
+		}(d)
+	}
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// Metrics returns the delivery counts so far.
+func (r *WebhookRelay) Metrics() WebhookRelayMetrics {
+	r.mu.Lock()
+	m := WebhookRelayMetrics{Received: r.received, Destinations: map[string]WebhookDestinationMetrics{}}
+	r.mu.Unlock()
+
+	for _, d := range r.dests {
+		d.mu.Lock()
+		dm := d.metrics
+		d.mu.Unlock()
+		dm.Pending = int(dm.Matched - dm.Delivered - dm.Failed)
+		m.Destinations[d.Name] = dm
+	}
+
+	return m
+}
+
+func (r *WebhookRelay) deliver(ctx context.Context, d *relayDestination, e *WebhookEvent) {
+	for attempts := 1; ; attempts++ {
+		start := r.now()
+		retry, err := r.post(ctx, d, e)
+		latency := r.now().Sub(start)
+
+		if err == nil {
+			d.update(func(m *WebhookDestinationMetrics) {
+				m.Delivered++
+				m.LastLatency = latency
+				m.LastDelivered = r.now()
+			})
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		if !retry || attempts >= r.opts.MaxAttempts {
+			d.update(func(m *WebhookDestinationMetrics) {
+				m.Failed++
+				m.LastError = err.Error()
+			})
+			r.fail(d, e, fmt.Errorf("giving up after %d attempts: %w", attempts, err))
+			return
+		}
+		d.update(func(m *WebhookDestinationMetrics) {
+			m.Retries++
+			m.LastError = err.Error()
+		})
+
+		timer := time.NewTimer(r.opts.Backoff(attempts))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// post sends e to d once and reports whether a failure may be retried.
+func (r *WebhookRelay) post(ctx context.Context, d *relayDestination, e *WebhookEvent) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", d.URL, bytes.NewReader(e.Raw))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if d.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(d.Secret, e.Raw))
+	}
+
+	resp, err := r.opts.HTTPClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests
+
+	return retry, fmt.Errorf("%s responded %s", d.Name, resp.Status)
+}
+
+func (r *WebhookRelay) fail(d *relayDestination, e *WebhookEvent, err error) {
+	if r.opts.OnFailure != nil {
+		r.opts.OnFailure(d.Name, e, err)
+	}
+}
+
+func (d *relayDestination) update(f func(m *WebhookDestinationMetrics)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	f(&d.metrics)
+}
@@ -0,0 +1,248 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type relayConsumer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	bodies []string
+	sigs   []string
+	status []int
+}
+
+// newRelayConsumer responds with status in turn, then 200.
+func newRelayConsumer(status ...int) *relayConsumer {
+	c := &relayConsumer{status: status}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.bodies = append(c.bodies, string(b))
+		c.sigs = append(c.sigs, r.Header.Get(WebhookSignatureHeader))
+		if len(c.status) > 0 {
+			w.WriteHeader(c.status[0])
+			c.status = c.status[1:]
+		}
+	}))
+	return c
+}
+
+func (c *relayConsumer) received() ([]string, []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.bodies...), append([]string(nil), c.sigs...)
+}
+
+func waitRelayIdle(t *testing.T, r *WebhookRelay) WebhookRelayMetrics {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		m := r.Metrics()
+		pending := 0
+		for _, d := range m.Destinations {
+			pending += d.Pending
+		}
+		if pending == 0 {
+			return m
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("relay still has %d pending deliveries", pending)
+		}
+	}
+}
+
+func TestWebhookRelay(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+	lookups := 0
+	mux.HandleFunc("/task/9hz/", func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		fmt.Fprint(w, `{"id": "9hz", "list": {"id": "5"}, "folder": {"id": "4"}, "space": {"id": "9"}}`)
+	})
+
+	created, byList, bySpace := newRelayConsumer(), newRelayConsumer(), newRelayConsumer()
+	defer created.Close()
+	defer byList.Close()
+	defer bySpace.Close()
+
+	relay := NewWebhookRelay("s3cret", []WebhookDestination{
+		{Name: "created", URL: created.URL, Secret: "a", Events: []string{WebhookEventTaskCreated}},
+		{Name: "list", URL: byList.URL, ListID: "5"},
+		{Name: "space", URL: bySpace.URL, Secret: "c", SpaceID: "9"},
+	}, &WebhookRelayOptions{Client: client})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go relay.Run(ctx)
+
+	task := `{"event":"taskCreated","task_id":"9hz","webhook_id":"wh1","history_items":[{"id":"1","parent_id":"5"}]}`
+	list := `{"event":"listCreated","list_id":"6","webhook_id":"wh1"}`
+	for _, body := range []string{task, list} {
+		if rec := deliverWebhook(relay, "s3cret", body); rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want 200", rec.Code)
+		}
+	}
+	if rec := deliverWebhook(relay, "wrong", task); rec.Code != http.StatusUnauthorized {
+		t.Errorf("status with a bad signature = %d, want 401", rec.Code)
+	}
+
+	m := waitRelayIdle(t, relay)
+	if m.Received != 2 {
+		t.Errorf("Received = %d, want 2", m.Received)
+	}
+	if lookups != 1 {
+		t.Errorf("looked up the task %d times, want 1", lookups)
+	}
+
+	for _, tt := range []struct {
+		consumer *relayConsumer
+		secret   string
+	}{{created, "a"}, {byList, ""}, {bySpace, "c"}} {
+		bodies, sigs := tt.consumer.received()
+		if !cmp.Equal(bodies, []string{task}) {
+			t.Errorf("relayed %q, want the task delivery only", bodies)
+			continue
+		}
+		want := ""
+		if tt.secret != "" {
+			want = SignWebhookPayload(tt.secret, []byte(task))
+		}
+		if sigs[0] != want {
+			t.Errorf("signature = %q, want %q", sigs[0], want)
+		}
+	}
+	if d := m.Destinations["list"]; d.Matched != 1 || d.Delivered != 1 || d.LastDelivered.IsZero() {
+		t.Errorf("list metrics = %+v", d)
+	}
+}
+
+func TestWebhookRelay_retries(t *testing.T) {
+	flaky := newRelayConsumer(http.StatusInternalServerError, http.StatusTooManyRequests)
+	broken := newRelayConsumer(http.StatusBadRequest)
+	defer flaky.Close()
+	defer broken.Close()
+
+	var failures []string
+	var mu sync.Mutex
+	relay := NewWebhookRelay("s3cret", []WebhookDestination{
+		{Name: "flaky", URL: flaky.URL},
+		{Name: "broken", URL: broken.URL},
+	}, &WebhookRelayOptions{
+		Backoff: func(int) time.Duration { return time.Millisecond },
+		OnFailure: func(destination string, e *WebhookEvent, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failures = append(failures, destination+": "+err.Error())
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go relay.Run(ctx)
+
+	deliverWebhook(relay, "s3cret", `{"event":"taskDeleted","task_id":"9hz"}`)
+	m := waitRelayIdle(t, relay)
+
+	if d := m.Destinations["flaky"]; d.Delivered != 1 || d.Retries != 2 || d.Failed != 0 {
+		t.Errorf("flaky metrics = %+v", d)
+	}
+	if d := m.Destinations["broken"]; d.Delivered != 0 || d.Retries != 0 || d.Failed != 1 || d.LastError == "" {
+		t.Errorf("broken metrics = %+v", d)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(failures) != 1 {
+		t.Errorf("failures = %q, want one for broken", failures)
+	}
+}
+
+func TestWebhookRelay_lookupFails(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+	mux.HandleFunc("/task/9hz/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"err": "down"}`, http.StatusServiceUnavailable)
+	})
+
+	all, bySpace := newRelayConsumer(), newRelayConsumer()
+	defer all.Close()
+	defer bySpace.Close()
+
+	var failed []string
+	relay := NewWebhookRelay("s3cret", []WebhookDestination{
+		{Name: "space", URL: bySpace.URL, SpaceID: "9"},
+		{Name: "all", URL: all.URL},
+	}, &WebhookRelayOptions{
+		Client: client,
+		OnFailure: func(destination string, e *WebhookEvent, err error) {
+			failed = append(failed, destination)
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go relay.Run(ctx)
+
+	task := `{"event":"taskCreated","task_id":"9hz"}`
+	if rec := deliverWebhook(relay, "s3cret", task); rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+
+	m := waitRelayIdle(t, relay)
+	if bodies, _ := all.received(); !cmp.Equal(bodies, []string{task}) {
+		t.Errorf("relayed %q to all, want the task delivery", bodies)
+	}
+	if bodies, _ := bySpace.received(); len(bodies) != 0 {
+		t.Errorf("relayed %q to space, want nothing", bodies)
+	}
+	if d := m.Destinations["space"]; d.Matched != 0 || d.LastError == "" {
+		t.Errorf("space metrics = %+v", d)
+	}
+	if !cmp.Equal(failed, []string{"space"}) {
+		t.Errorf("failed %q, want space", failed)
+	}
+}
+
+func TestWebhookRelay_queueFull(t *testing.T) {
+	var dropped []string
+	relay := NewWebhookRelay("s3cret", []WebhookDestination{
+		{Name: "all", URL: "http://localhost:1"},
+		{Name: "big", URL: "http://localhost:2", Events: []string{"taskCreated"}},
+	}, &WebhookRelayOptions{
+		QueueSize: 1,
+		OnFailure: func(destination string, e *WebhookEvent, err error) {
+			dropped = append(dropped, destination+" "+e.TaskID)
+		},
+	})
+	relay.dests[1].queue = make(chan *WebhookEvent, 10)
+
+	// Without Run nothing is delivered, so the second delivery does not fit
+	// in the queue of all, and is refused for ClickUp to retry.
+	if rec := deliverWebhook(relay, "s3cret", `{"event":"taskCreated","task_id":"a"}`); rec.Code != http.StatusOK {
+		t.Fatalf("first delivery got status %d, want 200", rec.Code)
+	}
+	if rec := deliverWebhook(relay, "s3cret", `{"event":"taskCreated","task_id":"b"}`); rec.Code < 500 {
+		t.Errorf("delivery to a full queue got status %d, want an error status", rec.Code)
+	}
+
+	m := relay.Metrics()
+	if d := m.Destinations["all"]; d.Matched != 1 || d.Dropped != 1 || d.Pending != 1 {
+		t.Errorf("metrics of all = %+v", d)
+	}
+	// Nothing was queued for big either.
+	if d := m.Destinations["big"]; d.Matched != 1 || d.Dropped != 0 || d.Pending != 1 {
+		t.Errorf("metrics of big = %+v", d)
+	}
+	if !cmp.Equal(dropped, []string{"all b"}) {
+		t.Errorf("dropped %q, want b for all", dropped)
+	}
+}
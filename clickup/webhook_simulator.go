@@ -0,0 +1,423 @@
+package clickup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebhookDelivery is a signed webhook delivery, as ClickUp would send it.
+type WebhookDelivery struct {
+	Event     string
+	Body      []byte
+	Signature string
+}
+
+// Request returns a POST request of the delivery to url.
+func (d *WebhookDelivery) Request(ctx context.Context, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(d.Body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, d.Signature)
+
+	return req, nil
+}
+
+// SimulatedChange is a history item of a simulated delivery. Before and After
+// are encoded as JSON, except that time.Time values are sent as Unix
+// milliseconds and time.Duration values as milliseconds, like ClickUp does.
+type SimulatedChange struct {
+	Field         string
+	Before, After interface{}
+	Data          interface{}
+	Comment       interface{}
+}
+
+// WebhookSimulator builds signed deliveries for every webhook event, for
+// testing webhook consumers without exposing them to ClickUp:
+//
+//	sim := clickup.NewWebhookSimulator("wh1", secret)
+//	d, _ := sim.TaskEvent(clickup.WebhookEventTaskStatusUpdated, task)
+//	resp, err := sim.Post(ctx, "http://localhost:8080/webhook", d)
+//
+// Typed task events get the history item ClickUp would send, built from the
+// task, unless changes are given.
+type WebhookSimulator struct {
+	WebhookID string
+	Secret    string
+	// User is who the changes are attributed to.
+	User User
+	// HTTPClient sends deliveries; nil means http.DefaultClient.
+	HTTPClient *http.Client
+
+	now func() time.Time
+	mu  sync.Mutex
+	seq int64
+}
+
+func NewWebhookSimulator(webhookID, secret string) *WebhookSimulator {
+	return &WebhookSimulator{
+		WebhookID: webhookID,
+		Secret:    secret,
+		User:      User{ID: 1, Username: "Simulator", Initials: "S"},
+		now:       time.Now,
+	}
+}
+
+// TaskEvent builds a task event delivery.
+func (s *WebhookSimulator) TaskEvent(event string, task *Task, changes ...SimulatedChange) (*WebhookDelivery, error) {
+	if len(changes) == 0 {
+		changes = s.taskChanges(event, task)
+	}
+
+	return s.delivery(event, "task", task.List.ID, map[string]interface{}{"task_id": task.ID}, changes)
+}
+
+// ListEvent builds a list event delivery.
+func (s *WebhookSimulator) ListEvent(event string, list *List, changes ...SimulatedChange) (*WebhookDelivery, error) {
+	return s.delivery(event, "list", list.Folder.ID, map[string]interface{}{"list_id": list.ID}, changes)
+}
+
+// FolderEvent builds a folder event delivery.
+func (s *WebhookSimulator) FolderEvent(event string, folder *Folder, changes ...SimulatedChange) (*WebhookDelivery, error) {
+	return s.delivery(event, "folder", folder.Space.ID, map[string]interface{}{"folder_id": folder.ID}, changes)
+}
+
+// SpaceEvent builds a space event delivery.
+func (s *WebhookSimulator) SpaceEvent(event string, space *Space, changes ...SimulatedChange) (*WebhookDelivery, error) {
+	return s.delivery(event, "space", "", map[string]interface{}{"space_id": space.ID}, changes)
+}
+
+// GoalEvent builds a goal event delivery.
+func (s *WebhookSimulator) GoalEvent(event string, goal *Goal, changes ...SimulatedChange) (*WebhookDelivery, error) {
+	return s.delivery(event, "goal", goal.TeamID, map[string]interface{}{"goal_id": goal.ID}, changes)
+}
+
+// KeyResultEvent builds a key result event delivery.
+func (s *WebhookSimulator) KeyResultEvent(event string, kr *KeyResult, changes ...SimulatedChange) (*WebhookDelivery, error) {
+	return s.delivery(event, "keyResult", kr.GoalID, map[string]interface{}{"goal_id": kr.GoalID, "key_result_id": kr.ID}, changes)
+}
+
+// Comment returns the history item of a comment on a task, for
+// taskCommentPosted and taskCommentUpdated events.
+func (s *WebhookSimulator) Comment(text string) SimulatedChange {
+	id := s.nextID()
+	return SimulatedChange{
+		Field: "comment",
+		After: id,
+		Comment: WebhookComment{
+			ID:          json.Number(id),
+			Date:        json.Number(strconv.FormatInt(s.now().UnixMilli(), 10)),
+			Type:        1,
+			Comment:     []CommentInComment{{Text: text}},
+			TextContent: text,
+			UserID:      json.Number(strconv.Itoa(s.User.ID)),
+		},
+	}
+}
+
+func (s *WebhookSimulator) taskChanges(event string, task *Task) []SimulatedChange {
+	switch event {
+	case WebhookEventTaskCreated:
+		return []SimulatedChange{{Field: "task_creation", Data: map[string]string{"status_type": task.Status.Type}}}
+	case WebhookEventTaskStatusUpdated:
+		return []SimulatedChange{{Field: "status", After: webhookStatus(task.Status)}}
+	case WebhookEventTaskPriorityUpdated:
+		return []SimulatedChange{{Field: "priority", After: webhookPriority(task.Priority)}}
+	case WebhookEventTaskAssigneeUpdated:
+		var changes []SimulatedChange
+		for _, u := range task.Assignees {
+			changes = append(changes, SimulatedChange{Field: "assignee_add", After: u})
+		}
+		return changes
+	case WebhookEventTaskDueDateUpdated:
+		var after *time.Time
+		if task.DueDate != nil {
+			after = task.DueDate.Time()
+		}
+		return []SimulatedChange{{Field: "due_date", After: after}}
+	case WebhookEventTaskTagUpdated:
+		return []SimulatedChange{{Field: "tag", After: task.Tags}}
+	case WebhookEventTaskMoved:
+		return []SimulatedChange{{Field: "section_moved", After: TaskWebhookLocation(task)}}
+	case WebhookEventTaskCommentPosted, WebhookEventTaskCommentUpdated:
+		return []SimulatedChange{s.Comment("Simulated comment on " + task.Name)}
+	case WebhookEventTaskTimeEstimateUpdated:
+		var after interface{}
+		if task.TimeEstimate > 0 {
+			after = time.Duration(task.TimeEstimate) * time.Millisecond
+		}
+		return []SimulatedChange{{Field: "time_estimate", After: after}}
+	case WebhookEventTaskTimeTrackedUpdated:
+		end := s.now().UnixMilli()
+		return []SimulatedChange{{Field: "time_spent", After: WebhookTimeEntry{
+			ID:    s.nextID(),
+			Start: json.Number(strconv.FormatInt(end-task.TimeSpent, 10)),
+			End:   json.Number(strconv.FormatInt(end, 10)),
+			Time:  json.Number(strconv.FormatInt(task.TimeSpent, 10)),
+		}}}
+	}
+
+	return nil
+}
+
+// TaskWebhookLocation returns the location of task as taskMoved events
+// report it.
+func TaskWebhookLocation(task *Task) *WebhookLocation {
+	return newWebhookLocation(task.List, task.Folder, task.Space)
+}
+
+func newWebhookLocation(list ListOfTaskBelonging, folder FolderOftaskBelonging, space SpaceOfTaskBelonging) *WebhookLocation {
+	l := &WebhookLocation{ID: list.ID, Name: list.Name}
+	l.Category.ID = folder.ID
+	l.Category.Name = folder.Name
+	l.Category.Hidden = folder.Hidden
+	l.Project.ID = space.ID
+
+	return l
+}
+
+func webhookStatus(s TaskStatus) WebhookStatus {
+	return WebhookStatus{Status: s.Status, Color: s.Color, Type: s.Type, Orderindex: s.Orderindex}
+}
+
+var webhookPriorityIDs = map[string]string{"urgent": "1", "high": "2", "normal": "3", "low": "4"}
+
+func webhookPriority(p TaskPriority) *WebhookPriority {
+	if p.Priority == "" {
+		return nil
+	}
+	id := webhookPriorityIDs[p.Priority]
+
+	return &WebhookPriority{ID: id, Priority: p.Priority, Color: p.Color, Orderindex: json.Number(id)}
+}
+
+func (s *WebhookSimulator) delivery(event, resource, parentID string, ids map[string]interface{}, changes []SimulatedChange) (*WebhookDelivery, error) {
+	if _, ok := webhookEventDecoders[event]; !ok || !strings.HasPrefix(event, resource) {
+		return nil, fmt.Errorf("%q is not a %s event", event, resource)
+	}
+
+	itemType := 0
+	if resource == "task" {
+		itemType = 1
+	}
+	now := s.now()
+	items := make([]map[string]interface{}, len(changes))
+	for i, c := range changes {
+		items[i] = simulatedHistoryItem(s.nextID(), itemType, now, parentID, s.User, c)
+	}
+
+	body, err := simulatedPayload(event, s.WebhookID, ids, items)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sign(event, body), nil
+}
+
+// simulatedHistoryItem encodes c as a history item the way ClickUp does.
+func simulatedHistoryItem(id string, itemType int, date time.Time, parentID string, user User, c SimulatedChange) map[string]interface{} {
+	item := map[string]interface{}{
+		"id":        id,
+		"type":      itemType,
+		"date":      strconv.FormatInt(date.UnixMilli(), 10),
+		"field":     c.Field,
+		"parent_id": parentID,
+		"data":      c.Data,
+		"source":    nil,
+		"user":      user,
+		"before":    simulatedValue(c.Before),
+		"after":     simulatedValue(c.After),
+	}
+	if c.Data == nil {
+		item["data"] = map[string]interface{}{}
+	}
+	if c.Comment != nil {
+		item["comment"] = c.Comment
+	}
+
+	return item
+}
+
+func simulatedPayload(event, webhookID string, ids map[string]interface{}, items []map[string]interface{}) ([]byte, error) {
+	payload := map[string]interface{}{"event": event, "webhook_id": webhookID}
+	for k, v := range ids {
+		payload[k] = v
+	}
+	if len(items) > 0 {
+		payload["history_items"] = items
+	}
+
+	return json.Marshal(payload)
+}
+
+func simulatedValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case time.Time:
+		return strconv.FormatInt(v.UnixMilli(), 10)
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return strconv.FormatInt(v.UnixMilli(), 10)
+	case time.Duration:
+		return strconv.FormatInt(v.Milliseconds(), 10)
+	}
+
+	return v
+}
+
+func (s *WebhookSimulator) nextID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+
+	return strconv.FormatInt(s.now().UnixMilli()*1000+s.seq%1000, 10)
+}
+
+func (s *WebhookSimulator) sign(event string, body []byte) *WebhookDelivery {
+	return &WebhookDelivery{Event: event, Body: body, Signature: SignWebhookPayload(s.Secret, body)}
+}
+
+// ReadDeliveries reads recorded deliveries: a JSON object, an array of them,
+// or a stream of them such as JSON lines. Lines of a FileWebhookDeadLetters
+// file are read as the deliveries they hold. Bodies are kept as recorded and
+// signed with the simulator's secret.
+func (s *WebhookSimulator) ReadDeliveries(r io.Reader) ([]*WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+	add := func(raw json.RawMessage) error {
+		var e struct {
+			Event string          `json:"event"`
+			Body  json.RawMessage `json:"body"`
+		}
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return fmt.Errorf("reading delivery %d: %w", len(deliveries)+1, err)
+		}
+		if e.Event == "" && len(e.Body) > 0 {
+			raw = e.Body
+			if err := json.Unmarshal(raw, &e); err != nil {
+				return fmt.Errorf("reading delivery %d: %w", len(deliveries)+1, err)
+			}
+		}
+		if e.Event == "" {
+			return fmt.Errorf("reading delivery %d: no event", len(deliveries)+1)
+		}
+		deliveries = append(deliveries, s.sign(e.Event, raw))
+		return nil
+	}
+
+	dec := json.NewDecoder(r)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return deliveries, err
+		}
+
+		raw = bytes.TrimSpace(raw)
+		if len(raw) > 0 && raw[0] == '[' {
+			var batch []json.RawMessage
+			if err := json.Unmarshal(raw, &batch); err != nil {
+				return deliveries, err
+			}
+			for _, b := range batch {
+				if err := add(b); err != nil {
+					return deliveries, err
+				}
+			}
+			continue
+		}
+		if err := add(raw); err != nil {
+			return deliveries, err
+		}
+	}
+
+	return deliveries, nil
+}
+
+// ReadDeliveryFile reads recorded deliveries from a file, as ReadDeliveries
+// does.
+func (s *WebhookSimulator) ReadDeliveryFile(path string) ([]*WebhookDelivery, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	deliveries, err := s.ReadDeliveries(f)
+	if err != nil {
+		return deliveries, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return deliveries, nil
+}
+
+// Post sends d to url. The caller must close the response body.
+func (s *WebhookSimulator) Post(ctx context.Context, url string, d *WebhookDelivery) (*http.Response, error) {
+	req, err := d.Request(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return client.Do(req)
+}
+
+// Serve passes d to h in process and returns the response.
+func (s *WebhookSimulator) Serve(h http.Handler, d *WebhookDelivery) *http.Response {
+	req, _ := d.Request(context.Background(), "http://localhost/webhook")
+	w := &simulatedResponseWriter{header: http.Header{}}
+	h.ServeHTTP(w, req)
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", w.code, http.StatusText(w.code)),
+		StatusCode:    w.code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        w.header,
+		Body:          io.NopCloser(bytes.NewReader(w.body.Bytes())),
+		ContentLength: int64(w.body.Len()),
+		Request:       req,
+	}
+}
+
+// simulatedResponseWriter records the response of a handler for Serve.
+type simulatedResponseWriter struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (w *simulatedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *simulatedResponseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *simulatedResponseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
@@ -0,0 +1,255 @@
+package clickup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newTestWebhookSimulator() *WebhookSimulator {
+	s := NewWebhookSimulator("wh1", "s3cret")
+	s.now = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
+	return s
+}
+
+func TestWebhookSimulator_TaskEvent(t *testing.T) {
+	s := newTestWebhookSimulator()
+	due := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	task := &Task{
+		ID:           "9hz",
+		Name:         "Write docs",
+		Status:       TaskStatus{Status: "in progress", Type: "custom"},
+		Priority:     TaskPriority{Priority: "high", Color: "#ffcc00"},
+		Assignees:    []User{{ID: 7, Username: "ann"}},
+		Tags:         []Tag{{Name: "docs"}},
+		DueDate:      NewDate(due),
+		TimeEstimate: 3600000,
+		TimeSpent:    60000,
+		List:         ListOfTaskBelonging{ID: "5", Name: "Backlog"},
+		Folder:       FolderOftaskBelonging{ID: "4", Name: "Eng"},
+		Space:        SpaceOfTaskBelonging{ID: "3"},
+	}
+
+	var got []interface{}
+	d := NewWebhookDispatcher()
+	d.OnOther(func(ctx context.Context, event string, raw json.RawMessage) error {
+		return fmt.Errorf("unexpected %s delivery", event)
+	})
+	d.OnTaskCreated(func(ctx context.Context, e TaskCreatedEvent) error {
+		got = append(got, e.HistoryItem("task_creation") != nil)
+		return nil
+	})
+	d.OnTaskStatusUpdated(func(ctx context.Context, e TaskStatusUpdatedEvent) error {
+		got = append(got, e.After.Status)
+		return nil
+	})
+	d.OnTaskPriorityUpdated(func(ctx context.Context, e TaskPriorityUpdatedEvent) error {
+		got = append(got, e.After.ID)
+		return nil
+	})
+	d.OnTaskAssigneeUpdated(func(ctx context.Context, e TaskAssigneeUpdatedEvent) error {
+		got = append(got, e.Added[0].Username)
+		return nil
+	})
+	d.OnTaskDueDateUpdated(func(ctx context.Context, e TaskDueDateUpdatedEvent) error {
+		got = append(got, e.After.Equal(due))
+		return nil
+	})
+	d.OnTaskTagUpdated(func(ctx context.Context, e TaskTagUpdatedEvent) error {
+		got = append(got, e.Added[0].Name)
+		return nil
+	})
+	d.OnTaskMoved(func(ctx context.Context, e TaskMovedEvent) error {
+		got = append(got, e.After.Category.Name)
+		return nil
+	})
+	d.OnTaskCommentPosted(func(ctx context.Context, e TaskCommentPostedEvent) error {
+		got = append(got, e.Comment.TextContent)
+		return nil
+	})
+	d.OnTaskTimeEstimateUpdated(func(ctx context.Context, e TaskTimeEstimateUpdatedEvent) error {
+		got = append(got, *e.After)
+		return nil
+	})
+	d.OnTaskTimeTrackedUpdated(func(ctx context.Context, e TaskTimeTrackedUpdatedEvent) error {
+		got = append(got, e.After.Time.String())
+		return nil
+	})
+	wr := NewWebhookReceiver("s3cret")
+	wr.Handle(WebhookEventAll, d.Handle)
+
+	for _, event := range []string{
+		WebhookEventTaskCreated,
+		WebhookEventTaskStatusUpdated,
+		WebhookEventTaskPriorityUpdated,
+		WebhookEventTaskAssigneeUpdated,
+		WebhookEventTaskDueDateUpdated,
+		WebhookEventTaskTagUpdated,
+		WebhookEventTaskMoved,
+		WebhookEventTaskCommentPosted,
+		WebhookEventTaskTimeEstimateUpdated,
+		WebhookEventTaskTimeTrackedUpdated,
+	} {
+		delivery, err := s.TaskEvent(event, task)
+		if err != nil {
+			t.Fatalf("TaskEvent(%s) returned error: %v", event, err)
+		}
+		if resp := s.Serve(wr, delivery); resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200", event, resp.StatusCode)
+		}
+	}
+
+	want := []interface{}{true, "in progress", "2", "ann", true, "docs", "Eng", "Simulated comment on Write docs", time.Hour, "60000"}
+	if !cmp.Equal(got, want) {
+		t.Errorf("decoded events = %v, want %v", got, want)
+	}
+}
+
+func TestWebhookSimulator_changes(t *testing.T) {
+	s := newTestWebhookSimulator()
+
+	d, err := s.TaskEvent(WebhookEventTaskStatusUpdated, &Task{ID: "9hz", List: ListOfTaskBelonging{ID: "5"}}, SimulatedChange{
+		Field:  "status",
+		Before: WebhookStatus{Status: "to do"},
+		After:  WebhookStatus{Status: "done"},
+	})
+	if err != nil {
+		t.Fatalf("TaskEvent returned error: %v", err)
+	}
+	if !VerifyWebhookSignature("s3cret", d.Body, d.Signature) {
+		t.Error("delivery signature does not verify")
+	}
+
+	var e WebhookEvent
+	json.Unmarshal(d.Body, &e)
+	e.Raw = d.Body
+	parsed, err := ParseWebhookEvent(&e)
+	if err != nil {
+		t.Fatalf("ParseWebhookEvent returned error: %v", err)
+	}
+	got := parsed.(TaskStatusUpdatedEvent)
+	if got.TaskID != "9hz" || got.WebhookID != "wh1" || got.Before.Status != "to do" || got.After.Status != "done" {
+		t.Errorf("parsed %+v", got)
+	}
+	h := got.History[0]
+	if h.ParentID != "5" || h.Type != 1 || h.Date != "1704067200000" || h.User.Username != "Simulator" {
+		t.Errorf("history item %+v", h)
+	}
+}
+
+func TestWebhookSimulator_otherEvents(t *testing.T) {
+	s := newTestWebhookSimulator()
+
+	for _, tt := range []struct {
+		build func() (*WebhookDelivery, error)
+		want  WebhookEvent
+	}{
+		{func() (*WebhookDelivery, error) { return s.ListEvent(WebhookEventListCreated, &List{ID: "5"}) }, WebhookEvent{Event: "listCreated", ListID: "5"}},
+		{func() (*WebhookDelivery, error) { return s.FolderEvent(WebhookEventFolderUpdated, &Folder{ID: "4"}) }, WebhookEvent{Event: "folderUpdated", FolderID: "4"}},
+		{func() (*WebhookDelivery, error) { return s.SpaceEvent(WebhookEventSpaceDeleted, &Space{ID: "3"}) }, WebhookEvent{Event: "spaceDeleted", SpaceID: "3"}},
+		{func() (*WebhookDelivery, error) { return s.GoalEvent(WebhookEventGoalCreated, &Goal{ID: "g1"}) }, WebhookEvent{Event: "goalCreated", GoalID: "g1"}},
+		{func() (*WebhookDelivery, error) {
+			return s.KeyResultEvent(WebhookEventKeyResultUpdated, &KeyResult{ID: "k1", GoalID: "g1"})
+		}, WebhookEvent{Event: "keyResultUpdated", GoalID: "g1", KeyResultID: "k1"}},
+	} {
+		d, err := tt.build()
+		if err != nil {
+			t.Fatalf("building %s returned error: %v", tt.want.Event, err)
+		}
+		var got WebhookEvent
+		json.Unmarshal(d.Body, &got)
+		tt.want.WebhookID = "wh1"
+		if !cmp.Equal(got, tt.want) || d.Event != tt.want.Event {
+			t.Errorf("delivery = %+v, want %+v", got, tt.want)
+		}
+	}
+
+	if _, err := s.ListEvent(WebhookEventTaskCreated, &List{ID: "5"}); err == nil {
+		t.Error("ListEvent returned no error for a task event")
+	}
+	if _, err := s.GoalEvent("goalArchived", &Goal{ID: "g1"}); err == nil {
+		t.Error("GoalEvent returned no error for an unknown event")
+	}
+}
+
+func TestWebhookSimulator_ReadDeliveries(t *testing.T) {
+	s := newTestWebhookSimulator()
+
+	input := `{"event": "taskCreated", "task_id": "a"}
+[{"event": "taskDeleted", "task_id": "b"}, {"event": "listCreated", "list_id": "5"}]
+{"key": "wh1:1", "body": {"event":"taskMoved","task_id":"c"}, "attempts": 5}
+`
+	deliveries, err := s.ReadDeliveries(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadDeliveries returned error: %v", err)
+	}
+
+	var got []string
+	for _, d := range deliveries {
+		if !VerifyWebhookSignature("s3cret", d.Body, d.Signature) {
+			t.Errorf("delivery %s is not signed", d.Body)
+		}
+		got = append(got, d.Event+" "+string(d.Body))
+	}
+	want := []string{
+		`taskCreated {"event": "taskCreated", "task_id": "a"}`,
+		`taskDeleted {"event": "taskDeleted", "task_id": "b"}`,
+		`listCreated {"event": "listCreated", "list_id": "5"}`,
+		`taskMoved {"event":"taskMoved","task_id":"c"}`,
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("deliveries = %q, want %q", got, want)
+	}
+
+	if _, err := s.ReadDeliveries(strings.NewReader(`{"task_id": "a"}`)); err == nil {
+		t.Error("ReadDeliveries returned no error for a delivery without an event")
+	}
+}
+
+func TestWebhookSimulator_Post(t *testing.T) {
+	s := newTestWebhookSimulator()
+
+	var body, signature string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		b, _ := io.ReadAll(r.Body)
+		body, signature = string(b), r.Header.Get(WebhookSignatureHeader)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	d, _ := s.SpaceEvent(WebhookEventSpaceCreated, &Space{ID: "3"})
+	resp, err := s.Post(context.Background(), srv.URL, d)
+	if err != nil {
+		t.Fatalf("Post returned error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted || body != string(d.Body) || signature != d.Signature {
+		t.Errorf("Post delivered %q with signature %q, status %d", body, signature, resp.StatusCode)
+	}
+}
+
+func TestWebhookSimulator_Serve(t *testing.T) {
+	s := newTestWebhookSimulator()
+	d, _ := s.SpaceEvent(WebhookEventSpaceCreated, &Space{ID: "3"})
+
+	resp := s.Serve(NewWebhookReceiver("other"), d)
+	b, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusUnauthorized || !strings.Contains(string(b), "invalid signature") {
+		t.Errorf("Serve = %d %q, want 401 for a bad signature", resp.StatusCode, b)
+	}
+
+	resp = s.Serve(NewWebhookReceiver("s3cret"), d)
+	if resp.StatusCode != http.StatusOK || resp.Request.Header.Get(WebhookSignatureHeader) != d.Signature {
+		t.Errorf("Serve = %d for request %+v, want 200", resp.StatusCode, resp.Request)
+	}
+}
@@ -41,6 +41,8 @@ type WebhooksService service
 // - keyResultCreated
 // - keyResultUpdated
 // - keyResultDeleted
+//
+// The WebhookEvent constants name these events.
 type WebhookRequest struct {
 	Endpoint string   `json:"endpoint"`
 	Events   []string `json:"events"`
@@ -0,0 +1,55 @@
+// The hierarchy command prints the spaces, folders and lists of a team as an
+// indented tree, or as JSON with -json:
+//
+//	hierarchy -team 123 -archived -tasks
+//
+// The API key is read from CLICKUP_API_KEY.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/raksul/go-clickup/clickup"
+)
+
+func main() {
+	teamID := flag.String("team", "", "team ID")
+	archived := flag.Bool("archived", false, "include archived spaces, folders and lists")
+	tasks := flag.Bool("tasks", false, "include tasks")
+	views := flag.Bool("views", false, "include views")
+	asJSON := flag.Bool("json", false, "print the tree as JSON")
+	flag.Parse()
+
+	client := clickup.NewClient(nil, os.Getenv("CLICKUP_API_KEY"))
+	h, err := client.Teams.CrawlHierarchy(context.Background(), *teamID, &clickup.CrawlHierarchyOptions{
+		Archived: *archived,
+		Tasks:    *tasks,
+		Views:    *views,
+	})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(h)
+		return
+	}
+
+	h.Walk(func(n clickup.HierarchyNode) error {
+		shared := ""
+		if (n.Folder != nil && n.Folder.Shared && n.Kind == clickup.HierarchyKindFolder) ||
+			(n.List != nil && n.List.Shared && n.Kind == clickup.HierarchyKindList) {
+			shared = " (shared)"
+		}
+		fmt.Printf("%s%s %s [%s]%s\n", strings.Repeat("  ", n.Depth), n.Kind, n.Name(), n.ID(), shared)
+		return nil
+	})
+}
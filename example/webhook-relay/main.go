@@ -0,0 +1,71 @@
+// The webhook-relay command receives ClickUp webhook deliveries and forwards
+// them to the internal destinations listed in a JSON config file:
+//
+//	{
+//	  "secret": "webhook secret from ClickUp",
+//	  "destinations": [
+//	    {"name": "search", "url": "http://search.internal/hook", "secret": "s1"},
+//	    {"name": "status", "url": "http://status.internal/hook", "events": ["taskStatusUpdated"], "space_id": "9"}
+//	  ]
+//	}
+//
+// Deliveries are accepted on /webhook and delivery metrics are served as JSON
+// on /metrics. Destinations filtering on a folder or space look up tasks with
+// the API key in CLICKUP_API_KEY.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/raksul/go-clickup/clickup"
+)
+
+type config struct {
+	Secret       string                       `json:"secret"`
+	Destinations []clickup.WebhookDestination `json:"destinations"`
+}
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	path := flag.String("config", "relay.json", "config file")
+	flag.Parse()
+
+	b, err := os.ReadFile(*path)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	var c config
+	if err := json.Unmarshal(b, &c); err != nil {
+		fmt.Printf("Error: %s: %v\n", *path, err)
+		os.Exit(1)
+	}
+
+	opts := &clickup.WebhookRelayOptions{
+		OnFailure: func(destination string, e *clickup.WebhookEvent, err error) {
+			log.Printf("%s: %s delivery %s: %v", destination, e.Event, clickup.WebhookIdempotencyKey(e), err)
+		},
+	}
+	// The API key is only needed for destinations filtering task events on
+	// their folder or space.
+	if key := os.Getenv("CLICKUP_API_KEY"); key != "" {
+		opts.Client = clickup.NewClient(nil, key)
+	}
+	relay := clickup.NewWebhookRelay(c.Secret, c.Destinations, opts)
+	go relay.Run(context.Background())
+
+	http.Handle("/webhook", relay)
+	http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(relay.Metrics())
+	})
+
+	log.Printf("relaying to %d destinations on %s", len(c.Destinations), *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
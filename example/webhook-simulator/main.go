@@ -0,0 +1,94 @@
+// The webhook-simulator command sends signed ClickUp webhook deliveries to
+// a local endpoint, so webhook consumers can be tested without ClickUp.
+//
+// Send a simulated event:
+//
+//	webhook-simulator -url http://localhost:8080/webhook -event taskStatusUpdated -id 9hz -status done
+//
+// Replay recorded deliveries, one JSON object per line:
+//
+//	webhook-simulator -url http://localhost:8080/webhook deliveries.jsonl
+//
+// The secret is read from CLICKUP_WEBHOOK_SECRET unless -secret is given.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/raksul/go-clickup/clickup"
+)
+
+func buildDelivery(sim *clickup.WebhookSimulator, event, id, name, status string) (*clickup.WebhookDelivery, error) {
+	switch {
+	case strings.HasPrefix(event, "task"):
+		return sim.TaskEvent(event, &clickup.Task{ID: id, Name: name, Status: clickup.TaskStatus{Status: status, Type: "custom"}})
+	case strings.HasPrefix(event, "list"):
+		return sim.ListEvent(event, &clickup.List{ID: id, Name: name})
+	case strings.HasPrefix(event, "folder"):
+		return sim.FolderEvent(event, &clickup.Folder{ID: id, Name: name})
+	case strings.HasPrefix(event, "space"):
+		return sim.SpaceEvent(event, &clickup.Space{ID: id, Name: name})
+	case strings.HasPrefix(event, "goal"):
+		return sim.GoalEvent(event, &clickup.Goal{ID: id, Name: name})
+	case strings.HasPrefix(event, "keyResult"):
+		return sim.KeyResultEvent(event, &clickup.KeyResult{ID: id, Name: name})
+	}
+
+	return nil, fmt.Errorf("unknown event %q", event)
+}
+
+func main() {
+	url := flag.String("url", "http://localhost:8080/webhook", "endpoint to send deliveries to")
+	secret := flag.String("secret", os.Getenv("CLICKUP_WEBHOOK_SECRET"), "webhook secret to sign deliveries with")
+	webhookID := flag.String("webhook-id", "simulated", "webhook ID of the deliveries")
+	event := flag.String("event", clickup.WebhookEventTaskCreated, "event to simulate")
+	id := flag.String("id", "abc123", "ID of the task, list, folder, space, goal or key result")
+	name := flag.String("name", "Simulated", "name of the task, list, folder, space, goal or key result")
+	status := flag.String("status", "to do", "status of the task")
+	flag.Parse()
+
+	sim := clickup.NewWebhookSimulator(*webhookID, *secret)
+
+	var deliveries []*clickup.WebhookDelivery
+	if flag.NArg() == 0 {
+		d, err := buildDelivery(sim, *event, *id, *name, *status)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		deliveries = append(deliveries, d)
+	}
+	for _, path := range flag.Args() {
+		ds, err := sim.ReadDeliveryFile(path)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		deliveries = append(deliveries, ds...)
+	}
+
+	failed := false
+	for _, d := range deliveries {
+		resp, err := sim.Post(context.Background(), *url, d)
+		if err != nil {
+			fmt.Printf("%s: error: %v\n", d.Event, err)
+			failed = true
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		fmt.Printf("%s: %s %s\n", d.Event, resp.Status, strings.TrimSpace(string(body)))
+		if resp.StatusCode >= 300 {
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}